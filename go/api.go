@@ -0,0 +1,90 @@
+package bucketclient
+
+import (
+	"context"
+	"iter"
+	"time"
+)
+
+// BucketDataAPI gathers the methods reading and writing buckets and
+// their contents
+type BucketDataAPI interface {
+	Request(ctx context.Context, apiMethod string, httpMethod string, resource string,
+		opts ...RequestOption) ([]byte, error)
+	CreateBucket(ctx context.Context, bucketName string, bucketAttributes []byte,
+		opts ...CreateBucketOption) error
+	DeleteBucket(ctx context.Context, bucketName string, opts ...DeleteBucketOption) error
+	GetBucketAttributes(ctx context.Context, bucketName string) ([]byte, error)
+	PutBucketAttributes(ctx context.Context, bucketName string, bucketAttributes []byte) error
+	UpdateBucketAttributes(ctx context.Context, bucketName string,
+		mutate func(bucketInfo *BucketInfo) error, opts ...UpdateBucketAttributesOption) error
+	PostBatch(ctx context.Context, bucketName string, batch []PostBatchEntry) error
+	DeleteRange(ctx context.Context, bucketName string, opts ...DeleteRangeOption) (int, error)
+	PutUsersBucketEntry(ctx context.Context, canonicalID string, bucketName string,
+		creationDate time.Time) error
+	DeleteUsersBucketEntry(ctx context.Context, canonicalID string, bucketName string) error
+}
+
+// BucketListingAPI gathers the methods listing the contents of buckets
+type BucketListingAPI interface {
+	ListBasic(ctx context.Context, bucketName string,
+		opts ...ListBasicOption) (*ListBasicResponse, error)
+	ListObjectVersions(ctx context.Context, bucketName string,
+		opts ...ListObjectVersionsOption) (*ListObjectVersionsResponse, error)
+	ListMultipartUploads(ctx context.Context, bucketName string,
+		opts ...ListMultipartUploadsOption) (*ListMultipartUploadsResponse, error)
+	ListParts(ctx context.Context, bucketName string, uploadId string,
+		opts ...ListPartsOption) (*ListPartsResponse, error)
+	ListBucketsByOwner(ctx context.Context, canonicalID string) ([]OwnerBucket, error)
+	ListLifecycleCurrent(ctx context.Context, bucketName string,
+		opts ...ListLifecycleOption) (*ListLifecycleResponse, error)
+	ListLifecycleNonCurrent(ctx context.Context, bucketName string,
+		opts ...ListLifecycleOption) (*ListLifecycleResponse, error)
+	ListLifecycleOrphanDeleteMarkers(ctx context.Context, bucketName string,
+		opts ...ListLifecycleOption) (*ListLifecycleResponse, error)
+	IterateLifecycleCurrent(ctx context.Context, bucketName string,
+		opts ...ListLifecycleOption) iter.Seq2[ListLifecycleEntry, error]
+	IterateLifecycleNonCurrent(ctx context.Context, bucketName string,
+		opts ...ListLifecycleOption) iter.Seq2[ListLifecycleEntry, error]
+	IterateLifecycleOrphanDeleteMarkers(ctx context.Context, bucketName string,
+		opts ...ListLifecycleOption) iter.Seq2[ListLifecycleEntry, error]
+}
+
+// MetastoreAPI gathers the methods managing metastore entries
+type MetastoreAPI interface {
+	GetMetastoreEntry(ctx context.Context, bucketName string) (MetastoreEntry, error)
+	CreateMetastoreEntry(ctx context.Context, bucketName string, metastoreEntry MetastoreEntry) error
+	DeleteMetastoreEntry(ctx context.Context, bucketName string,
+		opts ...DeleteMetastoreEntryOption) error
+	CompareAndSwapMetastoreEntry(ctx context.Context, bucketName string,
+		expectedVersion int, newEntry MetastoreEntry) error
+}
+
+// AdminAPI gathers the methods calling the admin routes of bucketd
+type AdminAPI interface {
+	AdminBucketRefreshCache(ctx context.Context, bucketName string) error
+	AdminGetBucketAccessMode(ctx context.Context, bucketName string) (BucketAccessMode, error)
+	AdminSetBucketAccessMode(ctx context.Context, bucketName string, accessMode BucketAccessMode) error
+	AdminGetBucketSessionID(ctx context.Context, bucketName string) (int, error)
+	AdminGetAllSessionsInfo(ctx context.Context) ([]SessionInfo, error)
+	AdminGetSessionInfo(ctx context.Context, sessionId int) (*SessionInfo, error)
+	AdminGetSessionLeader(ctx context.Context, sessionId int) (*MemberInfo, error)
+	AdminGetSessionLog(ctx context.Context, sessionId int, beginSeq int64, nRecords int,
+		targetLeader bool) (*AdminGetSessionLogResponse, error)
+}
+
+// BucketClientAPI is the interface of the public methods of
+// BucketClient calling bucketd, to let code using a BucketClient
+// accept fakes, mocks or decorators like CachingBucketClient instead.
+//
+// Code needing only part of the methods may accept one of the
+// interfaces it is made of instead.
+type BucketClientAPI interface {
+	BucketDataAPI
+	BucketListingAPI
+	MetastoreAPI
+	AdminAPI
+}
+
+var _ BucketClientAPI = (*BucketClient)(nil)
+var _ BucketClientAPI = (*CachingBucketClient)(nil)
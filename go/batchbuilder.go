@@ -0,0 +1,199 @@
+package bucketclient
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"unicode/utf8"
+)
+
+type BatchBuilderOption func(*batchBuilderOptionSet) error
+
+// BatchBuilderMaxEntriesOption limits the number of entries sent in a
+// single PostBatch request (default 1000)
+func BatchBuilderMaxEntriesOption(maxEntries int) BatchBuilderOption {
+	return func(opts *batchBuilderOptionSet) error {
+		if maxEntries < 1 {
+			return fmt.Errorf("maxEntries=%d must be at least 1", maxEntries)
+		}
+		opts.maxEntries = maxEntries
+		return nil
+	}
+}
+
+// BatchBuilderMaxBytesOption limits the size of the body of a single
+// PostBatch request (default 4MiB)
+func BatchBuilderMaxBytesOption(maxBytes int) BatchBuilderOption {
+	return func(opts *batchBuilderOptionSet) error {
+		if maxBytes <= len(postBatchBodyPrefix)+len(postBatchBodySuffix) {
+			return fmt.Errorf("maxBytes=%d is too small", maxBytes)
+		}
+		opts.maxBytes = maxBytes
+		return nil
+	}
+}
+
+type batchBuilderOptionSet struct {
+	maxEntries int
+	maxBytes   int
+}
+
+// the JSON body of PostBatch requests wraps entries with these
+const (
+	postBatchBodyPrefix = `{"batch":[`
+	postBatchBodySuffix = `]}`
+)
+
+// BatchBuilder accumulates typed put and delete operations on the keys
+// of a bucket, then sends them with PostBatch in chunks small enough
+// for bucketd.
+//
+// A BatchBuilder is not safe for concurrent use.
+type BatchBuilder struct {
+	client     BucketDataAPI
+	bucketName string
+	options    batchBuilderOptionSet
+	chunks     []batchChunk
+}
+
+type batchChunk struct {
+	entries []PostBatchEntry
+	size    int
+}
+
+// BatchChunkError is returned by BatchBuilder.Send when a chunk could
+// not be applied. Chunks are sent in order, so all chunks before the
+// failed one have been applied, and none after it have been sent.
+type BatchChunkError struct {
+	BucketName string
+	// FailedChunk is the index of the chunk that failed
+	FailedChunk int
+	// ChunkCount is the total number of chunks of the batch
+	ChunkCount int
+	// AppliedEntries is the number of entries of the chunks
+	// applied before the failed one
+	AppliedEntries int
+	Err            error
+}
+
+func (e *BatchChunkError) Error() string {
+	return fmt.Sprintf("batch on bucket %s failed at chunk %d/%d after applying %d entries: %v",
+		e.BucketName, e.FailedChunk+1, e.ChunkCount, e.AppliedEntries, e.Err)
+}
+
+func (e *BatchChunkError) Unwrap() error {
+	return e.Err
+}
+
+// NewBatchBuilder returns an empty batch builder on the given bucket.
+func (client *BucketClient) NewBatchBuilder(bucketName string,
+	opts ...BatchBuilderOption) (*BatchBuilder, error) {
+	return newBatchBuilder(client, bucketName, opts)
+}
+
+// newBatchBuilder returns an empty batch builder sending batches with
+// the PostBatch method of client
+func newBatchBuilder(client BucketDataAPI, bucketName string,
+	opts []BatchBuilderOption) (*BatchBuilder, error) {
+	options := batchBuilderOptionSet{
+		maxEntries: 1000,
+		maxBytes:   4 * 1024 * 1024,
+	}
+	for _, opt := range opts {
+		err := opt(&options)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return &BatchBuilder{
+		client:     client,
+		bucketName: bucketName,
+		options:    options,
+	}, nil
+}
+
+// Put adds an operation setting the value of key to the JSON encoding
+// of value. Pass a json.RawMessage to set an already encoded value.
+func (b *BatchBuilder) Put(key string, value any) error {
+	encodedValue, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Errorf("error marshaling value of key %q: %w", key, err)
+	}
+	return b.add(PostBatchEntry{Key: key, Value: string(encodedValue)})
+}
+
+// Delete adds an operation deleting key.
+func (b *BatchBuilder) Delete(key string) error {
+	return b.add(PostBatchEntry{Key: key, Type: "del"})
+}
+
+func (b *BatchBuilder) add(entry PostBatchEntry) error {
+	if entry.Key == "" {
+		return fmt.Errorf("invalid empty key")
+	}
+	if !utf8.ValidString(entry.Key) {
+		return fmt.Errorf("invalid key %q: not valid UTF-8", entry.Key)
+	}
+	encodedEntry, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("error marshaling entry of key %q: %w", entry.Key, err)
+	}
+	entrySize := len(encodedEntry)
+	if len(postBatchBodyPrefix)+entrySize+len(postBatchBodySuffix) > b.options.maxBytes {
+		return fmt.Errorf("entry of key %q is too large (%d bytes) to fit in a batch of %d bytes",
+			entry.Key, entrySize, b.options.maxBytes)
+	}
+	var lastChunk *batchChunk
+	if len(b.chunks) > 0 {
+		lastChunk = &b.chunks[len(b.chunks)-1]
+	}
+	if lastChunk == nil ||
+		len(lastChunk.entries) == b.options.maxEntries ||
+		lastChunk.size+1+entrySize > b.options.maxBytes {
+		b.chunks = append(b.chunks, batchChunk{
+			size: len(postBatchBodyPrefix) + len(postBatchBodySuffix) - 1,
+		})
+		lastChunk = &b.chunks[len(b.chunks)-1]
+	}
+	lastChunk.entries = append(lastChunk.entries, entry)
+	// count the separating comma along with each entry, hence
+	// the initial chunk size reduced by one
+	lastChunk.size += 1 + entrySize
+	return nil
+}
+
+// Len returns the number of operations added to the batch.
+func (b *BatchBuilder) Len() int {
+	count := 0
+	for _, chunk := range b.chunks {
+		count += len(chunk.entries)
+	}
+	return count
+}
+
+// Send sends all operations of the batch in order, in as many
+// PostBatch requests as needed to respect the size and count limits
+// of the builder, then empties the builder.
+//
+// If a request fails, Send stops and returns a *BatchChunkError
+// telling how far the batch was applied. The builder then keeps all
+// operations: since batches are idempotent, Send can be called again
+// to retry the whole batch.
+func (b *BatchBuilder) Send(ctx context.Context) error {
+	appliedEntries := 0
+	for i, chunk := range b.chunks {
+		err := b.client.PostBatch(ctx, b.bucketName, chunk.entries)
+		if err != nil {
+			return &BatchChunkError{
+				BucketName:     b.bucketName,
+				FailedChunk:    i,
+				ChunkCount:     len(b.chunks),
+				AppliedEntries: appliedEntries,
+				Err:            err,
+			}
+		}
+		appliedEntries += len(chunk.entries)
+	}
+	b.chunks = nil
+	return nil
+}
@@ -0,0 +1,152 @@
+package bucketclient_test
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/jarcoal/httpmock"
+
+	"github.com/scality/bucketclient/go"
+)
+
+var _ = Describe("BatchBuilder", func() {
+	var receivedBodies []string
+
+	BeforeEach(func() {
+		receivedBodies = nil
+	})
+
+	registerBatchResponder := func(failAtRequest int) {
+		httpmock.RegisterResponder(
+			"POST", "/default/batch/somebucket",
+			func(req *http.Request) (*http.Response, error) {
+				defer req.Body.Close()
+				body, err := io.ReadAll(req.Body)
+				Expect(err).ToNot(HaveOccurred())
+				receivedBodies = append(receivedBodies, string(body))
+				if len(receivedBodies) == failAtRequest {
+					return httpmock.NewStringResponse(500, ""), nil
+				}
+				return httpmock.NewStringResponse(200, ""), nil
+			},
+		)
+	}
+
+	It("sends typed puts and deletes in a single batch", func(ctx SpecContext) {
+		registerBatchResponder(0)
+		batch, err := client.NewBatchBuilder("somebucket")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(batch.Put("foo", map[string]any{"bar": 42})).To(Succeed())
+		Expect(batch.Put("raw", json.RawMessage(`{"raw":true}`))).To(Succeed())
+		Expect(batch.Delete("old\x00version")).To(Succeed())
+		Expect(batch.Len()).To(Equal(3))
+
+		Expect(batch.Send(ctx)).To(Succeed())
+		Expect(receivedBodies).To(Equal([]string{
+			`{"batch":[{"key":"foo","value":"{\"bar\":42}"},` +
+				`{"key":"raw","value":"{\"raw\":true}"},` +
+				`{"key":"old\u0000version","type":"del"}]}`,
+		}))
+		Expect(batch.Len()).To(Equal(0))
+	})
+
+	It("does not send anything for an empty batch", func(ctx SpecContext) {
+		registerBatchResponder(0)
+		batch, err := client.NewBatchBuilder("somebucket")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(batch.Send(ctx)).To(Succeed())
+		Expect(receivedBodies).To(BeEmpty())
+	})
+
+	It("rejects invalid keys and values", func() {
+		batch, err := client.NewBatchBuilder("somebucket")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(batch.Put("", "value")).To(MatchError(ContainSubstring("empty key")))
+		Expect(batch.Delete("bad\xffkey")).To(MatchError(ContainSubstring("not valid UTF-8")))
+		Expect(batch.Put("foo", make(chan int))).To(
+			MatchError(ContainSubstring("error marshaling value")))
+		Expect(batch.Len()).To(Equal(0))
+	})
+
+	It("rejects invalid options", func() {
+		_, err := client.NewBatchBuilder("somebucket",
+			bucketclient.BatchBuilderMaxEntriesOption(0))
+		Expect(err).To(HaveOccurred())
+		_, err = client.NewBatchBuilder("somebucket",
+			bucketclient.BatchBuilderMaxBytesOption(10))
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("splits the batch in chunks bounded by entry count", func(ctx SpecContext) {
+		registerBatchResponder(0)
+		batch, err := client.NewBatchBuilder("somebucket",
+			bucketclient.BatchBuilderMaxEntriesOption(2))
+		Expect(err).ToNot(HaveOccurred())
+		for _, key := range []string{"a", "b", "c", "d", "e"} {
+			Expect(batch.Delete(key)).To(Succeed())
+		}
+		Expect(batch.Send(ctx)).To(Succeed())
+		Expect(receivedBodies).To(Equal([]string{
+			`{"batch":[{"key":"a","type":"del"},{"key":"b","type":"del"}]}`,
+			`{"batch":[{"key":"c","type":"del"},{"key":"d","type":"del"}]}`,
+			`{"batch":[{"key":"e","type":"del"}]}`,
+		}))
+	})
+
+	It("splits the batch in chunks bounded by body size", func(ctx SpecContext) {
+		registerBatchResponder(0)
+		// each entry is 31 bytes long, and a body with two entries
+		// is 10+31+1+31+2 = 75 bytes long
+		batch, err := client.NewBatchBuilder("somebucket",
+			bucketclient.BatchBuilderMaxBytesOption(75))
+		Expect(err).ToNot(HaveOccurred())
+		for _, key := range []string{"a", "b", "c"} {
+			Expect(batch.Put(key, "12345")).To(Succeed())
+		}
+		Expect(batch.Send(ctx)).To(Succeed())
+		Expect(receivedBodies).To(HaveLen(2))
+		Expect(receivedBodies[0]).To(HaveLen(75))
+		for _, body := range receivedBodies {
+			Expect(len(body)).To(BeNumerically("<=", 75))
+		}
+		Expect(receivedBodies[1]).To(Equal(`{"batch":[{"key":"c","value":"\"12345\""}]}`))
+	})
+
+	It("rejects an entry larger than the maximum body size", func() {
+		batch, err := client.NewBatchBuilder("somebucket",
+			bucketclient.BatchBuilderMaxBytesOption(40))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(batch.Put("foo", "some value too large for the batch")).To(
+			MatchError(ContainSubstring("too large")))
+	})
+
+	It("reports which chunks were applied when a chunk fails", func(ctx SpecContext) {
+		registerBatchResponder(2)
+		batch, err := client.NewBatchBuilder("somebucket",
+			bucketclient.BatchBuilderMaxEntriesOption(2))
+		Expect(err).ToNot(HaveOccurred())
+		for _, key := range []string{"a", "b", "c", "d", "e"} {
+			Expect(batch.Delete(key)).To(Succeed())
+		}
+		err = batch.Send(ctx)
+		Expect(err).To(HaveOccurred())
+		var chunkErr *bucketclient.BatchChunkError
+		Expect(errors.As(err, &chunkErr)).To(BeTrue())
+		Expect(chunkErr.FailedChunk).To(Equal(1))
+		Expect(chunkErr.ChunkCount).To(Equal(3))
+		Expect(chunkErr.AppliedEntries).To(Equal(2))
+
+		var bcErr *bucketclient.BucketClientError
+		Expect(errors.As(err, &bcErr)).To(BeTrue())
+		Expect(bcErr.StatusCode).To(Equal(500))
+
+		// the third chunk was not sent, and the batch is kept for retry
+		Expect(receivedBodies).To(HaveLen(2))
+		Expect(batch.Len()).To(Equal(5))
+	})
+})
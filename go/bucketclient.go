@@ -1,9 +1,42 @@
 package bucketclient
 
+import (
+	"net/http"
+)
+
 type BucketClient struct {
 	Endpoint string
+
+	httpClient *http.Client
+
+	coalescer *requestCoalescer
+	hedger    *hedger
+	limits    *requestLimitSet
+
+	circuitBreakers *circuitBreakerSet
+	interceptors    []RequestInterceptor
+	logger          *requestLogger
+	metrics         RequestMetrics
+}
+
+type BucketClientOption func(*BucketClient)
+
+// New creates a client for the bucketd service listening on
+// bucketdEndpoint, with optional client-wide features enabled through
+// opts.
+func New(bucketdEndpoint string, opts ...BucketClientOption) *BucketClient {
+	client := &BucketClient{Endpoint: bucketdEndpoint}
+	for _, opt := range opts {
+		opt(client)
+	}
+	return client
 }
 
-func New(bucketdEndpoint string) *BucketClient {
-	return &BucketClient{bucketdEndpoint}
+// BucketClientTransportOption sends the HTTP requests to bucketd
+// through transport instead of http.DefaultTransport, e.g. to record or
+// replay them with the bucketclientreplay package
+func BucketClientTransportOption(transport http.RoundTripper) BucketClientOption {
+	return func(client *BucketClient) {
+		client.httpClient = &http.Client{Transport: transport}
+	}
 }
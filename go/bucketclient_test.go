@@ -1,16 +1,48 @@
 package bucketclient_test
 
 import (
+	"io"
+	"net/http"
+	"strings"
+
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 
 	"github.com/scality/bucketclient/go"
 )
 
+type roundTripperFunc func(request *http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(request *http.Request) (*http.Response, error) {
+	return f(request)
+}
+
 var _ = Describe("BucketClient", func() {
 	It("New", func() {
 		client := bucketclient.New("http://localhost:9000")
 		Expect(client).ToNot(BeNil())
 		Expect(client.Endpoint).To(Equal("http://localhost:9000"))
 	})
+
+	It("sends requests through the transport set with BucketClientTransportOption",
+		func(ctx SpecContext) {
+			var requestURLs, apiMethods []string
+			transport := roundTripperFunc(func(request *http.Request) (*http.Response, error) {
+				requestURLs = append(requestURLs, request.URL.String())
+				apiMethods = append(apiMethods, bucketclient.ApiMethodFromContext(request.Context()))
+				return &http.Response{
+					StatusCode: 200,
+					Status:     "200 OK",
+					Body:       io.NopCloser(strings.NewReader(`{"foo":"bar"}`)),
+					Request:    request,
+				}, nil
+			})
+			client := bucketclient.New("http://localhost:9000",
+				bucketclient.BucketClientTransportOption(transport))
+			Expect(client.GetBucketAttributes(ctx, "my-bucket")).To(Equal([]byte(`{"foo":"bar"}`)))
+			Expect(requestURLs).To(Equal([]string{
+				"http://localhost:9000/default/attributes/my-bucket",
+			}))
+			Expect(apiMethods).To(Equal([]string{"GetBucketAttributes"}))
+		})
 })
@@ -15,7 +15,12 @@ type BucketClientError struct {
 }
 
 func (e *BucketClientError) Error() string {
-	if e.StatusCode > 0 {
+	if e.StatusCode > 0 && e.Err != nil {
+		// status set by the client itself, e.g. after conflicting
+		// updates: the wrapped error tells why
+		return fmt.Sprintf("error in %s [%s %s%s]: HTTP status %d %s: %v",
+			e.ApiMethod, e.HttpMethod, e.Endpoint, e.Resource, e.StatusCode, e.ErrorType, e.Err)
+	} else if e.StatusCode > 0 {
 		return fmt.Sprintf("error in %s [%s %s%s]: bucketd returned HTTP status %d %s",
 			e.ApiMethod, e.HttpMethod, e.Endpoint, e.Resource, e.StatusCode, e.ErrorType)
 	} else {
@@ -24,6 +24,20 @@ var _ = Describe("BucketClientError", func() {
 		Expect(errStr).To(Equal("error in SomeMethod [GET http://localhost:9000/some/resource]: " +
 			"bucketd returned HTTP status 404 ResourceNotFound"))
 	})
+	It("Error(): with HTTP status and wrapped error", func() {
+		myError := &bucketclient.BucketClientError{
+			ApiMethod:  "SomeMethod",
+			HttpMethod: "POST",
+			Endpoint:   "http://localhost:9000",
+			Resource:   "/some/resource",
+			StatusCode: 409,
+			ErrorType:  "Conflict",
+			Err:        errors.New("OOPS"),
+		}
+		errStr := myError.Error()
+		Expect(errStr).To(Equal("error in SomeMethod [POST http://localhost:9000/some/resource]: " +
+			"HTTP status 409 Conflict: OOPS"))
+	})
 	It("Error(): generic error", func() {
 		myError := &bucketclient.BucketClientError{
 			ApiMethod:  "SomeMethod",
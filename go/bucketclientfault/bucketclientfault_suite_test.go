@@ -0,0 +1,13 @@
+package bucketclientfault_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestBucketclientfault(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Bucketclientfault Suite")
+}
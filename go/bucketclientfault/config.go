@@ -0,0 +1,142 @@
+package bucketclientfault
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+)
+
+// FaultType is the kind of failure injected by a rule
+type FaultType string
+
+const (
+	// FaultLatency delays the request by Rule.Latency before
+	// sending it
+	FaultLatency FaultType = "latency"
+	// FaultReset fails the request with a connection reset error,
+	// without sending it
+	FaultReset FaultType = "reset"
+	// FaultTruncatedBody sends the request, then fails reading the
+	// response body halfway through
+	FaultTruncatedBody FaultType = "truncatedBody"
+	// FaultStatus answers the request with Rule.StatusCode, without
+	// sending it
+	FaultStatus FaultType = "status"
+	// FaultMalformedJSON sends the request, then replaces the
+	// response body by an invalid JSON document
+	FaultMalformedJSON FaultType = "malformedJSON"
+)
+
+// Duration is a time.Duration read from and written to JSON as a
+// string like "250ms"
+type Duration time.Duration
+
+func (d Duration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(time.Duration(d).String())
+}
+
+func (d *Duration) UnmarshalJSON(data []byte) error {
+	var text string
+	if err := json.Unmarshal(data, &text); err != nil {
+		return err
+	}
+	duration, err := time.ParseDuration(text)
+	if err != nil {
+		return err
+	}
+	*d = Duration(duration)
+	return nil
+}
+
+// Rule injects a fault into the requests it selects
+type Rule struct {
+	// Name identifies the rule in injection counts, and defaults to
+	// "{index}-{fault}", e.g. "0-latency"
+	Name string `json:"name,omitempty"`
+	// ApiMethods selects requests by their API method, e.g.
+	// "PostBatch", or all of them if empty
+	ApiMethods []string `json:"apiMethods,omitempty"`
+	// Buckets selects requests by the bucket they target, or all of
+	// them if empty
+	Buckets []string `json:"buckets,omitempty"`
+	// Probability is the probability of injecting the fault into a
+	// selected request, between 0 and 1, or nil to always inject it
+	Probability *float64 `json:"probability,omitempty"`
+
+	Fault FaultType `json:"fault"`
+	// Latency is the delay added by FaultLatency
+	Latency Duration `json:"latency,omitempty"`
+	// StatusCode is the HTTP status returned by FaultStatus, e.g. 503
+	StatusCode int `json:"statusCode,omitempty"`
+	// ErrorType is the bucketd error type returned by FaultStatus as
+	// reason phrase, defaulting to the status text without spaces,
+	// e.g. "ServiceUnavailable"
+	ErrorType string `json:"errorType,omitempty"`
+}
+
+// Config is the set of rules of a Transport. Rules are tried in order
+// and the first one selecting a request injects its fault, so that at
+// most one fault is injected per request.
+type Config struct {
+	// Seed seeds the random draws of rule probabilities, so that a
+	// given sequence of requests gets the same faults across runs
+	Seed  uint64 `json:"seed"`
+	Rules []Rule `json:"rules"`
+}
+
+// LoadConfig reads a JSON configuration file, e.g.:
+//
+//	{
+//	  "seed": 42,
+//	  "rules": [
+//	    {"apiMethods": ["PostBatch"], "probability": 0.1,
+//	     "fault": "status", "statusCode": 503},
+//	    {"buckets": ["slowbucket"], "fault": "latency", "latency": "2s"}
+//	  ]
+//	}
+func LoadConfig(path string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var config Config
+	if err := json.Unmarshal(data, &config); err != nil {
+		return nil, fmt.Errorf("error parsing fault injection config %q: %w", path, err)
+	}
+	return &config, nil
+}
+
+// normalize checks the rule and fills in its defaults
+func (rule Rule) normalize(index int) (Rule, error) {
+	if rule.Probability != nil && (*rule.Probability < 0 || *rule.Probability > 1) {
+		return rule, fmt.Errorf("rule %d: probability %v is not between 0 and 1",
+			index, *rule.Probability)
+	}
+	switch rule.Fault {
+	case FaultLatency:
+		if rule.Latency <= 0 {
+			return rule, fmt.Errorf("rule %d: latency fault requires a positive latency", index)
+		}
+	case FaultStatus:
+		statusText := http.StatusText(rule.StatusCode)
+		if statusText == "" {
+			return rule, fmt.Errorf("rule %d: status fault requires a valid status code, got %d",
+				index, rule.StatusCode)
+		}
+		if rule.ErrorType == "" {
+			rule.ErrorType = strings.ReplaceAll(statusText, " ", "")
+		} else if strings.Contains(rule.ErrorType, " ") {
+			return rule, fmt.Errorf("rule %d: error type %q contains spaces", index, rule.ErrorType)
+		}
+	case FaultReset, FaultTruncatedBody, FaultMalformedJSON:
+	default:
+		return rule, fmt.Errorf("rule %d: unknown fault %q", index, rule.Fault)
+	}
+	if rule.Name == "" {
+		rule.Name = fmt.Sprintf("%d-%s", index, rule.Fault)
+	}
+	return rule, nil
+}
@@ -0,0 +1,77 @@
+package bucketclientfault_test
+
+import (
+	"os"
+	"path/filepath"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/scality/bucketclient/go/bucketclientfault"
+)
+
+// probability returns a pointer to p, to set Rule.Probability
+func probability(p float64) *float64 {
+	return &p
+}
+
+var _ = Describe("Config", func() {
+	It("loads rules from a JSON file", func() {
+		configPath := filepath.Join(GinkgoT().TempDir(), "faults.json")
+		Expect(os.WriteFile(configPath, []byte(`{
+			"seed": 42,
+			"rules": [
+				{"apiMethods": ["PostBatch"], "probability": 0.1,
+				 "fault": "status", "statusCode": 503},
+				{"buckets": ["slowbucket"], "fault": "latency", "latency": "2s"}
+			]
+		}`), 0o644)).To(Succeed())
+		Expect(bucketclientfault.LoadConfig(configPath)).To(Equal(&bucketclientfault.Config{
+			Seed: 42,
+			Rules: []bucketclientfault.Rule{
+				{
+					ApiMethods:  []string{"PostBatch"},
+					Probability: probability(0.1),
+					Fault:       bucketclientfault.FaultStatus,
+					StatusCode:  503,
+				},
+				{
+					Buckets: []string{"slowbucket"},
+					Fault:   bucketclientfault.FaultLatency,
+					Latency: bucketclientfault.Duration(2 * time.Second),
+				},
+			},
+		}))
+	})
+
+	It("fails to load invalid files", func() {
+		configPath := filepath.Join(GinkgoT().TempDir(), "faults.json")
+		Expect(os.WriteFile(configPath, []byte(`{"rules": [{"latency": "soon"}]}`), 0o644)).To(Succeed())
+		_, err := bucketclientfault.LoadConfig(configPath)
+		Expect(err).To(MatchError(ContainSubstring("error parsing fault injection config")))
+	})
+
+	DescribeTable("rejects invalid rules",
+		func(rule bucketclientfault.Rule, expectedError string) {
+			_, err := bucketclientfault.NewTransport(bucketclientfault.Config{
+				Rules: []bucketclientfault.Rule{rule},
+			})
+			Expect(err).To(MatchError(expectedError))
+		},
+		Entry("unknown fault", bucketclientfault.Rule{Fault: "explode"},
+			`rule 0: unknown fault "explode"`),
+		Entry("probability above 1", bucketclientfault.Rule{
+			Fault: bucketclientfault.FaultReset, Probability: probability(1.5),
+		}, "rule 0: probability 1.5 is not between 0 and 1"),
+		Entry("latency without duration", bucketclientfault.Rule{
+			Fault: bucketclientfault.FaultLatency,
+		}, "rule 0: latency fault requires a positive latency"),
+		Entry("status without status code", bucketclientfault.Rule{
+			Fault: bucketclientfault.FaultStatus,
+		}, "rule 0: status fault requires a valid status code, got 0"),
+		Entry("error type with spaces", bucketclientfault.Rule{
+			Fault: bucketclientfault.FaultStatus, StatusCode: 500, ErrorType: "Internal Error",
+		}, `rule 0: error type "Internal Error" contains spaces`),
+	)
+})
@@ -0,0 +1,233 @@
+// Package bucketclientfault injects failures into the HTTP requests a
+// bucketclient.BucketClient sends to bucketd, to test how services
+// using it handle bucketd failures.
+//
+// Faults are injected by a Transport, selected by rules configured in
+// code or loaded from a JSON file:
+//
+//	config, err := bucketclientfault.LoadConfig("faults.json")
+//	...
+//	transport, err := bucketclientfault.NewTransport(*config)
+//	...
+//	client := bucketclient.New(endpoint,
+//		bucketclient.BucketClientTransportOption(transport))
+package bucketclientfault
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"net"
+	"net/http"
+	"slices"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/scality/bucketclient/go"
+)
+
+type TransportOption func(*transportOptionSet)
+
+// TransportUpstreamOption sets the transport sending the requests,
+// with or without faults (default http.DefaultTransport)
+func TransportUpstreamOption(upstream http.RoundTripper) TransportOption {
+	return func(opts *transportOptionSet) {
+		opts.upstream = upstream
+	}
+}
+
+type transportOptionSet struct {
+	upstream http.RoundTripper
+}
+
+// Transport is an http.RoundTripper injecting faults into the requests
+// selected by its rules, to be passed to
+// bucketclient.BucketClientTransportOption. It is safe for concurrent
+// use.
+//
+// Rules select requests by the API method and bucket they are sent
+// for, as found by bucketclient.ApiMethodFromContext and
+// bucketclient.BucketNameFromResource. Probabilities are drawn from a
+// random source seeded from the configuration, in the order requests
+// are sent: the faults injected are deterministic as long as requests
+// are sent sequentially.
+type Transport struct {
+	rules    []Rule
+	upstream http.RoundTripper
+
+	mutex    sync.Mutex
+	rand     *rand.Rand
+	injected map[string]int
+}
+
+// NewTransport creates a transport injecting faults as configured
+func NewTransport(config Config, opts ...TransportOption) (*Transport, error) {
+	options := transportOptionSet{}
+	for _, opt := range opts {
+		opt(&options)
+	}
+	rules := make([]Rule, 0, len(config.Rules))
+	for i, rule := range config.Rules {
+		rule, err := rule.normalize(i)
+		if err != nil {
+			return nil, err
+		}
+		rules = append(rules, rule)
+	}
+	return &Transport{
+		rules:    rules,
+		upstream: options.upstream,
+		rand:     rand.New(rand.NewPCG(config.Seed, config.Seed)),
+		injected: map[string]int{},
+	}, nil
+}
+
+// Injected returns the number of faults injected so far by each rule,
+// by rule name
+func (transport *Transport) Injected() map[string]int {
+	transport.mutex.Lock()
+	defer transport.mutex.Unlock()
+	injected := make(map[string]int, len(transport.injected))
+	for name, count := range transport.injected {
+		injected[name] = count
+	}
+	return injected
+}
+
+// selectRule returns the rule injecting a fault into the request, or
+// nil if none does
+func (transport *Transport) selectRule(request *http.Request) *Rule {
+	apiMethod := bucketclient.ApiMethodFromContext(request.Context())
+	bucketName := bucketclient.BucketNameFromResource(request.URL.EscapedPath())
+
+	transport.mutex.Lock()
+	defer transport.mutex.Unlock()
+	for i := range transport.rules {
+		rule := &transport.rules[i]
+		if len(rule.ApiMethods) > 0 && !slices.Contains(rule.ApiMethods, apiMethod) {
+			continue
+		}
+		if len(rule.Buckets) > 0 && !slices.Contains(rule.Buckets, bucketName) {
+			continue
+		}
+		if rule.Probability != nil && transport.rand.Float64() >= *rule.Probability {
+			continue
+		}
+		transport.injected[rule.Name] += 1
+		return rule
+	}
+	return nil
+}
+
+func (transport *Transport) RoundTrip(request *http.Request) (*http.Response, error) {
+	rule := transport.selectRule(request)
+	if rule == nil {
+		return transport.send(request)
+	}
+	switch rule.Fault {
+	case FaultLatency:
+		timer := time.NewTimer(time.Duration(rule.Latency))
+		defer timer.Stop()
+		select {
+		case <-timer.C:
+		case <-request.Context().Done():
+			closeRequestBody(request)
+			return nil, request.Context().Err()
+		}
+		return transport.send(request)
+
+	case FaultReset:
+		closeRequestBody(request)
+		return nil, &net.OpError{
+			Op:   "read",
+			Net:  "tcp",
+			Addr: fakeAddr(request.URL.Host),
+			Err:  fmt.Errorf("injected by rule %q: %w", rule.Name, syscall.ECONNRESET),
+		}
+
+	case FaultStatus:
+		closeRequestBody(request)
+		return &http.Response{
+			Status:     fmt.Sprintf("%d %s", rule.StatusCode, rule.ErrorType),
+			StatusCode: rule.StatusCode,
+			Proto:      "HTTP/1.1",
+			ProtoMajor: 1,
+			ProtoMinor: 1,
+			Header:     http.Header{},
+			Body:       http.NoBody,
+			Request:    request,
+		}, nil
+	}
+
+	response, err := transport.send(request)
+	if err != nil {
+		return nil, err
+	}
+	body, err := io.ReadAll(response.Body)
+	response.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	if rule.Fault == FaultTruncatedBody {
+		response.Body = &truncatedBody{
+			Reader: bytes.NewReader(body[:len(body)/2]),
+			err: fmt.Errorf("injected by rule %q: %w",
+				rule.Name, io.ErrUnexpectedEOF),
+		}
+	} else {
+		body = malformJSON(body)
+		response.Body = io.NopCloser(bytes.NewReader(body))
+		response.ContentLength = int64(len(body))
+		response.Header.Del("Content-Length")
+	}
+	return response, nil
+}
+
+func (transport *Transport) send(request *http.Request) (*http.Response, error) {
+	upstream := transport.upstream
+	if upstream == nil {
+		upstream = http.DefaultTransport
+	}
+	return upstream.RoundTrip(request)
+}
+
+// closeRequestBody closes the body of a request which is not sent, as
+// RoundTrip must always do
+func closeRequestBody(request *http.Request) {
+	if request.Body != nil {
+		request.Body.Close()
+	}
+}
+
+// malformJSON returns an invalid JSON document made of the first half
+// of body, which fails to parse whatever body holds
+func malformJSON(body []byte) []byte {
+	return append(slices.Clip(body[:len(body)/2]), []byte(`{"malformed`)...)
+}
+
+// truncatedBody reads the part of the response body kept, then fails
+type truncatedBody struct {
+	*bytes.Reader
+	err error
+}
+
+func (body *truncatedBody) Read(p []byte) (int, error) {
+	n, err := body.Reader.Read(p)
+	if err == io.EOF {
+		err = body.err
+	}
+	return n, err
+}
+
+func (body *truncatedBody) Close() error {
+	return nil
+}
+
+// fakeAddr is the address of the peer reported in connection reset
+// errors
+type fakeAddr string
+
+func (addr fakeAddr) Network() string { return "tcp" }
+func (addr fakeAddr) String() string  { return string(addr) }
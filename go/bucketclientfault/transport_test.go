@@ -0,0 +1,147 @@
+package bucketclientfault_test
+
+import (
+	"context"
+	"errors"
+	"syscall"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/scality/bucketclient/go"
+	"github.com/scality/bucketclient/go/bucketclientfault"
+	"github.com/scality/bucketclient/go/bucketclienttest"
+)
+
+var _ = Describe("Transport", func() {
+	var server *bucketclienttest.Server
+
+	BeforeEach(func(ctx SpecContext) {
+		server = bucketclienttest.NewServer()
+		DeferCleanup(server.Close)
+		client := server.NewClient()
+		for _, bucketName := range []string{"bucket1", "bucket2"} {
+			Expect(client.CreateBucket(ctx, bucketName, []byte(`{}`))).To(Succeed())
+			Expect(client.PostBatch(ctx, bucketName, []bucketclient.PostBatchEntry{
+				{Key: "a", Value: "1"},
+			})).To(Succeed())
+		}
+	})
+
+	newClient := func(rules ...bucketclientfault.Rule) (
+		*bucketclient.BucketClient, *bucketclientfault.Transport) {
+		transport, err := bucketclientfault.NewTransport(bucketclientfault.Config{
+			Seed:  1,
+			Rules: rules,
+		})
+		Expect(err).ToNot(HaveOccurred())
+		return server.NewClient(bucketclient.BucketClientTransportOption(transport)), transport
+	}
+
+	It("injects status codes into requests of the API methods selected", func(ctx SpecContext) {
+		client, transport := newClient(bucketclientfault.Rule{
+			Name:       "read-only",
+			ApiMethods: []string{"PostBatch"},
+			Fault:      bucketclientfault.FaultStatus,
+			StatusCode: 503,
+		})
+		err := client.PostBatch(ctx, "bucket1", []bucketclient.PostBatchEntry{{Key: "b", Value: "2"}})
+		var bcErr *bucketclient.BucketClientError
+		Expect(errors.As(err, &bcErr)).To(BeTrue())
+		Expect(bcErr.StatusCode).To(Equal(503))
+		Expect(bcErr.ErrorType).To(Equal("ServiceUnavailable"))
+		Expect(client.ListBasic(ctx, "bucket1")).To(Equal(&bucketclient.ListBasicResponse{
+			{Key: "a", Value: "1"},
+		}))
+		Expect(transport.Injected()).To(Equal(map[string]int{"read-only": 1}))
+	})
+
+	It("injects faults into requests to the buckets selected", func(ctx SpecContext) {
+		client, transport := newClient(bucketclientfault.Rule{
+			Buckets:    []string{"bucket2"},
+			Fault:      bucketclientfault.FaultStatus,
+			StatusCode: 500,
+			ErrorType:  "InternalError",
+		})
+		Expect(client.GetBucketAttributes(ctx, "bucket1")).To(Equal([]byte(`{}`)))
+		_, err := client.GetBucketAttributes(ctx, "bucket2")
+		var bcErr *bucketclient.BucketClientError
+		Expect(errors.As(err, &bcErr)).To(BeTrue())
+		Expect(bcErr.StatusCode).To(Equal(500))
+		Expect(bcErr.ErrorType).To(Equal("InternalError"))
+		Expect(transport.Injected()).To(Equal(map[string]int{"0-status": 1}))
+	})
+
+	It("resets connections", func(ctx SpecContext) {
+		client, _ := newClient(bucketclientfault.Rule{Fault: bucketclientfault.FaultReset})
+		_, err := client.GetBucketAttributes(ctx, "bucket1")
+		Expect(errors.Is(err, syscall.ECONNRESET)).To(BeTrue())
+	})
+
+	It("truncates response bodies", func(ctx SpecContext) {
+		client, _ := newClient(bucketclientfault.Rule{Fault: bucketclientfault.FaultTruncatedBody})
+		_, err := client.ListBasic(ctx, "bucket1")
+		Expect(err).To(MatchError(ContainSubstring("error reading response body")))
+		Expect(errors.Is(err, context.Canceled)).To(BeFalse())
+	})
+
+	It("malforms JSON responses", func(ctx SpecContext) {
+		client, _ := newClient(bucketclientfault.Rule{Fault: bucketclientfault.FaultMalformedJSON})
+		_, err := client.ListBasic(ctx, "bucket1")
+		Expect(err).To(HaveOccurred())
+		Expect(client.GetBucketAttributes(ctx, "bucket1")).To(Equal([]byte(`{{"malformed`)))
+	})
+
+	It("adds latency to requests", func(ctx SpecContext) {
+		client, _ := newClient(bucketclientfault.Rule{
+			Fault:   bucketclientfault.FaultLatency,
+			Latency: bucketclientfault.Duration(50 * time.Millisecond),
+		})
+		start := time.Now()
+		Expect(client.GetBucketAttributes(ctx, "bucket1")).To(Equal([]byte(`{}`)))
+		Expect(time.Since(start)).To(BeNumerically(">=", 50*time.Millisecond))
+
+		timeoutCtx, cancel := context.WithTimeout(ctx, 10*time.Millisecond)
+		defer cancel()
+		_, err := client.GetBucketAttributes(timeoutCtx, "bucket1")
+		Expect(errors.Is(err, context.DeadlineExceeded)).To(BeTrue())
+	})
+
+	It("injects faults with the probability given, deterministically from the seed",
+		func(ctx SpecContext) {
+			failures := func() []bool {
+				client, _ := newClient(bucketclientfault.Rule{
+					Probability: probability(0.3),
+					Fault:       bucketclientfault.FaultStatus,
+					StatusCode:  503,
+				})
+				var failures []bool
+				for range 100 {
+					_, err := client.GetBucketAttributes(ctx, "bucket1")
+					failures = append(failures, err != nil)
+				}
+				return failures
+			}
+			firstRun := failures()
+			Expect(failures()).To(Equal(firstRun))
+			nFailures := 0
+			for _, failed := range firstRun {
+				if failed {
+					nFailures += 1
+				}
+			}
+			Expect(nFailures).To(BeNumerically("~", 30, 15))
+		})
+
+	It("never injects faults with a zero probability", func(ctx SpecContext) {
+		client, _ := newClient(bucketclientfault.Rule{
+			Probability: probability(0),
+			Fault:       bucketclientfault.FaultStatus,
+			StatusCode:  503,
+		})
+		for range 10 {
+			Expect(client.GetBucketAttributes(ctx, "bucket1")).To(Equal([]byte(`{}`)))
+		}
+	})
+})
@@ -0,0 +1,26 @@
+package bucketclientotel_test
+
+import (
+	"testing"
+
+	"github.com/jarcoal/httpmock"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestBucketclientotel(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Bucketclientotel Suite")
+}
+
+var _ = BeforeSuite(func() {
+	httpmock.Activate()
+})
+
+var _ = AfterSuite(func() {
+	httpmock.DeactivateAndReset()
+})
+
+var _ = AfterEach(func() {
+	httpmock.Reset()
+})
@@ -0,0 +1,196 @@
+// Package bucketclientotel traces the requests sent by a
+// bucketclient.BucketClient to bucketd with OpenTelemetry.
+//
+// A Tracer creates a span for each call to BucketClient.Request with
+// its Intercept method, given to bucketclient.BucketClientInterceptorOption,
+// and records the HTTP requests sent for the call as events of the span
+// with the transport returned by its Transport method, given to
+// bucketclient.BucketClientTransportOption:
+//
+//	tracer := bucketclientotel.NewTracer(tracerProvider)
+//	client := bucketclient.New(endpoint,
+//		bucketclient.BucketClientInterceptorOption(tracer.Intercept),
+//		bucketclient.BucketClientTransportOption(tracer.Transport(nil)))
+package bucketclientotel
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/scality/bucketclient/go"
+)
+
+// TracerName is the name of the OpenTelemetry tracer creating the
+// spans of bucketd requests
+const TracerName = "github.com/scality/bucketclient/go/bucketclientotel"
+
+// attributes set on spans and span events of bucketd requests
+const (
+	bucketAttribute     = attribute.Key("bucketd.bucket")
+	resourceAttribute   = attribute.Key("bucketd.resource")
+	endpointAttribute   = attribute.Key("bucketd.endpoint")
+	attemptAttribute    = attribute.Key("bucketd.attempt")
+	httpMethodAttribute = attribute.Key("http.request.method")
+	statusAttribute     = attribute.Key("http.response.status_code")
+)
+
+type TracerOption func(*tracerOptionSet)
+
+// TracerPropagatorOption sets the propagator injecting the trace
+// context into the headers of requests sent to bucketd (default W3C
+// Trace Context)
+func TracerPropagatorOption(propagator propagation.TextMapPropagator) TracerOption {
+	return func(opts *tracerOptionSet) {
+		opts.propagator = propagator
+	}
+}
+
+type tracerOptionSet struct {
+	propagator propagation.TextMapPropagator
+}
+
+// Tracer traces bucketd requests with a tracer from an OpenTelemetry
+// tracer provider. Spans are named after the API method and are
+// children of the span carried by the request context, if any.
+//
+// Each HTTP request sent to bucketd for a call is recorded as a
+// "bucketd.attempt" event of its span, and hedging a request to a
+// secondary endpoint as a "bucketd.hedge" event. When reads are
+// coalesced with bucketclient.BucketClientCoalesceReads, attempts are
+// recorded on the span of the call which sent the shared request only,
+// and the spans of the calls which joined it get a "bucketd.coalesced"
+// event.
+type Tracer struct {
+	tracer     trace.Tracer
+	propagator propagation.TextMapPropagator
+}
+
+var _ bucketclient.RequestInterceptor = (*Tracer)(nil).Intercept
+
+// NewTracer creates a tracer of bucketd requests using tracerProvider
+func NewTracer(tracerProvider trace.TracerProvider, opts ...TracerOption) *Tracer {
+	options := tracerOptionSet{
+		propagator: propagation.TraceContext{},
+	}
+	for _, opt := range opts {
+		opt(&options)
+	}
+	return &Tracer{
+		tracer:     tracerProvider.Tracer(TracerName),
+		propagator: options.propagator,
+	}
+}
+
+// Intercept is a bucketclient.RequestInterceptor creating the span of
+// a call to Request. It should be the first interceptor, so that the
+// span covers the others.
+func (t *Tracer) Intercept(ctx context.Context, info *bucketclient.RequestInfo,
+	invoker bucketclient.RequestInvoker) ([]byte, error) {
+	attrs := []attribute.KeyValue{
+		httpMethodAttribute.String(info.HttpMethod),
+		resourceAttribute.String(info.Resource),
+	}
+	if bucketName := bucketclient.BucketNameFromResource(info.Resource); bucketName != "" {
+		attrs = append(attrs, bucketAttribute.String(bucketName))
+	}
+	ctx, span := t.tracer.Start(ctx, info.ApiMethod,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(attrs...))
+	defer span.End()
+
+	responseBody, err := invoker(ctx, info)
+	if info.Coalesced {
+		span.AddEvent("bucketd.coalesced")
+	}
+	if err != nil {
+		var bcErr *bucketclient.BucketClientError
+		if errors.As(err, &bcErr) && bcErr.StatusCode != 0 {
+			span.SetAttributes(statusAttribute.Int(bcErr.StatusCode))
+		}
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	return responseBody, err
+}
+
+// Transport returns a transport sending HTTP requests through base, or
+// http.DefaultTransport if nil, which injects the trace context into
+// their headers and records them as events of the span of their
+// context.
+func (t *Tracer) Transport(base http.RoundTripper) http.RoundTripper {
+	return &transport{tracer: t, base: base}
+}
+
+type transport struct {
+	tracer *Tracer
+	base   http.RoundTripper
+}
+
+// RoundTrip implements http.RoundTripper
+func (tr *transport) RoundTrip(request *http.Request) (*http.Response, error) {
+	ctx := request.Context()
+	span := trace.SpanFromContext(ctx)
+	endpoint := request.URL.Scheme + "://" + request.URL.Host
+	attempt := bucketclient.RequestAttemptFromContext(ctx)
+	if attempt > 1 {
+		span.AddEvent("bucketd.hedge",
+			trace.WithAttributes(endpointAttribute.String(endpoint)))
+	}
+	// the request must not be modified, inject headers into a copy
+	request = request.Clone(ctx)
+	tr.tracer.propagator.Inject(ctx, propagation.HeaderCarrier(request.Header))
+
+	base := tr.base
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	start := time.Now()
+	response, err := base.RoundTrip(request)
+	recordAttempt := func() {
+		attrs := []attribute.KeyValue{
+			attemptAttribute.Int(attempt),
+			endpointAttribute.String(endpoint),
+			attribute.Int64("duration_ms", time.Since(start).Milliseconds()),
+		}
+		switch {
+		case err != nil:
+			attrs = append(attrs, attribute.String("error", err.Error()))
+		case response.StatusCode/100 != 2:
+			attrs = append(attrs, statusAttribute.Int(response.StatusCode),
+				attribute.String("error", response.Status))
+		default:
+			attrs = append(attrs, statusAttribute.Int(response.StatusCode))
+			span.SetAttributes(statusAttribute.Int(response.StatusCode))
+		}
+		span.AddEvent("bucketd.attempt", trace.WithAttributes(attrs...))
+	}
+	if err != nil || response.Body == nil {
+		recordAttempt()
+		return response, err
+	}
+	// the attempt ends once its response body is read
+	response.Body = &attemptBody{ReadCloser: response.Body, end: recordAttempt}
+	return response, nil
+}
+
+// attemptBody calls end when closed
+type attemptBody struct {
+	io.ReadCloser
+	once sync.Once
+	end  func()
+}
+
+func (body *attemptBody) Close() error {
+	err := body.ReadCloser.Close()
+	body.once.Do(body.end)
+	return err
+}
@@ -0,0 +1,208 @@
+package bucketclientotel_test
+
+import (
+	"net/http"
+	"sync"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/jarcoal/httpmock"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/scality/bucketclient/go"
+	"github.com/scality/bucketclient/go/bucketclientotel"
+)
+
+// spanAttributes returns the attributes of a span or span event as a map
+func spanAttributes(attrs []attribute.KeyValue) map[string]any {
+	attrMap := map[string]any{}
+	for _, attr := range attrs {
+		attrMap[string(attr.Key)] = attr.Value.AsInterface()
+	}
+	return attrMap
+}
+
+var _ = Describe("Tracer", func() {
+	var (
+		exporter       *tracetest.InMemoryExporter
+		tracerProvider *sdktrace.TracerProvider
+	)
+
+	BeforeEach(func() {
+		exporter = tracetest.NewInMemoryExporter()
+		tracerProvider = sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+	})
+
+	// newTracingClient returns a client traced with tracerProvider
+	newTracingClient := func(opts ...bucketclient.BucketClientOption) *bucketclient.BucketClient {
+		tracer := bucketclientotel.NewTracer(tracerProvider)
+		return bucketclient.New("http://localhost:9000", append([]bucketclient.BucketClientOption{
+			bucketclient.BucketClientInterceptorOption(tracer.Intercept),
+			bucketclient.BucketClientTransportOption(tracer.Transport(nil)),
+		}, opts...)...)
+	}
+
+	It("creates a span per request with bucket, resource and status", func(ctx SpecContext) {
+		tracingClient := newTracingClient()
+		httpmock.RegisterResponder(
+			"GET", "/default/attributes/somebucket",
+			httpmock.NewStringResponder(200, `{}`),
+		)
+		_, err := tracingClient.GetBucketAttributes(ctx, "somebucket")
+		Expect(err).ToNot(HaveOccurred())
+
+		spans := exporter.GetSpans()
+		Expect(spans).To(HaveLen(1))
+		Expect(spans[0].Name).To(Equal("GetBucketAttributes"))
+		Expect(spans[0].SpanKind).To(Equal(trace.SpanKindClient))
+		Expect(spans[0].InstrumentationScope.Name).To(Equal(bucketclientotel.TracerName))
+		Expect(spanAttributes(spans[0].Attributes)).To(Equal(map[string]any{
+			"http.request.method":       "GET",
+			"bucketd.resource":          "/default/attributes/somebucket",
+			"bucketd.bucket":            "somebucket",
+			"http.response.status_code": int64(200),
+		}))
+		Expect(spans[0].Status.Code).To(Equal(codes.Unset))
+		Expect(spans[0].Events).To(HaveLen(1))
+		Expect(spans[0].Events[0].Name).To(Equal("bucketd.attempt"))
+		Expect(spanAttributes(spans[0].Events[0].Attributes)).To(And(
+			HaveKeyWithValue("bucketd.attempt", int64(1)),
+			HaveKeyWithValue("bucketd.endpoint", "http://localhost:9000"),
+			HaveKeyWithValue("http.response.status_code", int64(200)),
+		))
+	})
+
+	It("injects the W3C trace context into request headers", func(ctx SpecContext) {
+		tracingClient := newTracingClient()
+		var traceparent string
+		httpmock.RegisterResponder(
+			"GET", "/default/attributes/somebucket",
+			func(req *http.Request) (*http.Response, error) {
+				traceparent = req.Header.Get("traceparent")
+				return httpmock.NewStringResponse(200, `{}`), nil
+			},
+		)
+		parentCtx, parent := tracerProvider.Tracer("test").Start(ctx, "parent")
+		_, err := tracingClient.GetBucketAttributes(parentCtx, "somebucket")
+		Expect(err).ToNot(HaveOccurred())
+		parent.End()
+
+		spans := exporter.GetSpans()
+		Expect(spans).To(HaveLen(2))
+		requestSpan := spans[0]
+		Expect(requestSpan.Parent.SpanID()).To(Equal(parent.SpanContext().SpanID()))
+		Expect(requestSpan.SpanContext.TraceID()).To(Equal(parent.SpanContext().TraceID()))
+		Expect(traceparent).To(Equal("00-" + requestSpan.SpanContext.TraceID().String() +
+			"-" + requestSpan.SpanContext.SpanID().String() + "-01"))
+	})
+
+	It("records errors on the span", func(ctx SpecContext) {
+		tracingClient := newTracingClient()
+		httpmock.RegisterResponder(
+			"POST", "/default/batch/somebucket",
+			httpmock.NewStringResponder(404, ""),
+		)
+		Expect(tracingClient.PostBatch(ctx, "somebucket", []bucketclient.PostBatchEntry{
+			{Key: "foo", Type: "del"},
+		})).ToNot(Succeed())
+
+		spans := exporter.GetSpans()
+		Expect(spans).To(HaveLen(1))
+		Expect(spans[0].Name).To(Equal("PostBatch"))
+		Expect(spans[0].Status.Code).To(Equal(codes.Error))
+		Expect(spanAttributes(spans[0].Attributes)).To(
+			HaveKeyWithValue("http.response.status_code", int64(404)))
+		Expect(spans[0].Events).To(HaveLen(2))
+		Expect(spans[0].Events[0].Name).To(Equal("bucketd.attempt"))
+		Expect(spanAttributes(spans[0].Events[0].Attributes)).To(And(
+			HaveKeyWithValue("http.response.status_code", int64(404)),
+			HaveKey("error"),
+		))
+		Expect(spans[0].Events[1].Name).To(Equal("exception"))
+	})
+
+	It("records hedged attempts as span events", func(ctx SpecContext) {
+		tracingClient := newTracingClient(
+			bucketclient.BucketClientHedgingOption([]string{"http://localhost:9001"},
+				bucketclient.HedgingDelayOption(time.Hour)))
+		httpmock.RegisterResponder(
+			"GET", "http://localhost:9000/default/attributes/somebucket",
+			httpmock.NewStringResponder(503, ""),
+		)
+		httpmock.RegisterResponder(
+			"GET", "http://localhost:9001/default/attributes/somebucket",
+			httpmock.NewStringResponder(200, `{}`),
+		)
+		_, err := tracingClient.GetBucketAttributes(ctx, "somebucket")
+		Expect(err).ToNot(HaveOccurred())
+
+		spans := exporter.GetSpans()
+		Expect(spans).To(HaveLen(1))
+		Expect(spans[0].Status.Code).To(Equal(codes.Unset))
+		events := spans[0].Events
+		Expect(events).To(HaveLen(3))
+		Expect(events[0].Name).To(Equal("bucketd.attempt"))
+		Expect(spanAttributes(events[0].Attributes)).To(And(
+			HaveKeyWithValue("bucketd.attempt", int64(1)),
+			HaveKeyWithValue("bucketd.endpoint", "http://localhost:9000"),
+			HaveKeyWithValue("http.response.status_code", int64(503)),
+		))
+		Expect(events[1].Name).To(Equal("bucketd.hedge"))
+		Expect(spanAttributes(events[1].Attributes)).To(
+			HaveKeyWithValue("bucketd.endpoint", "http://localhost:9001"))
+		Expect(events[2].Name).To(Equal("bucketd.attempt"))
+		Expect(spanAttributes(events[2].Attributes)).To(And(
+			HaveKeyWithValue("bucketd.attempt", int64(2)),
+			HaveKeyWithValue("bucketd.endpoint", "http://localhost:9001"),
+			HaveKeyWithValue("http.response.status_code", int64(200)),
+		))
+	})
+
+	It("records calls joining a coalesced request as span events", func(ctx SpecContext) {
+		tracingClient := newTracingClient(bucketclient.BucketClientCoalesceReads)
+		release := make(chan struct{})
+		httpmock.RegisterResponder(
+			"GET", "/default/attributes/somebucket",
+			func(req *http.Request) (*http.Response, error) {
+				<-release
+				return httpmock.NewStringResponse(200, `{}`), nil
+			},
+		)
+		var wg sync.WaitGroup
+		for i := 0; i < 2; i++ {
+			wg.Add(1)
+			go func() {
+				defer GinkgoRecover()
+				defer wg.Done()
+				_, err := tracingClient.GetBucketAttributes(ctx, "somebucket")
+				Expect(err).ToNot(HaveOccurred())
+			}()
+		}
+		Eventually(httpmock.GetTotalCallCount).Should(Equal(1))
+		time.Sleep(10 * time.Millisecond)
+		close(release)
+		wg.Wait()
+		Expect(httpmock.GetTotalCallCount()).To(Equal(1))
+
+		spans := exporter.GetSpans()
+		Expect(spans).To(HaveLen(2))
+		eventNames := [][]string{}
+		for _, span := range spans {
+			names := []string{}
+			for _, event := range span.Events {
+				names = append(names, event.Name)
+			}
+			eventNames = append(eventNames, names)
+		}
+		Expect(eventNames).To(ConsistOf(
+			[]string{"bucketd.attempt"},
+			[]string{"bucketd.coalesced"},
+		))
+	})
+})
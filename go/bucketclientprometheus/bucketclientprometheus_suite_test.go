@@ -0,0 +1,26 @@
+package bucketclientprometheus_test
+
+import (
+	"testing"
+
+	"github.com/jarcoal/httpmock"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestBucketclientprometheus(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Bucketclientprometheus Suite")
+}
+
+var _ = BeforeSuite(func() {
+	httpmock.Activate()
+})
+
+var _ = AfterSuite(func() {
+	httpmock.DeactivateAndReset()
+})
+
+var _ = AfterEach(func() {
+	httpmock.Reset()
+})
@@ -0,0 +1,183 @@
+// Package bucketclientprometheus exports measurements of the requests
+// sent by a bucketclient.BucketClient to bucketd as Prometheus metrics.
+package bucketclientprometheus
+
+import (
+	"context"
+	"errors"
+	"strconv"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/scality/bucketclient/go"
+)
+
+type CollectorOption func(*collectorOptionSet)
+
+// CollectorNamespaceOption sets the namespace prefixing the name of all
+// metrics (default "bucketclient")
+func CollectorNamespaceOption(namespace string) CollectorOption {
+	return func(opts *collectorOptionSet) {
+		opts.namespace = namespace
+	}
+}
+
+// CollectorConstLabelsOption adds labels with constant values to all
+// metrics, e.g. to tell apart multiple clients registered together
+func CollectorConstLabelsOption(labels prometheus.Labels) CollectorOption {
+	return func(opts *collectorOptionSet) {
+		opts.constLabels = labels
+	}
+}
+
+// CollectorDurationBucketsOption sets the buckets of the request
+// duration histogram, in seconds (default prometheus.DefBuckets)
+func CollectorDurationBucketsOption(buckets []float64) CollectorOption {
+	return func(opts *collectorOptionSet) {
+		opts.durationBuckets = buckets
+	}
+}
+
+// CollectorSizeBucketsOption sets the buckets of the response body size
+// histogram, in bytes (default powers of 4 from 64B to 16MiB)
+func CollectorSizeBucketsOption(buckets []float64) CollectorOption {
+	return func(opts *collectorOptionSet) {
+		opts.sizeBuckets = buckets
+	}
+}
+
+type collectorOptionSet struct {
+	namespace       string
+	constLabels     prometheus.Labels
+	durationBuckets []float64
+	sizeBuckets     []float64
+}
+
+// Collector is a prometheus.Collector of metrics on bucketd requests,
+// fed by BucketClient instances it is given to with
+// bucketclient.BucketClientMetricsOption. It exports:
+//
+//   - requests_total: counter of requests, by API method, status class
+//     and endpoint
+//   - request_duration_seconds: histogram of request durations, by
+//     API method, status class and endpoint
+//   - requests_in_flight: gauge of pending requests, by API method and
+//     endpoint
+//   - request_retries_total: counter of requests sent again to an
+//     endpoint, like hedged ones, by API method and endpoint
+//   - response_size_bytes: histogram of response body sizes of
+//     successful requests, by API method and endpoint
+//
+// The status class is "2xx", "4xx", "5xx" etc. for requests which got
+// a response, "canceled" for requests whose context ended before, and
+// "error" for other transport errors.
+type Collector struct {
+	requests     *prometheus.CounterVec
+	duration     *prometheus.HistogramVec
+	inFlight     *prometheus.GaugeVec
+	retries      *prometheus.CounterVec
+	responseSize *prometheus.HistogramVec
+}
+
+var _ prometheus.Collector = (*Collector)(nil)
+var _ bucketclient.RequestMetrics = (*Collector)(nil)
+
+// NewCollector creates a collector to register to a Prometheus registry
+func NewCollector(opts ...CollectorOption) *Collector {
+	options := collectorOptionSet{
+		namespace:       "bucketclient",
+		durationBuckets: prometheus.DefBuckets,
+		sizeBuckets:     prometheus.ExponentialBuckets(64, 4, 10),
+	}
+	for _, opt := range opts {
+		opt(&options)
+	}
+	return &Collector{
+		requests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace:   options.namespace,
+			Name:        "requests_total",
+			Help:        "Number of requests sent to bucketd.",
+			ConstLabels: options.constLabels,
+		}, []string{"api_method", "status_class", "endpoint"}),
+		duration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace:   options.namespace,
+			Name:        "request_duration_seconds",
+			Help:        "Duration of requests sent to bucketd.",
+			ConstLabels: options.constLabels,
+			Buckets:     options.durationBuckets,
+		}, []string{"api_method", "status_class", "endpoint"}),
+		inFlight: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace:   options.namespace,
+			Name:        "requests_in_flight",
+			Help:        "Number of requests sent to bucketd still pending.",
+			ConstLabels: options.constLabels,
+		}, []string{"api_method", "endpoint"}),
+		retries: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace:   options.namespace,
+			Name:        "request_retries_total",
+			Help:        "Number of requests sent again to bucketd, including hedged ones.",
+			ConstLabels: options.constLabels,
+		}, []string{"api_method", "endpoint"}),
+		responseSize: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace:   options.namespace,
+			Name:        "response_size_bytes",
+			Help:        "Size of response bodies of successful requests sent to bucketd.",
+			ConstLabels: options.constLabels,
+			Buckets:     options.sizeBuckets,
+		}, []string{"api_method", "endpoint"}),
+	}
+}
+
+// Describe implements prometheus.Collector
+func (c *Collector) Describe(ch chan<- *prometheus.Desc) {
+	c.requests.Describe(ch)
+	c.duration.Describe(ch)
+	c.inFlight.Describe(ch)
+	c.retries.Describe(ch)
+	c.responseSize.Describe(ch)
+}
+
+// Collect implements prometheus.Collector
+func (c *Collector) Collect(ch chan<- prometheus.Metric) {
+	c.requests.Collect(ch)
+	c.duration.Collect(ch)
+	c.inFlight.Collect(ch)
+	c.retries.Collect(ch)
+	c.responseSize.Collect(ch)
+}
+
+// RequestStarted implements bucketclient.RequestMetrics
+func (c *Collector) RequestStarted(apiMethod string, endpoint string) {
+	c.inFlight.WithLabelValues(apiMethod, endpoint).Inc()
+}
+
+// RequestEnded implements bucketclient.RequestMetrics
+func (c *Collector) RequestEnded(measurement bucketclient.RequestMeasurement) {
+	c.inFlight.WithLabelValues(measurement.ApiMethod, measurement.Endpoint).Dec()
+	statusClass := StatusClass(measurement)
+	c.requests.WithLabelValues(measurement.ApiMethod, statusClass,
+		measurement.Endpoint).Inc()
+	c.duration.WithLabelValues(measurement.ApiMethod, statusClass,
+		measurement.Endpoint).Observe(measurement.Duration.Seconds())
+	if measurement.Err == nil {
+		c.responseSize.WithLabelValues(measurement.ApiMethod,
+			measurement.Endpoint).Observe(float64(measurement.ResponseBodySize))
+	}
+}
+
+// RequestRetried implements bucketclient.RequestMetrics
+func (c *Collector) RequestRetried(apiMethod string, endpoint string) {
+	c.retries.WithLabelValues(apiMethod, endpoint).Inc()
+}
+
+// StatusClass returns the status class label of a request measurement
+func StatusClass(measurement bucketclient.RequestMeasurement) string {
+	if measurement.StatusCode != 0 {
+		return strconv.Itoa(measurement.StatusCode/100) + "xx"
+	}
+	if errors.Is(measurement.Err, context.Canceled) ||
+		errors.Is(measurement.Err, context.DeadlineExceeded) {
+		return "canceled"
+	}
+	return "error"
+}
@@ -0,0 +1,162 @@
+package bucketclientprometheus_test
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/jarcoal/httpmock"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+
+	"github.com/scality/bucketclient/go"
+	"github.com/scality/bucketclient/go/bucketclientprometheus"
+)
+
+var _ = Describe("Collector", func() {
+	var (
+		collector *bucketclientprometheus.Collector
+		client    *bucketclient.BucketClient
+	)
+
+	BeforeEach(func() {
+		collector = bucketclientprometheus.NewCollector()
+		client = bucketclient.New("http://localhost:9000",
+			bucketclient.BucketClientMetricsOption(collector))
+	})
+
+	It("counts requests by API method, status class and endpoint", func(ctx SpecContext) {
+		httpmock.RegisterResponder(
+			"GET", "/default/attributes/somebucket",
+			httpmock.NewStringResponder(200, `{"name":"somebucket"}`),
+		)
+		httpmock.RegisterResponder(
+			"GET", "/default/attributes/missing",
+			httpmock.NewStringResponder(404, ""),
+		)
+		httpmock.RegisterResponder(
+			"POST", "/default/batch/somebucket",
+			httpmock.NewStringResponder(503, ""),
+		)
+		for range 2 {
+			_, err := client.GetBucketAttributes(ctx, "somebucket")
+			Expect(err).ToNot(HaveOccurred())
+		}
+		_, err := client.GetBucketAttributes(ctx, "missing")
+		Expect(err).To(HaveOccurred())
+		Expect(client.PostBatch(ctx, "somebucket", []bucketclient.PostBatchEntry{
+			{Key: "foo", Type: "del"},
+		})).ToNot(Succeed())
+
+		Expect(testutil.CollectAndCompare(collector, strings.NewReader(`
+# HELP bucketclient_requests_total Number of requests sent to bucketd.
+# TYPE bucketclient_requests_total counter
+bucketclient_requests_total{api_method="GetBucketAttributes",endpoint="http://localhost:9000",status_class="2xx"} 2
+bucketclient_requests_total{api_method="GetBucketAttributes",endpoint="http://localhost:9000",status_class="4xx"} 1
+bucketclient_requests_total{api_method="PostBatch",endpoint="http://localhost:9000",status_class="5xx"} 1
+# HELP bucketclient_requests_in_flight Number of requests sent to bucketd still pending.
+# TYPE bucketclient_requests_in_flight gauge
+bucketclient_requests_in_flight{api_method="GetBucketAttributes",endpoint="http://localhost:9000"} 0
+bucketclient_requests_in_flight{api_method="PostBatch",endpoint="http://localhost:9000"} 0
+`), "bucketclient_requests_total", "bucketclient_requests_in_flight")).To(Succeed())
+		Expect(testutil.CollectAndCount(collector, "bucketclient_request_duration_seconds")).To(Equal(3))
+		Expect(testutil.CollectAndCount(collector, "bucketclient_response_size_bytes")).To(Equal(1))
+	})
+
+	It("measures response body sizes", func(ctx SpecContext) {
+		collector = bucketclientprometheus.NewCollector(
+			bucketclientprometheus.CollectorNamespaceOption("test"),
+			bucketclientprometheus.CollectorSizeBucketsOption([]float64{10, 100}))
+		client = bucketclient.New("http://localhost:9000",
+			bucketclient.BucketClientMetricsOption(collector))
+		httpmock.RegisterResponder(
+			"GET", "/default/attributes/somebucket",
+			httpmock.NewStringResponder(200, `{"name":"somebucket"}`),
+		)
+		_, err := client.GetBucketAttributes(ctx, "somebucket")
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(testutil.CollectAndCompare(collector, strings.NewReader(`
+# HELP test_response_size_bytes Size of response bodies of successful requests sent to bucketd.
+# TYPE test_response_size_bytes histogram
+test_response_size_bytes_bucket{api_method="GetBucketAttributes",endpoint="http://localhost:9000",le="10"} 0
+test_response_size_bytes_bucket{api_method="GetBucketAttributes",endpoint="http://localhost:9000",le="100"} 1
+test_response_size_bytes_bucket{api_method="GetBucketAttributes",endpoint="http://localhost:9000",le="+Inf"} 1
+test_response_size_bytes_sum{api_method="GetBucketAttributes",endpoint="http://localhost:9000"} 21
+test_response_size_bytes_count{api_method="GetBucketAttributes",endpoint="http://localhost:9000"} 1
+`), "test_response_size_bytes")).To(Succeed())
+	})
+
+	It("tracks requests in flight", func(ctx SpecContext) {
+		inFlight := make(chan float64, 1)
+		httpmock.RegisterResponder(
+			"GET", "/default/attributes/somebucket",
+			func(req *http.Request) (*http.Response, error) {
+				inFlight <- testutil.ToFloat64(collector)
+				return httpmock.NewStringResponse(200, `{}`), nil
+			},
+		)
+		_, err := client.GetBucketAttributes(ctx, "somebucket")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(<-inFlight).To(Equal(1.0))
+	})
+
+	It("counts hedged requests as retries", func(ctx SpecContext) {
+		client = bucketclient.New("http://localhost:9000",
+			bucketclient.BucketClientMetricsOption(collector),
+			bucketclient.BucketClientHedgingOption([]string{"http://localhost:9001"},
+				bucketclient.HedgingDelayOption(time.Hour)))
+		httpmock.RegisterResponder(
+			"GET", "http://localhost:9000/default/attributes/somebucket",
+			httpmock.NewStringResponder(503, ""),
+		)
+		httpmock.RegisterResponder(
+			"GET", "http://localhost:9001/default/attributes/somebucket",
+			httpmock.NewStringResponder(200, `{}`),
+		)
+		_, err := client.GetBucketAttributes(ctx, "somebucket")
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(testutil.CollectAndCompare(collector, strings.NewReader(`
+# HELP bucketclient_request_retries_total Number of requests sent again to bucketd, including hedged ones.
+# TYPE bucketclient_request_retries_total counter
+bucketclient_request_retries_total{api_method="GetBucketAttributes",endpoint="http://localhost:9001"} 1
+# HELP bucketclient_requests_total Number of requests sent to bucketd.
+# TYPE bucketclient_requests_total counter
+bucketclient_requests_total{api_method="GetBucketAttributes",endpoint="http://localhost:9000",status_class="5xx"} 1
+bucketclient_requests_total{api_method="GetBucketAttributes",endpoint="http://localhost:9001",status_class="2xx"} 1
+`), "bucketclient_request_retries_total", "bucketclient_requests_total")).To(Succeed())
+	})
+
+	It("registers multiple collectors with different constant labels", func() {
+		registry := prometheus.NewPedanticRegistry()
+		for _, name := range []string{"main", "other"} {
+			Expect(registry.Register(bucketclientprometheus.NewCollector(
+				bucketclientprometheus.CollectorConstLabelsOption(
+					prometheus.Labels{"client": name})))).To(Succeed())
+		}
+		Expect(registry.Register(collector)).ToNot(Succeed())
+	})
+})
+
+var _ = Describe("StatusClass", func() {
+	It("returns the class of the status, or the kind of error", func() {
+		Expect(bucketclientprometheus.StatusClass(bucketclient.RequestMeasurement{
+			StatusCode: 200,
+		})).To(Equal("2xx"))
+		Expect(bucketclientprometheus.StatusClass(bucketclient.RequestMeasurement{
+			StatusCode: 503,
+			Err:        &bucketclient.BucketClientError{StatusCode: 503},
+		})).To(Equal("5xx"))
+		Expect(bucketclientprometheus.StatusClass(bucketclient.RequestMeasurement{
+			Err: &bucketclient.BucketClientError{Err: context.Canceled},
+		})).To(Equal("canceled"))
+		Expect(bucketclientprometheus.StatusClass(bucketclient.RequestMeasurement{
+			Err: &bucketclient.BucketClientError{Err: http.ErrHandlerTimeout},
+		})).To(Equal("error"))
+	})
+})
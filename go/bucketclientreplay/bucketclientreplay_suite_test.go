@@ -0,0 +1,13 @@
+package bucketclientreplay_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestBucketclientreplay(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Bucketclientreplay Suite")
+}
@@ -0,0 +1,121 @@
+package bucketclientreplay
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"unicode/utf8"
+)
+
+// CassetteVersion is the version of the cassette file format written
+// by this package
+const CassetteVersion = 1
+
+// Cassette is the on-disk format of recorded exchanges with bucketd,
+// stored as indented JSON. Interactions are kept in the order they were
+// recorded, and only depend on the requests sent and the responses
+// received: the bucketd endpoint is not part of it, so that a cassette
+// recorded against one cluster replays against any endpoint.
+type Cassette struct {
+	Version      int           `json:"version"`
+	Interactions []Interaction `json:"interactions"`
+}
+
+// Interaction is a request sent to bucketd along with its response
+type Interaction struct {
+	Request  RecordedRequest  `json:"request"`
+	Response RecordedResponse `json:"response"`
+}
+
+// RecordedRequest holds the parts of a request it is matched on
+type RecordedRequest struct {
+	Method string `json:"method"`
+	// Resource is the escaped path of the request, e.g.
+	// "/default/bucket/somebucket/dir%2Fobj"
+	Resource string `json:"resource"`
+	// Query is the query string of the request, with parameters
+	// sorted by name
+	Query string `json:"query,omitempty"`
+	Body  Body   `json:"body,omitempty"`
+}
+
+// RecordedResponse is the response received for a request
+type RecordedResponse struct {
+	// Status is the full status line, e.g. "404 NoSuchBucket", since
+	// bucketd reports error types as reason phrases
+	Status     string      `json:"status"`
+	StatusCode int         `json:"statusCode"`
+	Header     http.Header `json:"header,omitempty"`
+	Body       Body        `json:"body,omitempty"`
+}
+
+// Body is a request or response body. It is stored in cassettes as a
+// JSON string when it is valid UTF-8, which is the case of all bucketd
+// payloads, and as a {"base64": "..."} object otherwise.
+type Body []byte
+
+func (body Body) MarshalJSON() ([]byte, error) {
+	if utf8.Valid(body) {
+		return marshalJSON(string(body))
+	}
+	return marshalJSON(struct {
+		Base64 string `json:"base64"`
+	}{base64.StdEncoding.EncodeToString(body)})
+}
+
+func (body *Body) UnmarshalJSON(data []byte) error {
+	var text string
+	if err := json.Unmarshal(data, &text); err == nil {
+		*body = Body(text)
+		return nil
+	}
+	var encoded struct {
+		Base64 string `json:"base64"`
+	}
+	if err := json.Unmarshal(data, &encoded); err != nil {
+		return err
+	}
+	decoded, err := base64.StdEncoding.DecodeString(encoded.Base64)
+	if err != nil {
+		return err
+	}
+	*body = decoded
+	return nil
+}
+
+// LoadCassette reads the cassette stored at path
+func LoadCassette(path string) (*Cassette, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var cassette Cassette
+	if err := json.Unmarshal(data, &cassette); err != nil {
+		return nil, fmt.Errorf("error parsing cassette %q: %w", path, err)
+	}
+	if cassette.Version != CassetteVersion {
+		return nil, fmt.Errorf("unsupported version %d of cassette %q (expected %d)",
+			cassette.Version, path, CassetteVersion)
+	}
+	return &cassette, nil
+}
+
+// Save writes the cassette to path, creating its parent directory if
+// needed
+func (cassette *Cassette) Save(path string) error {
+	var buffer bytes.Buffer
+	encoder := json.NewEncoder(&buffer)
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(cassette); err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, buffer.Bytes(), 0o644)
+}
@@ -0,0 +1,48 @@
+package bucketclientreplay_test
+
+import (
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/scality/bucketclient/go/bucketclientreplay"
+)
+
+var _ = Describe("Cassette", func() {
+	It("saves and loads interactions", func() {
+		cassettePath := filepath.Join(GinkgoT().TempDir(), "cassettes", "cassette.json")
+		cassette := &bucketclientreplay.Cassette{
+			Version: bucketclientreplay.CassetteVersion,
+			Interactions: []bucketclientreplay.Interaction{{
+				Request: bucketclientreplay.RecordedRequest{
+					Method:   "POST",
+					Resource: "/default/bucket/somebucket/obj",
+					Body:     bucketclientreplay.Body("\xff\xfe"),
+				},
+				Response: bucketclientreplay.RecordedResponse{
+					Status:     "200 OK",
+					StatusCode: 200,
+					Body:       bucketclientreplay.Body(`{"key":"<a&b>"}`),
+				},
+			}},
+		}
+		Expect(cassette.Save(cassettePath)).To(Succeed())
+		data, err := os.ReadFile(cassettePath)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(string(data)).To(ContainSubstring(`"body": {
+          "base64": "//4="
+        }`))
+		Expect(string(data)).To(ContainSubstring(`"body": "{\"key\":\"<a&b>\"}"`))
+
+		Expect(bucketclientreplay.LoadCassette(cassettePath)).To(Equal(cassette))
+	})
+
+	It("refuses cassettes of unknown versions", func() {
+		cassettePath := filepath.Join(GinkgoT().TempDir(), "cassette.json")
+		Expect(os.WriteFile(cassettePath, []byte(`{"version":2,"interactions":[]}`), 0o644)).To(Succeed())
+		_, err := bucketclientreplay.LoadCassette(cassettePath)
+		Expect(err).To(MatchError(ContainSubstring("unsupported version 2")))
+	})
+})
@@ -0,0 +1,169 @@
+package bucketclientreplay
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/url"
+)
+
+// RedactedValue replaces sensitive values in cassettes
+const RedactedValue = "[REDACTED]"
+
+// defaultRedactedHeaders are the headers always redacted from cassettes
+var defaultRedactedHeaders = []string{
+	"Authorization",
+	"Proxy-Authorization",
+	"Cookie",
+	"Set-Cookie",
+}
+
+// redactor replaces sensitive values of requests and responses by
+// RedactedValue before they are written to or matched against a
+// cassette
+type redactor struct {
+	headers     map[string]bool
+	queryParams map[string]bool
+	jsonFields  map[string]bool
+}
+
+func newRedactor() *redactor {
+	r := &redactor{
+		headers:     map[string]bool{},
+		queryParams: map[string]bool{},
+		jsonFields:  map[string]bool{},
+	}
+	for _, name := range defaultRedactedHeaders {
+		r.headers[name] = true
+	}
+	return r
+}
+
+// redactHeader returns a copy of header with sensitive values redacted.
+// Content-Length is dropped since it is computed from the body.
+func (r *redactor) redactHeader(header http.Header) http.Header {
+	if len(header) == 0 {
+		return nil
+	}
+	redacted := http.Header{}
+	for name, values := range header {
+		name = http.CanonicalHeaderKey(name)
+		switch {
+		case name == "Content-Length":
+			continue
+		case r.headers[name]:
+			redacted[name] = []string{RedactedValue}
+		default:
+			redacted[name] = append([]string(nil), values...)
+		}
+	}
+	return redacted
+}
+
+// normalizeQuery returns rawQuery with sensitive parameters redacted
+// and parameters sorted by name, keeping the order of values of each
+// parameter
+func (r *redactor) normalizeQuery(rawQuery string) (string, error) {
+	if rawQuery == "" {
+		return "", nil
+	}
+	values, err := url.ParseQuery(rawQuery)
+	if err != nil {
+		return "", err
+	}
+	for name := range values {
+		if r.queryParams[name] {
+			values[name] = []string{RedactedValue}
+		}
+	}
+	return values.Encode(), nil
+}
+
+// redactBody redacts the sensitive fields of JSON bodies. Other bodies
+// are returned as is.
+func (r *redactor) redactBody(body []byte) []byte {
+	if len(r.jsonFields) == 0 || len(body) == 0 {
+		return body
+	}
+	redacted, _ := r.redactJSON(body)
+	return redacted
+}
+
+// normalizeBody redacts request bodies and compacts JSON ones, so that
+// they match regardless of their formatting
+func (r *redactor) normalizeBody(body []byte) []byte {
+	body = r.redactBody(body)
+	if len(body) == 0 || !json.Valid(body) {
+		return body
+	}
+	var compacted bytes.Buffer
+	if err := json.Compact(&compacted, body); err != nil {
+		return body
+	}
+	return compacted.Bytes()
+}
+
+// redactJSON redacts the sensitive fields of the JSON document data,
+// and returns the re-encoded document if any was found. Since bucketd
+// stores object and bucket metadata as JSON strings, e.g. in listing
+// values, string values holding JSON objects or arrays are redacted as
+// well.
+func (r *redactor) redactJSON(data []byte) ([]byte, bool) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var document any
+	if err := decoder.Decode(&document); err != nil {
+		return data, false
+	}
+	document, changed := r.redactValue(document)
+	if !changed {
+		return data, false
+	}
+	encoded, err := marshalJSON(document)
+	if err != nil {
+		return data, false
+	}
+	return encoded, true
+}
+
+func (r *redactor) redactValue(value any) (any, bool) {
+	changed := false
+	switch v := value.(type) {
+	case map[string]any:
+		for key, field := range v {
+			if r.jsonFields[key] {
+				v[key] = RedactedValue
+				changed = true
+			} else if redacted, fieldChanged := r.redactValue(field); fieldChanged {
+				v[key] = redacted
+				changed = true
+			}
+		}
+	case []any:
+		for i, item := range v {
+			if redacted, itemChanged := r.redactValue(item); itemChanged {
+				v[i] = redacted
+				changed = true
+			}
+		}
+	case string:
+		if len(v) > 0 && (v[0] == '{' || v[0] == '[') {
+			if redacted, stringChanged := r.redactJSON([]byte(v)); stringChanged {
+				return string(redacted), true
+			}
+		}
+	}
+	return value, changed
+}
+
+// marshalJSON encodes value without escaping HTML characters, to keep
+// redacted payloads as close as possible to the original ones
+func marshalJSON(value any) ([]byte, error) {
+	var buffer bytes.Buffer
+	encoder := json.NewEncoder(&buffer)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(value); err != nil {
+		return nil, err
+	}
+	return bytes.TrimSuffix(buffer.Bytes(), []byte("\n")), nil
+}
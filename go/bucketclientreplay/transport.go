@@ -0,0 +1,357 @@
+// Package bucketclientreplay records the HTTP exchanges of a
+// bucketclient.BucketClient with bucketd into cassette files, and
+// replays them later without a bucketd, to run integration tests
+// deterministically and offline.
+//
+// A test typically records its cassette once against a real bucketd,
+// then replays it in CI:
+//
+//	mode := bucketclientreplay.ModeReplay
+//	if os.Getenv("BUCKETD_RECORD") != "" {
+//		mode = bucketclientreplay.ModeRecord
+//	}
+//	transport, err := bucketclientreplay.NewTransport("testdata/listing.json", mode)
+//	...
+//	defer transport.Save()
+//	client := bucketclient.New(endpoint,
+//		bucketclient.BucketClientTransportOption(transport))
+package bucketclientreplay
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// Mode tells whether a Transport records or replays exchanges
+type Mode int
+
+const (
+	// ModeReplay answers requests with the responses recorded in the
+	// cassette, without sending them
+	ModeReplay Mode = iota
+	// ModeRecord sends requests and records them with their responses
+	// into a new cassette
+	ModeRecord
+)
+
+func (mode Mode) String() string {
+	switch mode {
+	case ModeReplay:
+		return "replay"
+	case ModeRecord:
+		return "record"
+	default:
+		return fmt.Sprintf("Mode(%d)", int(mode))
+	}
+}
+
+type TransportOption func(*transportOptionSet)
+
+// TransportUpstreamOption sets the transport sending requests in
+// record mode (default http.DefaultTransport)
+func TransportUpstreamOption(upstream http.RoundTripper) TransportOption {
+	return func(opts *transportOptionSet) {
+		opts.upstream = upstream
+	}
+}
+
+// TransportRedactHeadersOption redacts the values of the given request
+// and response headers, in addition to Authorization,
+// Proxy-Authorization, Cookie and Set-Cookie
+func TransportRedactHeadersOption(names ...string) TransportOption {
+	return func(opts *transportOptionSet) {
+		for _, name := range names {
+			opts.redactor.headers[http.CanonicalHeaderKey(name)] = true
+		}
+	}
+}
+
+// TransportRedactQueryParamsOption redacts the values of the given
+// query parameters of requests
+func TransportRedactQueryParamsOption(names ...string) TransportOption {
+	return func(opts *transportOptionSet) {
+		for _, name := range names {
+			opts.redactor.queryParams[name] = true
+		}
+	}
+}
+
+// TransportRedactJSONFieldsOption redacts the values of all fields with
+// one of the given names in JSON request and response bodies, at any
+// depth, including in JSON documents stored as strings like object
+// metadata in listings, e.g. "owner-display-name"
+func TransportRedactJSONFieldsOption(names ...string) TransportOption {
+	return func(opts *transportOptionSet) {
+		for _, name := range names {
+			opts.redactor.jsonFields[name] = true
+		}
+	}
+}
+
+type transportOptionSet struct {
+	upstream http.RoundTripper
+	redactor *redactor
+}
+
+// Transport is an http.RoundTripper recording or replaying the
+// requests sent through it, to be passed to
+// bucketclient.BucketClientTransportOption. It is safe for concurrent
+// use.
+//
+// Requests are matched on their method, their escaped path, their
+// query string with parameters sorted by name, and their body, with
+// JSON bodies compacted. Sensitive values are redacted before requests
+// are recorded or matched, and headers of requests are neither recorded
+// nor matched. Each recorded interaction is replayed at most once, in
+// the order they were recorded when several match the same request.
+type Transport struct {
+	cassettePath string
+	mode         Mode
+	upstream     http.RoundTripper
+	redactor     *redactor
+
+	mutex    sync.Mutex
+	cassette *Cassette
+	replayed []bool
+}
+
+// NewTransport creates a transport recording exchanges into the
+// cassette at cassettePath, or replaying them from it, depending on
+// mode. In record mode, the cassette is only written by Save, replacing
+// any existing one.
+func NewTransport(cassettePath string, mode Mode, opts ...TransportOption) (*Transport, error) {
+	options := transportOptionSet{
+		redactor: newRedactor(),
+	}
+	for _, opt := range opts {
+		opt(&options)
+	}
+	transport := &Transport{
+		cassettePath: cassettePath,
+		mode:         mode,
+		upstream:     options.upstream,
+		redactor:     options.redactor,
+	}
+	switch mode {
+	case ModeRecord:
+		transport.cassette = &Cassette{
+			Version:      CassetteVersion,
+			Interactions: []Interaction{},
+		}
+	case ModeReplay:
+		cassette, err := LoadCassette(cassettePath)
+		if err != nil {
+			return nil, err
+		}
+		transport.cassette = cassette
+		transport.replayed = make([]bool, len(cassette.Interactions))
+	default:
+		return nil, fmt.Errorf("invalid mode %v", mode)
+	}
+	return transport, nil
+}
+
+// Mode returns whether the transport records or replays exchanges
+func (transport *Transport) Mode() Mode {
+	return transport.mode
+}
+
+// Save writes the interactions recorded so far to the cassette. It
+// does nothing in replay mode.
+func (transport *Transport) Save() error {
+	if transport.mode != ModeRecord {
+		return nil
+	}
+	transport.mutex.Lock()
+	defer transport.mutex.Unlock()
+	return transport.cassette.Save(transport.cassettePath)
+}
+
+// Unreplayed returns the interactions of the cassette which were not
+// replayed yet, e.g. to check at the end of a test that all requests
+// expected were sent. It returns nil in record mode.
+func (transport *Transport) Unreplayed() []Interaction {
+	transport.mutex.Lock()
+	defer transport.mutex.Unlock()
+	var interactions []Interaction
+	for i, replayed := range transport.replayed {
+		if !replayed {
+			interactions = append(interactions, transport.cassette.Interactions[i])
+		}
+	}
+	return interactions
+}
+
+func (transport *Transport) RoundTrip(request *http.Request) (*http.Response, error) {
+	var requestBody []byte
+	if request.Body != nil {
+		var err error
+		requestBody, err = io.ReadAll(request.Body)
+		request.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+	}
+	query, err := transport.redactor.normalizeQuery(request.URL.RawQuery)
+	if err != nil {
+		return nil, fmt.Errorf("invalid query string %q: %w", request.URL.RawQuery, err)
+	}
+	recordedRequest := RecordedRequest{
+		Method:   request.Method,
+		Resource: request.URL.EscapedPath(),
+		Query:    query,
+		Body:     transport.redactor.normalizeBody(requestBody),
+	}
+	if transport.mode == ModeRecord {
+		return transport.record(request, requestBody, recordedRequest)
+	}
+	return transport.replay(request, recordedRequest)
+}
+
+func (transport *Transport) record(request *http.Request, requestBody []byte,
+	recordedRequest RecordedRequest) (*http.Response, error) {
+	upstreamRequest := request.Clone(request.Context())
+	if request.Body != nil {
+		upstreamRequest.Body = io.NopCloser(bytes.NewReader(requestBody))
+		upstreamRequest.GetBody = func() (io.ReadCloser, error) {
+			return io.NopCloser(bytes.NewReader(requestBody)), nil
+		}
+	}
+	upstream := transport.upstream
+	if upstream == nil {
+		upstream = http.DefaultTransport
+	}
+	response, err := upstream.RoundTrip(upstreamRequest)
+	if err != nil {
+		return nil, err
+	}
+	responseBody, err := io.ReadAll(response.Body)
+	response.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	response.Body = io.NopCloser(bytes.NewReader(responseBody))
+
+	transport.mutex.Lock()
+	defer transport.mutex.Unlock()
+	transport.cassette.Interactions = append(transport.cassette.Interactions, Interaction{
+		Request: recordedRequest,
+		Response: RecordedResponse{
+			Status:     response.Status,
+			StatusCode: response.StatusCode,
+			Header:     transport.redactor.redactHeader(response.Header),
+			Body:       transport.redactor.redactBody(responseBody),
+		},
+	})
+	return response, nil
+}
+
+func (transport *Transport) replay(request *http.Request,
+	recordedRequest RecordedRequest) (*http.Response, error) {
+	transport.mutex.Lock()
+	defer transport.mutex.Unlock()
+
+	unmatchedErr := &UnmatchedRequestError{
+		CassettePath: transport.cassettePath,
+		Request:      recordedRequest,
+	}
+	for i, interaction := range transport.cassette.Interactions {
+		if !interaction.Request.matches(recordedRequest) {
+			if interaction.Request.Method == recordedRequest.Method &&
+				interaction.Request.Resource == recordedRequest.Resource {
+				unmatchedErr.Similar = append(unmatchedErr.Similar, interaction.Request)
+			}
+			continue
+		}
+		if transport.replayed[i] {
+			unmatchedErr.AlreadyReplayed += 1
+			continue
+		}
+		transport.replayed[i] = true
+		recordedResponse := interaction.Response
+		header := recordedResponse.Header.Clone()
+		if header == nil {
+			header = http.Header{}
+		}
+		return &http.Response{
+			Status:        recordedResponse.Status,
+			StatusCode:    recordedResponse.StatusCode,
+			Proto:         "HTTP/1.1",
+			ProtoMajor:    1,
+			ProtoMinor:    1,
+			Header:        header,
+			Body:          io.NopCloser(bytes.NewReader(recordedResponse.Body)),
+			ContentLength: int64(len(recordedResponse.Body)),
+			Request:       request,
+		}, nil
+	}
+	return nil, unmatchedErr
+}
+
+func (r RecordedRequest) matches(other RecordedRequest) bool {
+	return r.Method == other.Method &&
+		r.Resource == other.Resource &&
+		r.Query == other.Query &&
+		bytes.Equal(r.Body, other.Body)
+}
+
+func (r RecordedRequest) String() string {
+	var builder strings.Builder
+	builder.WriteString(r.Method)
+	builder.WriteString(" ")
+	builder.WriteString(r.Resource)
+	if r.Query != "" {
+		builder.WriteString("?")
+		builder.WriteString(r.Query)
+	}
+	if len(r.Body) > 0 {
+		const maxBodyLength = 200
+		body := string(r.Body)
+		if len(body) > maxBodyLength {
+			body = fmt.Sprintf("%s... <truncated %d bytes>",
+				body[:maxBodyLength], len(body)-maxBodyLength)
+		}
+		fmt.Fprintf(&builder, " with body %q", body)
+	}
+	return builder.String()
+}
+
+// UnmatchedRequestError is returned in replay mode for requests which
+// match no interaction of the cassette left to replay. The client
+// returns it wrapped in a bucketclient.BucketClientError.
+type UnmatchedRequestError struct {
+	CassettePath string
+	// Request is the unmatched request, after normalization
+	Request RecordedRequest
+	// AlreadyReplayed is the number of interactions matching the
+	// request which were all replayed already
+	AlreadyReplayed int
+	// Similar lists the recorded requests with the same method and
+	// resource, which differ by their query or body
+	Similar []RecordedRequest
+}
+
+func (e *UnmatchedRequestError) Error() string {
+	var builder strings.Builder
+	fmt.Fprintf(&builder, "no interaction of cassette %q matches request %s",
+		e.CassettePath, e.Request)
+	if e.AlreadyReplayed > 0 {
+		fmt.Fprintf(&builder, " (%d matching interactions were already replayed)",
+			e.AlreadyReplayed)
+	}
+	if len(e.Similar) > 0 {
+		similar := make([]string, 0, len(e.Similar))
+		for _, request := range e.Similar {
+			similar = append(similar, request.String())
+		}
+		similar = slices.Compact(similar)
+		fmt.Fprintf(&builder, "; recorded requests to the same resource: %s",
+			strings.Join(similar, ", "))
+	}
+	return builder.String()
+}
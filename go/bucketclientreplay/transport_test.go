@@ -0,0 +1,158 @@
+package bucketclientreplay_test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/scality/bucketclient/go"
+	"github.com/scality/bucketclient/go/bucketclientreplay"
+	"github.com/scality/bucketclient/go/bucketclienttest"
+)
+
+// unreachableEndpoint makes any request sent for real fail, to check
+// that replayed requests are not sent
+const unreachableEndpoint = "http://127.0.0.1:1"
+
+var _ = Describe("Transport", func() {
+	var cassettePath string
+
+	BeforeEach(func() {
+		cassettePath = filepath.Join(GinkgoT().TempDir(), "cassette.json")
+	})
+
+	// record runs f with a client recording its requests to a fake
+	// bucketd into the cassette
+	record := func(f func(client *bucketclient.BucketClient),
+		opts ...bucketclientreplay.TransportOption) {
+		server := bucketclienttest.NewServer()
+		defer server.Close()
+		transport, err := bucketclientreplay.NewTransport(cassettePath,
+			bucketclientreplay.ModeRecord, opts...)
+		Expect(err).ToNot(HaveOccurred())
+		f(server.NewClient(bucketclient.BucketClientTransportOption(transport)))
+		Expect(transport.Save()).To(Succeed())
+	}
+
+	replayClient := func(opts ...bucketclientreplay.TransportOption) (
+		*bucketclient.BucketClient, *bucketclientreplay.Transport) {
+		transport, err := bucketclientreplay.NewTransport(cassettePath,
+			bucketclientreplay.ModeReplay, opts...)
+		Expect(err).ToNot(HaveOccurred())
+		return bucketclient.New(unreachableEndpoint,
+			bucketclient.BucketClientTransportOption(transport)), transport
+	}
+
+	expectUnmatched := func(err error) *bucketclientreplay.UnmatchedRequestError {
+		var unmatchedErr *bucketclientreplay.UnmatchedRequestError
+		ExpectWithOffset(1, errors.As(err, &unmatchedErr)).To(BeTrue(), "unexpected error %v", err)
+		return unmatchedErr
+	}
+
+	It("replays recorded responses and errors", func(ctx SpecContext) {
+		record(func(client *bucketclient.BucketClient) {
+			Expect(client.CreateBucket(ctx, "somebucket", []byte(`{"uid":"1"}`))).To(Succeed())
+			Expect(client.PostBatch(ctx, "somebucket", []bucketclient.PostBatchEntry{
+				{Key: "a", Value: "1"},
+			})).To(Succeed())
+			Expect(client.GetBucketAttributes(ctx, "somebucket")).To(Equal([]byte(`{"uid":"1"}`)))
+			_, err := client.GetBucketAttributes(ctx, "nosuchbucket")
+			Expect(err).To(HaveOccurred())
+		})
+
+		client, transport := replayClient()
+		Expect(client.CreateBucket(ctx, "somebucket", []byte(`{"uid":"1"}`))).To(Succeed())
+		Expect(client.PostBatch(ctx, "somebucket", []bucketclient.PostBatchEntry{
+			{Key: "a", Value: "1"},
+		})).To(Succeed())
+		Expect(client.GetBucketAttributes(ctx, "somebucket")).To(Equal([]byte(`{"uid":"1"}`)))
+		_, err := client.GetBucketAttributes(ctx, "nosuchbucket")
+		var bcErr *bucketclient.BucketClientError
+		Expect(errors.As(err, &bcErr)).To(BeTrue())
+		Expect(bcErr.StatusCode).To(Equal(404))
+		Expect(bcErr.ErrorType).To(Equal("NoSuchBucket"))
+		Expect(transport.Unreplayed()).To(BeEmpty())
+	})
+
+	It("matches requests regardless of query parameter order and JSON formatting",
+		func(ctx SpecContext) {
+			record(func(client *bucketclient.BucketClient) {
+				Expect(client.CreateBucket(ctx, "somebucket", []byte(`{}`))).To(Succeed())
+				_, err := client.Request(ctx, "PutObject", "POST",
+					"/default/bucket/somebucket/obj?versioning=true&isNull=false",
+					bucketclient.RequestBodyOption([]byte(`{"size": 42}`)))
+				Expect(err).ToNot(HaveOccurred())
+			})
+			client, transport := replayClient()
+			_, err := client.Request(ctx, "PutObject", "POST",
+				"/default/bucket/somebucket/obj?isNull=false&versioning=true",
+				bucketclient.RequestBodyOption([]byte(`{"size":42}`)))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(transport.Unreplayed()).To(HaveLen(1))
+		})
+
+	It("reports unmatched requests", func(ctx SpecContext) {
+		record(func(client *bucketclient.BucketClient) {
+			Expect(client.CreateBucket(ctx, "somebucket", []byte(`{}`))).To(Succeed())
+			Expect(client.ListBasic(ctx, "somebucket",
+				bucketclient.ListBasicMaxKeysOption(10))).To(HaveValue(BeEmpty()))
+		})
+		client, _ := replayClient()
+
+		_, err := client.ListBasic(ctx, "somebucket", bucketclient.ListBasicMaxKeysOption(20))
+		unmatchedErr := expectUnmatched(err)
+		Expect(unmatchedErr.Request.Query).To(Equal("listingType=Basic&maxKeys=20"))
+		Expect(unmatchedErr.Similar).To(HaveLen(1))
+		Expect(err.Error()).To(ContainSubstring(
+			"matches request GET /default/bucket/somebucket?listingType=Basic&maxKeys=20; " +
+				"recorded requests to the same resource: GET /default/bucket/somebucket?listingType=Basic&maxKeys=10"))
+
+		Expect(client.ListBasic(ctx, "somebucket",
+			bucketclient.ListBasicMaxKeysOption(10))).To(HaveValue(BeEmpty()))
+		_, err = client.ListBasic(ctx, "somebucket", bucketclient.ListBasicMaxKeysOption(10))
+		Expect(expectUnmatched(err).AlreadyReplayed).To(Equal(1))
+		Expect(err.Error()).To(ContainSubstring("1 matching interactions were already replayed"))
+	})
+
+	It("redacts sensitive values", func(ctx SpecContext) {
+		redactOptions := []bucketclientreplay.TransportOption{
+			bucketclientreplay.TransportRedactQueryParamsOption("token"),
+			bucketclientreplay.TransportRedactJSONFieldsOption("owner-display-name"),
+		}
+		record(func(client *bucketclient.BucketClient) {
+			Expect(client.CreateBucket(ctx, "somebucket", []byte(`{}`))).To(Succeed())
+			Expect(client.PostBatch(ctx, "somebucket", []bucketclient.PostBatchEntry{
+				{Key: "obj", Value: `{"owner-display-name":"alice","content-length":42}`},
+			})).To(Succeed())
+			Expect(client.ListBasic(ctx, "somebucket")).To(HaveValue(HaveLen(1)))
+			_, err := client.Request(ctx, "GetBucketAttributes", "GET",
+				"/default/attributes/somebucket?token=secret")
+			Expect(err).ToNot(HaveOccurred())
+		}, redactOptions...)
+
+		cassette, err := os.ReadFile(cassettePath)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(string(cassette)).ToNot(ContainSubstring("alice"))
+		Expect(string(cassette)).ToNot(ContainSubstring("secret"))
+
+		client, transport := replayClient(redactOptions...)
+		Expect(client.PostBatch(ctx, "somebucket", []bucketclient.PostBatchEntry{
+			{Key: "obj", Value: `{"owner-display-name":"bob","content-length":42}`},
+		})).To(Succeed())
+		Expect(client.ListBasic(ctx, "somebucket")).To(Equal(&bucketclient.ListBasicResponse{
+			{Key: "obj", Value: `{"content-length":42,"owner-display-name":"[REDACTED]"}`},
+		}))
+		_, err = client.Request(ctx, "GetBucketAttributes", "GET",
+			"/default/attributes/somebucket?token=othersecret")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(transport.Unreplayed()).To(HaveLen(1))
+	})
+
+	It("fails to replay a missing cassette", func() {
+		_, err := bucketclientreplay.NewTransport(cassettePath, bucketclientreplay.ModeReplay)
+		Expect(errors.Is(err, os.ErrNotExist)).To(BeTrue())
+	})
+})
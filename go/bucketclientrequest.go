@@ -5,14 +5,17 @@ import (
 	"context"
 	"fmt"
 	"io"
+	"log/slog"
 	"net/http"
 	"strings"
+	"time"
 )
 
 type requestOptionSet struct {
 	requestBody            []byte
 	requestBodyContentType string
 	idempotent             bool
+	header                 http.Header
 }
 
 type RequestOption func(*requestOptionSet)
@@ -33,6 +36,16 @@ func RequestIdempotent(ros *requestOptionSet) {
 	ros.idempotent = true
 }
 
+// RequestHeaderOption adds a header to the request
+func RequestHeaderOption(key string, value string) RequestOption {
+	return func(ros *requestOptionSet) {
+		if ros.header == nil {
+			ros.header = http.Header{}
+		}
+		ros.header.Add(key, value)
+	}
+}
+
 func parseRequestOptions(opts ...RequestOption) (requestOptionSet, error) {
 	parsedOpts := requestOptionSet{
 		requestBody:            nil,
@@ -47,32 +60,198 @@ func parseRequestOptions(opts ...RequestOption) (requestOptionSet, error) {
 
 func (client *BucketClient) Request(ctx context.Context,
 	apiMethod string, httpMethod string, resource string, opts ...RequestOption) ([]byte, error) {
-	var response *http.Response
-	var err error
-
 	options, err := parseRequestOptions(opts...)
-	if err == nil {
-		url := fmt.Sprintf("%s%s", client.Endpoint, resource)
-
-		var requestBodyReader io.Reader = nil
-		if options.requestBody != nil {
-			requestBodyReader = bytes.NewReader(options.requestBody)
+	if err != nil {
+		return nil, &BucketClientError{
+			apiMethod, httpMethod, client.Endpoint, resource, 0, "", err,
 		}
-		var request *http.Request
-		request, err = http.NewRequestWithContext(ctx, httpMethod, url, requestBodyReader)
-		if err == nil {
-			if options.requestBodyContentType != "" {
-				request.Header.Add("Content-Type", string(options.requestBodyContentType))
+	}
+	var responseBody []byte
+	if len(client.interceptors) == 0 {
+		responseBody, _, err = client.invoke(ctx, apiMethod, httpMethod, resource, options)
+	} else {
+		info := newRequestInfo(apiMethod, httpMethod, resource, options)
+		responseBody, err = client.interceptedInvoker(0)(ctx, info)
+	}
+	if err != nil {
+		if _, ok := err.(*BucketClientError); !ok {
+			err = &BucketClientError{
+				apiMethod, httpMethod, client.Endpoint, resource, 0, "", err,
 			}
-			if options.idempotent {
-				request.Header["Idempotency-Key"] = []string{}
+		}
+		return nil, err
+	}
+	return responseBody, nil
+}
+
+// invoke sends the request, sharing it with concurrent identical
+// requests if enabled, and returns whether it joined a request sent
+// for another caller
+func (client *BucketClient) invoke(ctx context.Context,
+	apiMethod string, httpMethod string, resource string, options requestOptionSet) ([]byte, bool, error) {
+	if client.coalescer != nil && isReadRequest(httpMethod, options) {
+		key := newCoalescingKey(ctx, apiMethod, httpMethod, resource, options)
+		return client.coalescer.do(ctx, key,
+			func(ctx context.Context) ([]byte, error) {
+				return client.doRequest(ctx, apiMethod, httpMethod, resource, options)
+			})
+	}
+	responseBody, err := client.doRequest(ctx, apiMethod, httpMethod, resource, options)
+	return responseBody, false, err
+}
+
+type requestAttemptKey struct{}
+
+// contextWithRequestAttempt returns a context carrying the number of
+// the attempt of the request, starting at 1
+func contextWithRequestAttempt(ctx context.Context, attempt int) context.Context {
+	return context.WithValue(ctx, requestAttemptKey{}, attempt)
+}
+
+// RequestAttemptFromContext returns the number of the attempt of the
+// request the HTTP request made with ctx is sent for, starting at 1,
+// e.g. 2 for a hedged request sent to a secondary endpoint.
+func RequestAttemptFromContext(ctx context.Context) int {
+	if attempt, ok := ctx.Value(requestAttemptKey{}).(int); ok {
+		return attempt
+	}
+	return 1
+}
+
+type apiMethodKey struct{}
+
+// ApiMethodFromContext returns the API method of the request the HTTP
+// request made with ctx is sent for, e.g. "GetBucketAttributes", or an
+// empty string if there is none. It lets a transport set with
+// BucketClientTransportOption tell requests apart by their API method.
+func ApiMethodFromContext(ctx context.Context) string {
+	apiMethod, _ := ctx.Value(apiMethodKey{}).(string)
+	return apiMethod
+}
+
+// isReadRequest returns whether the request only reads from bucketd,
+// so that it can be shared or sent multiple times
+func isReadRequest(httpMethod string, options requestOptionSet) bool {
+	return httpMethod == "GET" && options.requestBody == nil
+}
+
+// doRequest sends the request to bucketd once allowed by request
+// limits, hedged across endpoints if enabled
+func (client *BucketClient) doRequest(ctx context.Context,
+	apiMethod string, httpMethod string, resource string, options requestOptionSet) ([]byte, error) {
+	if client.limits != nil {
+		release, err := client.limits.acquire(ctx, apiMethod)
+		if err != nil {
+			return nil, &BucketClientError{
+				apiMethod, httpMethod, client.Endpoint, resource, 0, "", err,
 			}
-			response, err = http.DefaultClient.Do(request)
 		}
+		defer release()
 	}
-	if err != nil {
+	if client.hedger != nil && isReadRequest(httpMethod, options) {
+		return client.hedger.do(ctx, client.Endpoint,
+			func(ctx context.Context, endpoint string) ([]byte, error) {
+				return client.sendRequest(ctx, endpoint, apiMethod, httpMethod, resource, options)
+			})
+	}
+	return client.sendRequest(ctx, client.Endpoint, apiMethod, httpMethod, resource, options)
+}
+
+// sendRequest sends a single HTTP request to the given bucketd
+// endpoint, unless its circuit breaker is open
+func (client *BucketClient) sendRequest(ctx context.Context, endpoint string,
+	apiMethod string, httpMethod string, resource string, options requestOptionSet) ([]byte, error) {
+	if client.circuitBreakers == nil {
+		return client.sendAttempt(ctx, endpoint, apiMethod, httpMethod, resource, options)
+	}
+	breaker := client.circuitBreakers.get(endpoint)
+	allowed, trial := breaker.allow()
+	if !allowed {
 		return nil, &BucketClientError{
-			apiMethod, httpMethod, client.Endpoint, resource, 0, "", err,
+			apiMethod, httpMethod, endpoint, resource, 0, "",
+			fmt.Errorf("%w for endpoint %s", ErrCircuitBreakerOpen, endpoint),
+		}
+	}
+	responseBody, err := client.sendAttempt(ctx, endpoint, apiMethod, httpMethod, resource, options)
+	breaker.record(trial, requestOutcomeOf(ctx, err))
+	return responseBody, err
+}
+
+// sendAttempt sends one attempt of the request to the given endpoint,
+// logging and measuring it if enabled
+func (client *BucketClient) sendAttempt(ctx context.Context, endpoint string,
+	apiMethod string, httpMethod string, resource string, options requestOptionSet) ([]byte, error) {
+	if client.logger == nil && client.metrics == nil {
+		responseBody, _, err := client.sendHTTPRequest(ctx, endpoint,
+			apiMethod, httpMethod, resource, options)
+		return responseBody, err
+	}
+	var logAttrs []slog.Attr
+	if client.logger != nil {
+		logAttrs = client.logger.logStart(ctx, endpoint, apiMethod, httpMethod, resource, options)
+	}
+	if client.metrics != nil {
+		if RequestAttemptFromContext(ctx) > 1 {
+			client.metrics.RequestRetried(apiMethod, endpoint)
+		}
+		client.metrics.RequestStarted(apiMethod, endpoint)
+	}
+	start := time.Now()
+	responseBody, statusCode, err := client.sendHTTPRequest(ctx, endpoint,
+		apiMethod, httpMethod, resource, options)
+	duration := time.Since(start)
+	if client.logger != nil {
+		client.logger.logEnd(ctx, logAttrs, statusCode, duration, responseBody, err)
+	}
+	if client.metrics != nil {
+		client.metrics.RequestEnded(RequestMeasurement{
+			ApiMethod:        apiMethod,
+			Endpoint:         endpoint,
+			StatusCode:       statusCode,
+			Duration:         duration,
+			ResponseBodySize: len(responseBody),
+			Err:              err,
+		})
+	}
+	return responseBody, err
+}
+
+// sendHTTPRequest sends the HTTP request and returns the response body
+// along with the HTTP status code, which is zero if no response was
+// received
+func (client *BucketClient) sendHTTPRequest(ctx context.Context, endpoint string,
+	apiMethod string, httpMethod string, resource string, options requestOptionSet) ([]byte, int, error) {
+	url := fmt.Sprintf("%s%s", endpoint, resource)
+
+	var requestBodyReader io.Reader = nil
+	if options.requestBody != nil {
+		requestBodyReader = bytes.NewReader(options.requestBody)
+	}
+	var response *http.Response
+	request, err := http.NewRequestWithContext(context.WithValue(ctx, apiMethodKey{}, apiMethod),
+		httpMethod, url, requestBodyReader)
+	if err == nil {
+		if options.requestBodyContentType != "" {
+			request.Header.Add("Content-Type", string(options.requestBodyContentType))
+		}
+		for key, values := range options.header {
+			request.Header[key] = append(request.Header[key], values...)
+		}
+		if options.idempotent {
+			request.Header["Idempotency-Key"] = []string{}
+		}
+		if requestUIDs := RequestUIDsFromContext(ctx); requestUIDs != "" {
+			request.Header.Set(RequestUIDsHeader, requestUIDs)
+		}
+		httpClient := client.httpClient
+		if httpClient == nil {
+			httpClient = http.DefaultClient
+		}
+		response, err = httpClient.Do(request)
+	}
+	if err != nil {
+		return nil, 0, &BucketClientError{
+			apiMethod, httpMethod, endpoint, resource, 0, "", err,
 		}
 	}
 	if response.Body != nil {
@@ -85,17 +264,17 @@ func (client *BucketClient) Request(ctx context.Context,
 		if len(splitStatus) == 2 {
 			errorType = splitStatus[1]
 		}
-		return nil, &BucketClientError{
-			apiMethod, httpMethod, client.Endpoint, resource,
+		return nil, response.StatusCode, &BucketClientError{
+			apiMethod, httpMethod, endpoint, resource,
 			response.StatusCode, errorType, nil,
 		}
 	}
 	responseBody, err := io.ReadAll(response.Body)
 	if err != nil {
-		return nil, &BucketClientError{
-			apiMethod, httpMethod, client.Endpoint, resource, 0, "",
+		return nil, response.StatusCode, &BucketClientError{
+			apiMethod, httpMethod, endpoint, resource, 0, "",
 			fmt.Errorf("error reading response body: %w", err),
 		}
 	}
-	return responseBody, nil
+	return responseBody, response.StatusCode, nil
 }
@@ -134,3 +134,18 @@ var _ = Describe("BucketClient.Request()", func() {
 		})
 	})
 })
+
+var _ = Describe("RequestHeaderOption", func() {
+	It("adds headers to the request", func(ctx SpecContext) {
+		httpmock.RegisterResponder(
+			"GET", "/default/attributes/somebucket",
+			func(req *http.Request) (*http.Response, error) {
+				Expect(req.Header.Values("X-Custom")).To(Equal([]string{"a", "b"}))
+				return httpmock.NewStringResponse(200, `{}`), nil
+			},
+		)
+		Expect(client.Request(ctx, "GetBucketAttributes", "GET", "/default/attributes/somebucket",
+			bucketclient.RequestHeaderOption("X-Custom", "a"),
+			bucketclient.RequestHeaderOption("X-Custom", "b"))).To(Equal([]byte(`{}`)))
+	})
+})
@@ -0,0 +1,175 @@
+package bucketclienttest
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/scality/bucketclient/go"
+)
+
+// raftSession is a raft session of the fake bucketd, with a single
+// member which is always the leader
+type raftSession struct {
+	info bucketclient.SessionInfo
+	log  []bucketclient.SessionLogRecord
+}
+
+func newRaftSession(id int) *raftSession {
+	return &raftSession{
+		info: bucketclient.SessionInfo{
+			ID: id,
+			RaftMembers: []bucketclient.MemberInfo{{
+				ID:          id * 10,
+				Name:        fmt.Sprintf("md%d-cluster1", id),
+				DisplayName: fmt.Sprintf("127.0.0.1 (md%d-cluster1)", id),
+				Host:        "127.0.0.1",
+				Port:        4200 + id,
+				AdminPort:   4250 + id,
+				MDClusterId: "1",
+			}},
+			ConnectedToLeader: true,
+		},
+	}
+}
+
+func (s *Server) getSession(id int) *raftSession {
+	if id < 1 || id > len(s.sessions) {
+		return nil
+	}
+	return s.sessions[id-1]
+}
+
+// appendLog appends a write to a bucket to the log of its raft session
+func (s *Server) appendLog(bucket *fakeBucket, bucketName string,
+	method bucketclient.DBMethodType, entries ...bucketclient.SessionLogEntry) {
+	session := s.getSession(bucket.sessionId)
+	if entries == nil {
+		entries = []bucketclient.SessionLogEntry{}
+	}
+	session.log = append(session.log, bucketclient.SessionLogRecord{
+		Bucket:    bucketName,
+		DBMethod:  method,
+		Timestamp: time.Now().UTC().Format("2006-01-02T15:04:05.000Z07:00"),
+		Entries:   entries,
+	})
+}
+
+func (s *Server) routeAdmin(method string, segments []string, query url.Values) ([]byte, error) {
+	switch {
+	case len(segments) == 1 && segments[0] == "healthcheck":
+		return nil, nil
+	case len(segments) == 3 && segments[0] == "buckets" && segments[2] == "refreshCache":
+		if method == "GET" {
+			_, err := s.getBucket(segments[1])
+			return nil, err
+		}
+	case len(segments) == 3 && segments[0] == "buckets" && segments[2] == "accessMode":
+		switch method {
+		case "GET":
+			bucket, err := s.getBucket(segments[1])
+			if err != nil {
+				return nil, err
+			}
+			return []byte(bucket.accessMode), nil
+		case "PUT":
+			return nil, s.setBucketAccessMode(segments[1], query.Get("mode"))
+		}
+	case len(segments) == 3 && segments[0] == "buckets" && segments[2] == "id":
+		if method == "GET" {
+			bucket, err := s.getBucket(segments[1])
+			if err != nil {
+				return nil, err
+			}
+			return []byte(strconv.Itoa(bucket.sessionId)), nil
+		}
+	case len(segments) == 1 && segments[0] == "raft_sessions":
+		if method == "GET" {
+			sessionsInfo := []bucketclient.SessionInfo{}
+			for _, session := range s.sessions {
+				sessionsInfo = append(sessionsInfo, session.info)
+			}
+			return json.Marshal(sessionsInfo)
+		}
+	case len(segments) == 3 && segments[0] == "raft_sessions":
+		id, err := strconv.Atoi(segments[1])
+		if err != nil {
+			return nil, errBadRequest
+		}
+		session := s.getSession(id)
+		if session == nil {
+			return nil, errRouteNotFound
+		}
+		if method != "GET" {
+			return nil, errMethodNotAllowed
+		}
+		switch segments[2] {
+		case "leader":
+			return json.Marshal(session.info.RaftMembers[0])
+		case "log":
+			return session.getLog(query)
+		case "bucket":
+			return json.Marshal(s.sessionBuckets(id))
+		}
+		return nil, errRouteNotFound
+	default:
+		return nil, errRouteNotFound
+	}
+	return nil, errMethodNotAllowed
+}
+
+func (s *Server) setBucketAccessMode(bucketName string, mode string) error {
+	bucket, err := s.getBucket(bucketName)
+	if err != nil {
+		return err
+	}
+	switch accessMode := bucketclient.BucketAccessMode(mode); accessMode {
+	case bucketclient.BucketAccessModeReadWrite, bucketclient.BucketAccessModeReadOnly:
+		bucket.accessMode = accessMode
+		return nil
+	default:
+		return errBadRequest
+	}
+}
+
+// sessionBuckets returns the sorted names of buckets hosted on a raft
+// session
+func (s *Server) sessionBuckets(id int) []string {
+	bucketNames := []string{}
+	for bucketName, bucket := range s.buckets {
+		if bucket.sessionId == id {
+			bucketNames = append(bucketNames, bucketName)
+		}
+	}
+	slices.Sort(bucketNames)
+	return bucketNames
+}
+
+// getLog returns records of the raft log, numbered from 1, starting at
+// the "begin" sequence number and up to "limit" records
+func (session *raftSession) getLog(query url.Values) ([]byte, error) {
+	begin, err := strconv.ParseInt(query.Get("begin"), 10, 64)
+	if err != nil || begin < 1 {
+		return nil, errBadRequest
+	}
+	limit, err := strconv.ParseInt(query.Get("limit"), 10, 64)
+	if err != nil || limit < 0 {
+		return nil, errBadRequest
+	}
+	cseq := int64(len(session.log))
+	if begin > cseq+1 {
+		return nil, errRangeNotSatisfied
+	}
+	end := min(begin-1+limit, cseq)
+	return json.Marshal(bucketclient.AdminGetSessionLogResponse{
+		Info: bucketclient.SessionLogInfo{
+			Start: begin,
+			CSeq:  cseq,
+			Prune: 1,
+		},
+		Log: session.log[begin-1 : end],
+	})
+}
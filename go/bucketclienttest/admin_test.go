@@ -0,0 +1,85 @@
+package bucketclienttest_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/scality/bucketclient/go"
+	"github.com/scality/bucketclient/go/bucketclienttest"
+)
+
+var _ = Describe("Server admin routes", func() {
+	var (
+		server *bucketclienttest.Server
+		client *bucketclient.BucketClient
+	)
+
+	BeforeEach(func() {
+		server = bucketclienttest.NewServer(bucketclienttest.ServerRaftSessionsOption(2))
+		DeferCleanup(server.Close)
+		client = server.NewClient()
+	})
+
+	It("returns raft sessions and their leader", func(ctx SpecContext) {
+		sessions, err := client.AdminGetAllSessionsInfo(ctx)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(sessions).To(HaveLen(2))
+		Expect(sessions[1].ID).To(Equal(2))
+		leader, err := client.AdminGetSessionLeader(ctx, 2)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(*leader).To(Equal(sessions[1].RaftMembers[0]))
+		_, err = client.AdminGetSessionLeader(ctx, 3)
+		expectBucketdError(err, 404, "RouteNotFound")
+	})
+
+	It("returns the raft session of buckets", func(ctx SpecContext) {
+		Expect(client.CreateBucket(ctx, "bucket1", []byte(`{}`))).To(Succeed())
+		Expect(client.CreateBucket(ctx, "bucket2", []byte(`{}`),
+			bucketclient.CreateBucketSessionIdOption(2))).To(Succeed())
+		Expect(client.AdminGetBucketSessionID(ctx, "bucket1")).To(Equal(1))
+		Expect(client.AdminGetBucketSessionID(ctx, "bucket2")).To(Equal(2))
+		Expect(client.Request(ctx, "GetRaftBuckets", "GET",
+			"/_/raft_sessions/2/bucket")).To(Equal([]byte(`["bucket2"]`)))
+		Expect(client.AdminBucketRefreshCache(ctx, "bucket1")).To(Succeed())
+		expectBucketdError(client.AdminBucketRefreshCache(ctx, "nosuchbucket"), 404, "NoSuchBucket")
+	})
+
+	It("appends writes to the raft log of the session", func(ctx SpecContext) {
+		Expect(client.CreateBucket(ctx, "somebucket", []byte(`{}`),
+			bucketclient.CreateBucketSessionIdOption(2))).To(Succeed())
+		Expect(client.PostBatch(ctx, "somebucket", []bucketclient.PostBatchEntry{
+			{Key: "a", Value: "1"},
+			{Key: "b", Type: "del"},
+		})).To(Succeed())
+		Expect(client.PutBucketAttributes(ctx, "somebucket", []byte(`{"uid":"1"}`))).To(Succeed())
+		Expect(client.DeleteBucket(ctx, "somebucket")).To(Succeed())
+
+		response, err := client.AdminGetSessionLog(ctx, 2, 2, 2, false)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(response.Info).To(Equal(bucketclient.SessionLogInfo{Start: 2, CSeq: 4, Prune: 1}))
+		Expect(response.Log).To(HaveLen(2))
+		Expect(response.Log[0].Bucket).To(Equal("somebucket"))
+		Expect(response.Log[0].DBMethod).To(Equal(bucketclient.DBMethodBatch))
+		Expect(response.Log[0].Timestamp).ToNot(BeEmpty())
+		Expect(response.Log[0].Entries).To(Equal([]bucketclient.SessionLogEntry{
+			{Key: "a", Value: "1"},
+			{Key: "b", Type: "del"},
+		}))
+		Expect(response.Log[1].DBMethod).To(Equal(bucketclient.DBMethodPutAttributes))
+		Expect(response.Log[1].Entries).To(Equal([]bucketclient.SessionLogEntry{
+			{Value: `{"uid":"1"}`},
+		}))
+
+		response, err = client.AdminGetSessionLog(ctx, 2, 4, 10, true)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(response.Log).To(HaveLen(1))
+		Expect(response.Log[0].DBMethod).To(Equal(bucketclient.DBMethodDelete))
+
+		response, err = client.AdminGetSessionLog(ctx, 1, 1, 10, false)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(response.Log).To(BeEmpty())
+
+		_, err = client.AdminGetSessionLog(ctx, 2, 10, 10, false)
+		expectBucketdError(err, 416, "RequestedRangeNotSatisfiable")
+	})
+})
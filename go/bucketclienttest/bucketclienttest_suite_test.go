@@ -0,0 +1,13 @@
+package bucketclienttest_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestBucketclienttest(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Bucketclienttest Suite")
+}
@@ -0,0 +1,264 @@
+package bucketclienttest
+
+import (
+	"encoding/json"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/scality/bucketclient/go"
+)
+
+// keyspace is a sorted map of keys to values
+type keyspace struct {
+	keys   []string
+	values map[string]string
+}
+
+func newKeyspace() *keyspace {
+	return &keyspace{values: map[string]string{}}
+}
+
+func (ks *keyspace) get(key string) (string, bool) {
+	value, found := ks.values[key]
+	return value, found
+}
+
+func (ks *keyspace) put(key string, value string) {
+	if _, found := ks.values[key]; !found {
+		index, _ := slices.BinarySearch(ks.keys, key)
+		ks.keys = slices.Insert(ks.keys, index, key)
+	}
+	ks.values[key] = value
+}
+
+func (ks *keyspace) delete(key string) bool {
+	if _, found := ks.values[key]; !found {
+		return false
+	}
+	index, _ := slices.BinarySearch(ks.keys, key)
+	ks.keys = slices.Delete(ks.keys, index, index+1)
+	delete(ks.values, key)
+	return true
+}
+
+// seek returns the index of the first key greater than or equal to
+// key, or strictly greater if exclusive is set
+func (ks *keyspace) seek(key string, exclusive bool) int {
+	index, found := slices.BinarySearch(ks.keys, key)
+	if found && exclusive {
+		index += 1
+	}
+	return index
+}
+
+func (s *Server) listBucket(bucketName string, query url.Values) ([]byte, error) {
+	bucket, err := s.getBucket(bucketName)
+	if err != nil {
+		return nil, err
+	}
+	params := listingParams(query)
+	switch params.get("listingType") {
+	case "Basic":
+		return listBasic(bucket.keys, params)
+	case "DelimiterVersions":
+		return listVersions(bucket, params)
+	default:
+		return nil, errNotImplemented
+	}
+}
+
+type listingParams url.Values
+
+func (params listingParams) get(name string) string {
+	if values := params[name]; len(values) > 0 {
+		return values[0]
+	}
+	return ""
+}
+
+func (params listingParams) has(name string) bool {
+	_, found := params[name]
+	return found
+}
+
+// maxKeys returns the maxKeys parameter, or defaultMaxKeys if absent
+func (params listingParams) maxKeys(defaultMaxKeys int) (int, error) {
+	if !params.has("maxKeys") {
+		return defaultMaxKeys, nil
+	}
+	maxKeys, err := strconv.Atoi(params.get("maxKeys"))
+	if err != nil || maxKeys < 0 {
+		return 0, errBadRequest
+	}
+	return min(maxKeys, 10000), nil
+}
+
+// listBasic lists keys within the gt/gte/lt/lte range, in order
+func listBasic(keys *keyspace, params listingParams) ([]byte, error) {
+	maxKeys, err := params.maxKeys(10000)
+	if err != nil {
+		return nil, err
+	}
+	start := 0
+	if params.has("gte") {
+		start = keys.seek(params.get("gte"), false)
+	}
+	if params.has("gt") {
+		start = max(start, keys.seek(params.get("gt"), true))
+	}
+	withKeys := params.get("keys") != "false"
+	withValues := params.get("values") != "false"
+	entries := []map[string]string{}
+	for _, key := range keys.keys[start:] {
+		if len(entries) == maxKeys ||
+			(params.has("lt") && key >= params.get("lt")) ||
+			(params.has("lte") && key > params.get("lte")) {
+			break
+		}
+		entry := map[string]string{}
+		if withKeys {
+			entry["key"] = key
+		}
+		if withValues {
+			entry["value"] = keys.values[key]
+		}
+		entries = append(entries, entry)
+	}
+	return json.Marshal(entries)
+}
+
+// listVersions lists versions of objects like the DelimiterVersions
+// listing of bucketd, with S3 ListObjectVersions semantics: versions
+// are listed by key, then newest first, and keys containing the
+// delimiter after the prefix are grouped into common prefixes
+func listVersions(bucket *fakeBucket, params listingParams) ([]byte, error) {
+	maxKeys, err := params.maxKeys(1000)
+	if err != nil {
+		return nil, err
+	}
+	var bucketInfo struct {
+		VFormat string `json:"vFormat"`
+	}
+	json.Unmarshal(bucket.attributes, &bucketInfo)
+	keyFormat, err := bucketclient.ParseBucketKeyFormat(bucketInfo.VFormat)
+	if err != nil {
+		return nil, errNotImplemented
+	}
+	prefix := params.get("prefix")
+	delimiter := params.get("delimiter")
+	keyMarker := params.get("keyMarker")
+	versionIdMarker := params.get("versionIdMarker")
+
+	response := bucketclient.ListObjectVersionsResponse{
+		Versions:       []bucketclient.ListObjectVersionsEntry{},
+		CommonPrefixes: []string{},
+	}
+	// add appends a version or common prefix to the response, and
+	// returns false once the response is full
+	add := func(objectKey string, versionId string, value string, commonPrefix string) bool {
+		if len(response.Versions)+len(response.CommonPrefixes) == maxKeys {
+			response.IsTruncated = true
+			return false
+		}
+		if commonPrefix != "" {
+			response.CommonPrefixes = append(response.CommonPrefixes, commonPrefix)
+			response.NextKeyMarker = commonPrefix
+			response.NextVersionIdMarker = ""
+		} else {
+			response.Versions = append(response.Versions, bucketclient.ListObjectVersionsEntry{
+				Key:       objectKey,
+				VersionId: versionId,
+				Value:     value,
+			})
+			response.NextKeyMarker = objectKey
+			response.NextVersionIdMarker = versionId
+		}
+		return true
+	}
+
+	lastCommonPrefix := ""
+	for _, version := range objectVersions(bucket.keys, keyFormat, prefix) {
+		if keyMarker != "" {
+			if versionIdMarker == "" {
+				if version.objectKey <= keyMarker {
+					continue
+				}
+			} else if bucketclient.CompareVersionsListingMarkers(
+				version.objectKey, version.versionId, keyMarker, versionIdMarker) <= 0 {
+				continue
+			}
+		}
+		if delimiter != "" {
+			if i := strings.Index(version.objectKey[len(prefix):], delimiter); i >= 0 {
+				commonPrefix := version.objectKey[:len(prefix)+i+len(delimiter)]
+				if commonPrefix == lastCommonPrefix ||
+					(keyMarker != "" && strings.HasPrefix(keyMarker, commonPrefix)) {
+					continue
+				}
+				if !add("", "", "", commonPrefix) {
+					break
+				}
+				lastCommonPrefix = commonPrefix
+				continue
+			}
+		}
+		if !add(version.objectKey, version.versionId, version.value, "") {
+			break
+		}
+	}
+	if !response.IsTruncated {
+		response.NextKeyMarker = ""
+		response.NextVersionIdMarker = ""
+	}
+	return json.Marshal(response)
+}
+
+type objectVersion struct {
+	objectKey string
+	versionId string
+	value     string
+}
+
+// objectVersions returns the versions of objects with the given
+// prefix, ordered by object key then version ID. Master keys of
+// objects without version keys, like those put in non-versioned
+// buckets, are returned as a version with the version ID found in
+// their value, or "null".
+func objectVersions(keys *keyspace, keyFormat bucketclient.BucketKeyFormat,
+	prefix string) []objectVersion {
+	var versions []objectVersion
+	hasVersions := map[string]bool{}
+	masters := map[string]string{}
+	for _, key := range keys.keys {
+		objectKey, versionId, err := keyFormat.ParseKey(key)
+		if err != nil || !strings.HasPrefix(objectKey, prefix) {
+			continue
+		}
+		if versionId == "" {
+			masters[objectKey] = keys.values[key]
+			continue
+		}
+		hasVersions[objectKey] = true
+		versions = append(versions, objectVersion{objectKey, versionId, keys.values[key]})
+	}
+	for objectKey, value := range masters {
+		if hasVersions[objectKey] {
+			continue
+		}
+		var md struct {
+			VersionId string `json:"versionId"`
+		}
+		json.Unmarshal([]byte(value), &md)
+		if md.VersionId == "" {
+			md.VersionId = "null"
+		}
+		versions = append(versions, objectVersion{objectKey, md.VersionId, value})
+	}
+	slices.SortFunc(versions, func(a, b objectVersion) int {
+		return bucketclient.CompareVersionsListingMarkers(
+			a.objectKey, a.versionId, b.objectKey, b.versionId)
+	})
+	return versions
+}
@@ -0,0 +1,168 @@
+package bucketclienttest_test
+
+import (
+	"encoding/json"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/scality/bucketclient/go"
+	"github.com/scality/bucketclient/go/bucketclienttest"
+)
+
+var _ = Describe("Server listings", func() {
+	var (
+		server *bucketclienttest.Server
+		client *bucketclient.BucketClient
+	)
+
+	BeforeEach(func() {
+		server = bucketclienttest.NewServer()
+		DeferCleanup(server.Close)
+		client = server.NewClient()
+	})
+
+	Describe("Basic", func() {
+		BeforeEach(func(ctx SpecContext) {
+			Expect(client.CreateBucket(ctx, "somebucket", []byte(`{}`))).To(Succeed())
+			Expect(client.PostBatch(ctx, "somebucket", []bucketclient.PostBatchEntry{
+				{Key: "d", Value: "4"},
+				{Key: "b", Value: "2"},
+				{Key: "a", Value: "1"},
+				{Key: "c", Value: "3"},
+			})).To(Succeed())
+		})
+
+		It("lists keys in order", func(ctx SpecContext) {
+			Expect(client.ListBasic(ctx, "somebucket")).To(Equal(&bucketclient.ListBasicResponse{
+				{Key: "a", Value: "1"},
+				{Key: "b", Value: "2"},
+				{Key: "c", Value: "3"},
+				{Key: "d", Value: "4"},
+			}))
+		})
+
+		It("lists a range of keys", func(ctx SpecContext) {
+			Expect(client.ListBasic(ctx, "somebucket",
+				bucketclient.ListBasicGTOption("a"),
+				bucketclient.ListBasicLTEOption("c"),
+			)).To(Equal(&bucketclient.ListBasicResponse{
+				{Key: "b", Value: "2"},
+				{Key: "c", Value: "3"},
+			}))
+			Expect(client.ListBasic(ctx, "somebucket",
+				bucketclient.ListBasicGTEOption("b"),
+				bucketclient.ListBasicLTOption("d"),
+				bucketclient.ListBasicMaxKeysOption(1),
+			)).To(Equal(&bucketclient.ListBasicResponse{
+				{Key: "b", Value: "2"},
+			}))
+		})
+
+		It("omits keys or values", func(ctx SpecContext) {
+			Expect(client.ListBasic(ctx, "somebucket",
+				bucketclient.ListBasicGTOption("c"),
+				bucketclient.ListBasicNoValuesOption(),
+			)).To(Equal(&bucketclient.ListBasicResponse{{Key: "d"}}))
+			Expect(client.ListBasic(ctx, "somebucket",
+				bucketclient.ListBasicGTOption("c"),
+				bucketclient.ListBasicNoKeysOption(),
+			)).To(Equal(&bucketclient.ListBasicResponse{{Value: "4"}}))
+		})
+	})
+
+	Describe("DelimiterVersions", func() {
+		BeforeEach(func(ctx SpecContext) {
+			Expect(client.CreateBucket(ctx, "somebucket", []byte(`{}`))).To(Succeed())
+			Expect(client.PostBatch(ctx, "somebucket", []bucketclient.PostBatchEntry{
+				// versioned object: master and two versions
+				{Key: "obj1", Value: `{"versionId":"v1"}`},
+				{Key: "obj1\x00v1", Value: `{"versionId":"v1"}`},
+				{Key: "obj1\x00v2", Value: `{"versionId":"v2"}`},
+				// non-versioned object
+				{Key: "obj2", Value: `{}`},
+				{Key: "dir/obj3", Value: `{}`},
+				{Key: "dir/obj4\x00v3", Value: `{"versionId":"v3"}`},
+			})).To(Succeed())
+		})
+
+		It("lists versions by key then version ID", func(ctx SpecContext) {
+			Expect(client.ListObjectVersions(ctx, "somebucket")).To(Equal(
+				&bucketclient.ListObjectVersionsResponse{
+					Versions: []bucketclient.ListObjectVersionsEntry{
+						{Key: "dir/obj3", VersionId: "null", Value: `{}`},
+						{Key: "dir/obj4", VersionId: "v3", Value: `{"versionId":"v3"}`},
+						{Key: "obj1", VersionId: "v1", Value: `{"versionId":"v1"}`},
+						{Key: "obj1", VersionId: "v2", Value: `{"versionId":"v2"}`},
+						{Key: "obj2", VersionId: "null", Value: `{}`},
+					},
+					CommonPrefixes: []string{},
+				}))
+		})
+
+		It("lists pages of versions with markers", func(ctx SpecContext) {
+			page, err := client.ListObjectVersions(ctx, "somebucket",
+				bucketclient.ListObjectVersionsMaxKeysOption(3))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(page.Versions).To(HaveLen(3))
+			Expect(page.IsTruncated).To(BeTrue())
+			Expect(page.NextKeyMarker).To(Equal("obj1"))
+			Expect(page.NextVersionIdMarker).To(Equal("v1"))
+
+			page, err = client.ListObjectVersions(ctx, "somebucket",
+				bucketclient.ListObjectVersionsMaxKeysOption(3),
+				bucketclient.ListObjectVersionsMarkerOption(page.NextKeyMarker, page.NextVersionIdMarker))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(page).To(Equal(&bucketclient.ListObjectVersionsResponse{
+				Versions: []bucketclient.ListObjectVersionsEntry{
+					{Key: "obj1", VersionId: "v2", Value: `{"versionId":"v2"}`},
+					{Key: "obj2", VersionId: "null", Value: `{}`},
+				},
+				CommonPrefixes: []string{},
+			}))
+		})
+
+		It("groups keys into common prefixes", func(ctx SpecContext) {
+			responseBody, err := client.Request(ctx, "ListObjectVersions", "GET",
+				"/default/bucket/somebucket?listingType=DelimiterVersions&delimiter=/&maxKeys=2")
+			Expect(err).ToNot(HaveOccurred())
+			var page bucketclient.ListObjectVersionsResponse
+			Expect(json.Unmarshal(responseBody, &page)).To(Succeed())
+			Expect(page.CommonPrefixes).To(Equal([]string{"dir/"}))
+			Expect(page.Versions).To(Equal([]bucketclient.ListObjectVersionsEntry{
+				{Key: "obj1", VersionId: "v1", Value: `{"versionId":"v1"}`},
+			}))
+			Expect(page.IsTruncated).To(BeTrue())
+
+			responseBody, err = client.Request(ctx, "ListObjectVersions", "GET",
+				"/default/bucket/somebucket?listingType=DelimiterVersions&prefix=dir/")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(json.Unmarshal(responseBody, &page)).To(Succeed())
+			Expect(page.CommonPrefixes).To(BeEmpty())
+			Expect(page.Versions).To(HaveLen(2))
+			Expect(page.IsTruncated).To(BeFalse())
+		})
+
+		It("lists versions of buckets with the v1 key format", func(ctx SpecContext) {
+			Expect(client.CreateBucket(ctx, "v1bucket", []byte(`{"vFormat":"v1"}`))).To(Succeed())
+			format := bucketclient.BucketKeyFormatV1
+			Expect(client.PostBatch(ctx, "v1bucket", []bucketclient.PostBatchEntry{
+				{Key: format.MasterKey("obj"), Value: `{"versionId":"v2"}`},
+				{Key: format.VersionKey("obj", "v1"), Value: `{"versionId":"v1"}`},
+				{Key: format.VersionKey("obj", "v2"), Value: `{"versionId":"v2"}`},
+			})).To(Succeed())
+			page, err := client.ListObjectVersions(ctx, "v1bucket")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(page.Versions).To(Equal([]bucketclient.ListObjectVersionsEntry{
+				{Key: "obj", VersionId: "v1", Value: `{"versionId":"v1"}`},
+				{Key: "obj", VersionId: "v2", Value: `{"versionId":"v2"}`},
+			}))
+		})
+	})
+
+	It("returns an error for unsupported listing types", func(ctx SpecContext) {
+		Expect(client.CreateBucket(ctx, "somebucket", []byte(`{}`))).To(Succeed())
+		_, err := client.ListLifecycleCurrent(ctx, "somebucket")
+		expectBucketdError(err, 501, "NotImplemented")
+	})
+})
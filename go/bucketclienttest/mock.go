@@ -0,0 +1,362 @@
+package bucketclienttest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"iter"
+	"time"
+
+	"github.com/scality/bucketclient/go"
+)
+
+// ErrNotMocked is returned by methods of MockBucketClient which have no
+// mock function set
+var ErrNotMocked = errors.New("method not mocked")
+
+func notMocked(method string) error {
+	return fmt.Errorf("%w: %s", ErrNotMocked, method)
+}
+
+// MockBucketClient implements bucketclient.BucketClientAPI by calling
+// the function set for each method, so that tests can mock only the
+// methods used by the code under test. Methods without a function set
+// return an error wrapping ErrNotMocked.
+type MockBucketClient struct {
+	RequestFunc func(ctx context.Context, apiMethod string, httpMethod string,
+		resource string, opts ...bucketclient.RequestOption) ([]byte, error)
+	CreateBucketFunc func(ctx context.Context, bucketName string, bucketAttributes []byte,
+		opts ...bucketclient.CreateBucketOption) error
+	DeleteBucketFunc func(ctx context.Context, bucketName string,
+		opts ...bucketclient.DeleteBucketOption) error
+	GetBucketAttributesFunc    func(ctx context.Context, bucketName string) ([]byte, error)
+	PutBucketAttributesFunc    func(ctx context.Context, bucketName string, bucketAttributes []byte) error
+	UpdateBucketAttributesFunc func(ctx context.Context, bucketName string,
+		mutate func(bucketInfo *bucketclient.BucketInfo) error,
+		opts ...bucketclient.UpdateBucketAttributesOption) error
+	PostBatchFunc func(ctx context.Context, bucketName string,
+		batch []bucketclient.PostBatchEntry) error
+	DeleteRangeFunc func(ctx context.Context, bucketName string,
+		opts ...bucketclient.DeleteRangeOption) (int, error)
+	PutUsersBucketEntryFunc func(ctx context.Context, canonicalID string, bucketName string,
+		creationDate time.Time) error
+	DeleteUsersBucketEntryFunc func(ctx context.Context, canonicalID string, bucketName string) error
+
+	ListBasicFunc func(ctx context.Context, bucketName string,
+		opts ...bucketclient.ListBasicOption) (*bucketclient.ListBasicResponse, error)
+	ListObjectVersionsFunc func(ctx context.Context, bucketName string,
+		opts ...bucketclient.ListObjectVersionsOption) (*bucketclient.ListObjectVersionsResponse, error)
+	ListMultipartUploadsFunc func(ctx context.Context, bucketName string,
+		opts ...bucketclient.ListMultipartUploadsOption) (*bucketclient.ListMultipartUploadsResponse, error)
+	ListPartsFunc func(ctx context.Context, bucketName string, uploadId string,
+		opts ...bucketclient.ListPartsOption) (*bucketclient.ListPartsResponse, error)
+	ListBucketsByOwnerFunc func(ctx context.Context,
+		canonicalID string) ([]bucketclient.OwnerBucket, error)
+	ListLifecycleCurrentFunc func(ctx context.Context, bucketName string,
+		opts ...bucketclient.ListLifecycleOption) (*bucketclient.ListLifecycleResponse, error)
+	ListLifecycleNonCurrentFunc func(ctx context.Context, bucketName string,
+		opts ...bucketclient.ListLifecycleOption) (*bucketclient.ListLifecycleResponse, error)
+	ListLifecycleOrphanDeleteMarkersFunc func(ctx context.Context, bucketName string,
+		opts ...bucketclient.ListLifecycleOption) (*bucketclient.ListLifecycleResponse, error)
+	IterateLifecycleCurrentFunc func(ctx context.Context, bucketName string,
+		opts ...bucketclient.ListLifecycleOption) iter.Seq2[bucketclient.ListLifecycleEntry, error]
+	IterateLifecycleNonCurrentFunc func(ctx context.Context, bucketName string,
+		opts ...bucketclient.ListLifecycleOption) iter.Seq2[bucketclient.ListLifecycleEntry, error]
+	IterateLifecycleOrphanDeleteMarkersFunc func(ctx context.Context, bucketName string,
+		opts ...bucketclient.ListLifecycleOption) iter.Seq2[bucketclient.ListLifecycleEntry, error]
+
+	GetMetastoreEntryFunc func(ctx context.Context,
+		bucketName string) (bucketclient.MetastoreEntry, error)
+	CreateMetastoreEntryFunc func(ctx context.Context, bucketName string,
+		metastoreEntry bucketclient.MetastoreEntry) error
+	DeleteMetastoreEntryFunc func(ctx context.Context, bucketName string,
+		opts ...bucketclient.DeleteMetastoreEntryOption) error
+	CompareAndSwapMetastoreEntryFunc func(ctx context.Context, bucketName string,
+		expectedVersion int, newEntry bucketclient.MetastoreEntry) error
+
+	AdminBucketRefreshCacheFunc  func(ctx context.Context, bucketName string) error
+	AdminGetBucketAccessModeFunc func(ctx context.Context,
+		bucketName string) (bucketclient.BucketAccessMode, error)
+	AdminSetBucketAccessModeFunc func(ctx context.Context, bucketName string,
+		accessMode bucketclient.BucketAccessMode) error
+	AdminGetBucketSessionIDFunc func(ctx context.Context, bucketName string) (int, error)
+	AdminGetAllSessionsInfoFunc func(ctx context.Context) ([]bucketclient.SessionInfo, error)
+	AdminGetSessionInfoFunc     func(ctx context.Context,
+		sessionId int) (*bucketclient.SessionInfo, error)
+	AdminGetSessionLeaderFunc func(ctx context.Context,
+		sessionId int) (*bucketclient.MemberInfo, error)
+	AdminGetSessionLogFunc func(ctx context.Context, sessionId int, beginSeq int64, nRecords int,
+		targetLeader bool) (*bucketclient.AdminGetSessionLogResponse, error)
+}
+
+var _ bucketclient.BucketClientAPI = (*MockBucketClient)(nil)
+
+func (m *MockBucketClient) Request(ctx context.Context, apiMethod string, httpMethod string,
+	resource string, opts ...bucketclient.RequestOption) ([]byte, error) {
+	if m.RequestFunc == nil {
+		return nil, notMocked("Request")
+	}
+	return m.RequestFunc(ctx, apiMethod, httpMethod, resource, opts...)
+}
+
+func (m *MockBucketClient) CreateBucket(ctx context.Context, bucketName string,
+	bucketAttributes []byte, opts ...bucketclient.CreateBucketOption) error {
+	if m.CreateBucketFunc == nil {
+		return notMocked("CreateBucket")
+	}
+	return m.CreateBucketFunc(ctx, bucketName, bucketAttributes, opts...)
+}
+
+func (m *MockBucketClient) DeleteBucket(ctx context.Context, bucketName string,
+	opts ...bucketclient.DeleteBucketOption) error {
+	if m.DeleteBucketFunc == nil {
+		return notMocked("DeleteBucket")
+	}
+	return m.DeleteBucketFunc(ctx, bucketName, opts...)
+}
+
+func (m *MockBucketClient) GetBucketAttributes(ctx context.Context,
+	bucketName string) ([]byte, error) {
+	if m.GetBucketAttributesFunc == nil {
+		return nil, notMocked("GetBucketAttributes")
+	}
+	return m.GetBucketAttributesFunc(ctx, bucketName)
+}
+
+func (m *MockBucketClient) PutBucketAttributes(ctx context.Context, bucketName string,
+	bucketAttributes []byte) error {
+	if m.PutBucketAttributesFunc == nil {
+		return notMocked("PutBucketAttributes")
+	}
+	return m.PutBucketAttributesFunc(ctx, bucketName, bucketAttributes)
+}
+
+func (m *MockBucketClient) UpdateBucketAttributes(ctx context.Context, bucketName string,
+	mutate func(bucketInfo *bucketclient.BucketInfo) error,
+	opts ...bucketclient.UpdateBucketAttributesOption) error {
+	if m.UpdateBucketAttributesFunc == nil {
+		return notMocked("UpdateBucketAttributes")
+	}
+	return m.UpdateBucketAttributesFunc(ctx, bucketName, mutate, opts...)
+}
+
+func (m *MockBucketClient) PostBatch(ctx context.Context, bucketName string,
+	batch []bucketclient.PostBatchEntry) error {
+	if m.PostBatchFunc == nil {
+		return notMocked("PostBatch")
+	}
+	return m.PostBatchFunc(ctx, bucketName, batch)
+}
+
+func (m *MockBucketClient) DeleteRange(ctx context.Context, bucketName string,
+	opts ...bucketclient.DeleteRangeOption) (int, error) {
+	if m.DeleteRangeFunc == nil {
+		return 0, notMocked("DeleteRange")
+	}
+	return m.DeleteRangeFunc(ctx, bucketName, opts...)
+}
+
+func (m *MockBucketClient) PutUsersBucketEntry(ctx context.Context, canonicalID string,
+	bucketName string, creationDate time.Time) error {
+	if m.PutUsersBucketEntryFunc == nil {
+		return notMocked("PutUsersBucketEntry")
+	}
+	return m.PutUsersBucketEntryFunc(ctx, canonicalID, bucketName, creationDate)
+}
+
+func (m *MockBucketClient) DeleteUsersBucketEntry(ctx context.Context, canonicalID string,
+	bucketName string) error {
+	if m.DeleteUsersBucketEntryFunc == nil {
+		return notMocked("DeleteUsersBucketEntry")
+	}
+	return m.DeleteUsersBucketEntryFunc(ctx, canonicalID, bucketName)
+}
+
+func (m *MockBucketClient) ListBasic(ctx context.Context, bucketName string,
+	opts ...bucketclient.ListBasicOption) (*bucketclient.ListBasicResponse, error) {
+	if m.ListBasicFunc == nil {
+		return nil, notMocked("ListBasic")
+	}
+	return m.ListBasicFunc(ctx, bucketName, opts...)
+}
+
+func (m *MockBucketClient) ListObjectVersions(ctx context.Context, bucketName string,
+	opts ...bucketclient.ListObjectVersionsOption) (*bucketclient.ListObjectVersionsResponse, error) {
+	if m.ListObjectVersionsFunc == nil {
+		return nil, notMocked("ListObjectVersions")
+	}
+	return m.ListObjectVersionsFunc(ctx, bucketName, opts...)
+}
+
+func (m *MockBucketClient) ListMultipartUploads(ctx context.Context, bucketName string,
+	opts ...bucketclient.ListMultipartUploadsOption) (*bucketclient.ListMultipartUploadsResponse, error) {
+	if m.ListMultipartUploadsFunc == nil {
+		return nil, notMocked("ListMultipartUploads")
+	}
+	return m.ListMultipartUploadsFunc(ctx, bucketName, opts...)
+}
+
+func (m *MockBucketClient) ListParts(ctx context.Context, bucketName string, uploadId string,
+	opts ...bucketclient.ListPartsOption) (*bucketclient.ListPartsResponse, error) {
+	if m.ListPartsFunc == nil {
+		return nil, notMocked("ListParts")
+	}
+	return m.ListPartsFunc(ctx, bucketName, uploadId, opts...)
+}
+
+func (m *MockBucketClient) ListBucketsByOwner(ctx context.Context,
+	canonicalID string) ([]bucketclient.OwnerBucket, error) {
+	if m.ListBucketsByOwnerFunc == nil {
+		return nil, notMocked("ListBucketsByOwner")
+	}
+	return m.ListBucketsByOwnerFunc(ctx, canonicalID)
+}
+
+func (m *MockBucketClient) ListLifecycleCurrent(ctx context.Context, bucketName string,
+	opts ...bucketclient.ListLifecycleOption) (*bucketclient.ListLifecycleResponse, error) {
+	if m.ListLifecycleCurrentFunc == nil {
+		return nil, notMocked("ListLifecycleCurrent")
+	}
+	return m.ListLifecycleCurrentFunc(ctx, bucketName, opts...)
+}
+
+func (m *MockBucketClient) ListLifecycleNonCurrent(ctx context.Context, bucketName string,
+	opts ...bucketclient.ListLifecycleOption) (*bucketclient.ListLifecycleResponse, error) {
+	if m.ListLifecycleNonCurrentFunc == nil {
+		return nil, notMocked("ListLifecycleNonCurrent")
+	}
+	return m.ListLifecycleNonCurrentFunc(ctx, bucketName, opts...)
+}
+
+func (m *MockBucketClient) ListLifecycleOrphanDeleteMarkers(ctx context.Context, bucketName string,
+	opts ...bucketclient.ListLifecycleOption) (*bucketclient.ListLifecycleResponse, error) {
+	if m.ListLifecycleOrphanDeleteMarkersFunc == nil {
+		return nil, notMocked("ListLifecycleOrphanDeleteMarkers")
+	}
+	return m.ListLifecycleOrphanDeleteMarkersFunc(ctx, bucketName, opts...)
+}
+
+// notMockedSeq returns an iterator yielding a single ErrNotMocked error
+func notMockedSeq(method string) iter.Seq2[bucketclient.ListLifecycleEntry, error] {
+	return func(yield func(bucketclient.ListLifecycleEntry, error) bool) {
+		yield(bucketclient.ListLifecycleEntry{}, notMocked(method))
+	}
+}
+
+func (m *MockBucketClient) IterateLifecycleCurrent(ctx context.Context, bucketName string,
+	opts ...bucketclient.ListLifecycleOption) iter.Seq2[bucketclient.ListLifecycleEntry, error] {
+	if m.IterateLifecycleCurrentFunc == nil {
+		return notMockedSeq("IterateLifecycleCurrent")
+	}
+	return m.IterateLifecycleCurrentFunc(ctx, bucketName, opts...)
+}
+
+func (m *MockBucketClient) IterateLifecycleNonCurrent(ctx context.Context, bucketName string,
+	opts ...bucketclient.ListLifecycleOption) iter.Seq2[bucketclient.ListLifecycleEntry, error] {
+	if m.IterateLifecycleNonCurrentFunc == nil {
+		return notMockedSeq("IterateLifecycleNonCurrent")
+	}
+	return m.IterateLifecycleNonCurrentFunc(ctx, bucketName, opts...)
+}
+
+func (m *MockBucketClient) IterateLifecycleOrphanDeleteMarkers(ctx context.Context, bucketName string,
+	opts ...bucketclient.ListLifecycleOption) iter.Seq2[bucketclient.ListLifecycleEntry, error] {
+	if m.IterateLifecycleOrphanDeleteMarkersFunc == nil {
+		return notMockedSeq("IterateLifecycleOrphanDeleteMarkers")
+	}
+	return m.IterateLifecycleOrphanDeleteMarkersFunc(ctx, bucketName, opts...)
+}
+
+func (m *MockBucketClient) GetMetastoreEntry(ctx context.Context,
+	bucketName string) (bucketclient.MetastoreEntry, error) {
+	if m.GetMetastoreEntryFunc == nil {
+		return bucketclient.MetastoreEntry{}, notMocked("GetMetastoreEntry")
+	}
+	return m.GetMetastoreEntryFunc(ctx, bucketName)
+}
+
+func (m *MockBucketClient) CreateMetastoreEntry(ctx context.Context, bucketName string,
+	metastoreEntry bucketclient.MetastoreEntry) error {
+	if m.CreateMetastoreEntryFunc == nil {
+		return notMocked("CreateMetastoreEntry")
+	}
+	return m.CreateMetastoreEntryFunc(ctx, bucketName, metastoreEntry)
+}
+
+func (m *MockBucketClient) DeleteMetastoreEntry(ctx context.Context, bucketName string,
+	opts ...bucketclient.DeleteMetastoreEntryOption) error {
+	if m.DeleteMetastoreEntryFunc == nil {
+		return notMocked("DeleteMetastoreEntry")
+	}
+	return m.DeleteMetastoreEntryFunc(ctx, bucketName, opts...)
+}
+
+func (m *MockBucketClient) CompareAndSwapMetastoreEntry(ctx context.Context, bucketName string,
+	expectedVersion int, newEntry bucketclient.MetastoreEntry) error {
+	if m.CompareAndSwapMetastoreEntryFunc == nil {
+		return notMocked("CompareAndSwapMetastoreEntry")
+	}
+	return m.CompareAndSwapMetastoreEntryFunc(ctx, bucketName, expectedVersion, newEntry)
+}
+
+func (m *MockBucketClient) AdminBucketRefreshCache(ctx context.Context, bucketName string) error {
+	if m.AdminBucketRefreshCacheFunc == nil {
+		return notMocked("AdminBucketRefreshCache")
+	}
+	return m.AdminBucketRefreshCacheFunc(ctx, bucketName)
+}
+
+func (m *MockBucketClient) AdminGetBucketAccessMode(ctx context.Context,
+	bucketName string) (bucketclient.BucketAccessMode, error) {
+	if m.AdminGetBucketAccessModeFunc == nil {
+		return "", notMocked("AdminGetBucketAccessMode")
+	}
+	return m.AdminGetBucketAccessModeFunc(ctx, bucketName)
+}
+
+func (m *MockBucketClient) AdminSetBucketAccessMode(ctx context.Context, bucketName string,
+	accessMode bucketclient.BucketAccessMode) error {
+	if m.AdminSetBucketAccessModeFunc == nil {
+		return notMocked("AdminSetBucketAccessMode")
+	}
+	return m.AdminSetBucketAccessModeFunc(ctx, bucketName, accessMode)
+}
+
+func (m *MockBucketClient) AdminGetBucketSessionID(ctx context.Context,
+	bucketName string) (int, error) {
+	if m.AdminGetBucketSessionIDFunc == nil {
+		return 0, notMocked("AdminGetBucketSessionID")
+	}
+	return m.AdminGetBucketSessionIDFunc(ctx, bucketName)
+}
+
+func (m *MockBucketClient) AdminGetAllSessionsInfo(ctx context.Context) ([]bucketclient.SessionInfo, error) {
+	if m.AdminGetAllSessionsInfoFunc == nil {
+		return nil, notMocked("AdminGetAllSessionsInfo")
+	}
+	return m.AdminGetAllSessionsInfoFunc(ctx)
+}
+
+func (m *MockBucketClient) AdminGetSessionInfo(ctx context.Context,
+	sessionId int) (*bucketclient.SessionInfo, error) {
+	if m.AdminGetSessionInfoFunc == nil {
+		return nil, notMocked("AdminGetSessionInfo")
+	}
+	return m.AdminGetSessionInfoFunc(ctx, sessionId)
+}
+
+func (m *MockBucketClient) AdminGetSessionLeader(ctx context.Context,
+	sessionId int) (*bucketclient.MemberInfo, error) {
+	if m.AdminGetSessionLeaderFunc == nil {
+		return nil, notMocked("AdminGetSessionLeader")
+	}
+	return m.AdminGetSessionLeaderFunc(ctx, sessionId)
+}
+
+func (m *MockBucketClient) AdminGetSessionLog(ctx context.Context, sessionId int, beginSeq int64,
+	nRecords int, targetLeader bool) (*bucketclient.AdminGetSessionLogResponse, error) {
+	if m.AdminGetSessionLogFunc == nil {
+		return nil, notMocked("AdminGetSessionLog")
+	}
+	return m.AdminGetSessionLogFunc(ctx, sessionId, beginSeq, nRecords, targetLeader)
+}
@@ -0,0 +1,52 @@
+package bucketclienttest_test
+
+import (
+	"context"
+	"errors"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/scality/bucketclient/go"
+	"github.com/scality/bucketclient/go/bucketclienttest"
+)
+
+var _ = Describe("MockBucketClient", func() {
+	It("calls the mocked methods", func(ctx SpecContext) {
+		mock := &bucketclienttest.MockBucketClient{
+			GetBucketAttributesFunc: func(ctx context.Context, bucketName string) ([]byte, error) {
+				return []byte(`{"name":"` + bucketName + `"}`), nil
+			},
+		}
+		Expect(mock.GetBucketAttributes(ctx, "somebucket")).To(Equal([]byte(`{"name":"somebucket"}`)))
+	})
+
+	It("returns ErrNotMocked from methods which are not mocked", func(ctx SpecContext) {
+		mock := &bucketclienttest.MockBucketClient{}
+		_, err := mock.ListBasic(ctx, "somebucket")
+		Expect(errors.Is(err, bucketclienttest.ErrNotMocked)).To(BeTrue())
+		Expect(err.Error()).To(ContainSubstring("ListBasic"))
+
+		var iterErrors []error
+		for _, err := range mock.IterateLifecycleCurrent(ctx, "somebucket") {
+			iterErrors = append(iterErrors, err)
+		}
+		Expect(iterErrors).To(HaveLen(1))
+		Expect(errors.Is(iterErrors[0], bucketclienttest.ErrNotMocked)).To(BeTrue())
+	})
+
+	It("can be decorated by a CachingBucketClient", func(ctx SpecContext) {
+		nCalls := 0
+		mock := &bucketclienttest.MockBucketClient{
+			GetBucketAttributesFunc: func(ctx context.Context, bucketName string) ([]byte, error) {
+				nCalls += 1
+				return []byte(`{}`), nil
+			},
+		}
+		client, err := bucketclient.NewCachingBucketClient(mock)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(client.GetBucketAttributes(ctx, "somebucket")).To(Equal([]byte(`{}`)))
+		Expect(client.GetBucketAttributes(ctx, "somebucket")).To(Equal([]byte(`{}`)))
+		Expect(nCalls).To(Equal(1))
+	})
+})
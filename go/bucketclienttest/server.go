@@ -0,0 +1,400 @@
+// Package bucketclienttest provides an in-memory fake of bucketd to
+// test code using a bucketclient.BucketClient end to end, without
+// mocking individual HTTP requests.
+package bucketclienttest
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/scality/bucketclient/go"
+)
+
+type ServerOption func(*serverOptionSet)
+
+// ServerRaftSessionsOption sets the number of raft sessions of the fake
+// bucketd, numbered from 1 (default 1)
+func ServerRaftSessionsOption(count int) ServerOption {
+	return func(opts *serverOptionSet) {
+		opts.raftSessions = max(count, 1)
+	}
+}
+
+type serverOptionSet struct {
+	raftSessions int
+}
+
+// Server is an in-process fake bucketd serving HTTP on a local port.
+// It keeps buckets, their attributes and their keys in memory and
+// implements the routes used by bucketclient:
+//
+//   - bucket creation and deletion, and bucket attributes
+//   - object get, put and delete on /default/bucket/{bucket}/{key}
+//   - "Basic" and "DelimiterVersions" listings
+//   - batches on /default/batch/{bucket}
+//   - metastore entries on /default/metastore/db/{bucket}
+//   - admin routes on /_/, including bucket access modes and raft
+//     session logs
+//
+// Each write to a bucket is appended to the synthetic log of the raft
+// session hosting it. Versioning parameters of object routes are not
+// interpreted: objects are stored under the key given.
+type Server struct {
+	// URL is the endpoint of the fake bucketd, to give to
+	// bucketclient.New
+	URL string
+
+	server *httptest.Server
+
+	mutex     sync.Mutex
+	buckets   map[string]*fakeBucket
+	metastore map[string]bucketclient.MetastoreEntry
+	sessions  []*raftSession
+}
+
+type fakeBucket struct {
+	attributes []byte
+	keys       *keyspace
+	accessMode bucketclient.BucketAccessMode
+	sessionId  int
+}
+
+// NewServer starts a fake bucketd, to be stopped with Close
+func NewServer(opts ...ServerOption) *Server {
+	options := serverOptionSet{
+		raftSessions: 1,
+	}
+	for _, opt := range opts {
+		opt(&options)
+	}
+	s := &Server{
+		buckets:   map[string]*fakeBucket{},
+		metastore: map[string]bucketclient.MetastoreEntry{},
+	}
+	for id := 1; id <= options.raftSessions; id++ {
+		s.sessions = append(s.sessions, newRaftSession(id))
+	}
+	s.server = httptest.NewServer(http.HandlerFunc(s.serveHTTP))
+	s.URL = s.server.URL
+	return s
+}
+
+// NewClient returns a client of the fake bucketd
+func (s *Server) NewClient(opts ...bucketclient.BucketClientOption) *bucketclient.BucketClient {
+	return bucketclient.New(s.URL, opts...)
+}
+
+// Close stops the fake bucketd
+func (s *Server) Close() {
+	s.server.Close()
+}
+
+// errorResponse is an error returned by a route handler, sent as an
+// HTTP status with the error type as reason phrase like bucketd does
+type errorResponse struct {
+	statusCode int
+	errorType  string
+}
+
+func (e *errorResponse) Error() string {
+	return fmt.Sprintf("%d %s", e.statusCode, e.errorType)
+}
+
+var (
+	errBadRequest         = &errorResponse{http.StatusBadRequest, "BadRequest"}
+	errNoSuchBucket       = &errorResponse{http.StatusNotFound, "NoSuchBucket"}
+	errObjNotFound        = &errorResponse{http.StatusNotFound, "ObjNotFound"}
+	errRouteNotFound      = &errorResponse{http.StatusNotFound, "RouteNotFound"}
+	errMethodNotAllowed   = &errorResponse{http.StatusMethodNotAllowed, "MethodNotAllowed"}
+	errBucketExists       = &errorResponse{http.StatusConflict, "BucketAlreadyExists"}
+	errRangeNotSatisfied  = &errorResponse{http.StatusRequestedRangeNotSatisfiable, "RequestedRangeNotSatisfiable"}
+	errNotImplemented     = &errorResponse{http.StatusNotImplemented, "NotImplemented"}
+	errServiceUnavailable = &errorResponse{http.StatusServiceUnavailable, "ServiceUnavailable"}
+)
+
+func (s *Server) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	requestBody, err := io.ReadAll(r.Body)
+	if err != nil {
+		writeError(w, errBadRequest)
+		return
+	}
+	segments, err := pathSegments(r.URL.EscapedPath())
+	if err != nil {
+		writeError(w, errBadRequest)
+		return
+	}
+	s.mutex.Lock()
+	responseBody, err := s.route(r.Method, segments, r.URL.Query(), requestBody)
+	s.mutex.Unlock()
+	if err != nil {
+		errResponse, ok := err.(*errorResponse)
+		if !ok {
+			errResponse = errBadRequest
+		}
+		writeError(w, errResponse)
+		return
+	}
+	w.Header().Set("Content-Length", strconv.Itoa(len(responseBody)))
+	w.WriteHeader(http.StatusOK)
+	w.Write(responseBody)
+}
+
+// pathSegments splits an escaped URL path into unescaped segments
+func pathSegments(escapedPath string) ([]string, error) {
+	segments := strings.Split(strings.Trim(escapedPath, "/"), "/")
+	for i, segment := range segments {
+		unescaped, err := url.PathUnescape(segment)
+		if err != nil {
+			return nil, err
+		}
+		segments[i] = unescaped
+	}
+	return segments, nil
+}
+
+// writeError sends an error status with a custom reason phrase, which
+// net/http does not support, by writing the response on the hijacked
+// connection
+func writeError(w http.ResponseWriter, errResponse *errorResponse) {
+	hijacker, ok := w.(http.Hijacker)
+	if !ok {
+		http.Error(w, errResponse.errorType, errResponse.statusCode)
+		return
+	}
+	conn, buf, err := hijacker.Hijack()
+	if err != nil {
+		http.Error(w, errResponse.errorType, errResponse.statusCode)
+		return
+	}
+	defer conn.Close()
+	fmt.Fprintf(buf, "HTTP/1.1 %d %s\r\nContent-Length: 0\r\nConnection: close\r\n\r\n",
+		errResponse.statusCode, errResponse.errorType)
+	buf.Flush()
+}
+
+func (s *Server) route(method string, segments []string, query url.Values,
+	body []byte) ([]byte, error) {
+	switch {
+	case len(segments) == 3 && segments[0] == "default" && segments[1] == "attributes":
+		switch method {
+		case "GET":
+			return s.getBucketAttributes(segments[2])
+		case "POST":
+			return nil, s.putBucketAttributes(segments[2], body)
+		}
+	case len(segments) == 3 && segments[0] == "default" && segments[1] == "bucket":
+		switch method {
+		case "GET":
+			return s.listBucket(segments[2], query)
+		case "POST":
+			return nil, s.createBucket(segments[2], query, body)
+		case "DELETE":
+			return nil, s.deleteBucket(segments[2])
+		}
+	case len(segments) >= 4 && segments[0] == "default" && segments[1] == "bucket":
+		key := strings.Join(segments[3:], "/")
+		switch method {
+		case "GET":
+			return s.getObject(segments[2], key)
+		case "POST", "PUT":
+			return nil, s.putObject(segments[2], key, body)
+		case "DELETE":
+			return nil, s.deleteObject(segments[2], key)
+		}
+	case len(segments) == 3 && segments[0] == "default" && segments[1] == "batch":
+		if method == "POST" {
+			return nil, s.postBatch(segments[2], body)
+		}
+	case len(segments) == 4 && segments[0] == "default" &&
+		segments[1] == "metastore" && segments[2] == "db":
+		switch method {
+		case "GET":
+			return s.getMetastoreEntry(segments[3])
+		case "POST":
+			return nil, s.putMetastoreEntry(segments[3], body)
+		case "DELETE":
+			return nil, s.deleteMetastoreEntry(segments[3])
+		}
+	case len(segments) >= 2 && segments[0] == "_":
+		return s.routeAdmin(method, segments[1:], query)
+	default:
+		return nil, errRouteNotFound
+	}
+	return nil, errMethodNotAllowed
+}
+
+func (s *Server) getBucket(bucketName string) (*fakeBucket, error) {
+	bucket, found := s.buckets[bucketName]
+	if !found {
+		return nil, errNoSuchBucket
+	}
+	return bucket, nil
+}
+
+// getWritableBucket returns a bucket which is not in read-only mode
+func (s *Server) getWritableBucket(bucketName string) (*fakeBucket, error) {
+	bucket, err := s.getBucket(bucketName)
+	if err != nil {
+		return nil, err
+	}
+	if bucket.accessMode == bucketclient.BucketAccessModeReadOnly {
+		return nil, errServiceUnavailable
+	}
+	return bucket, nil
+}
+
+func (s *Server) getBucketAttributes(bucketName string) ([]byte, error) {
+	bucket, err := s.getBucket(bucketName)
+	if err != nil {
+		return nil, err
+	}
+	return bucket.attributes, nil
+}
+
+func (s *Server) putBucketAttributes(bucketName string, attributes []byte) error {
+	bucket, err := s.getWritableBucket(bucketName)
+	if err != nil {
+		return err
+	}
+	if !json.Valid(attributes) {
+		return errBadRequest
+	}
+	bucket.attributes = attributes
+	s.appendLog(bucket, bucketName, bucketclient.DBMethodPutAttributes,
+		bucketclient.SessionLogEntry{Value: string(attributes)})
+	return nil
+}
+
+func (s *Server) createBucket(bucketName string, query url.Values, attributes []byte) error {
+	if _, found := s.buckets[bucketName]; found {
+		return errBucketExists
+	}
+	if !json.Valid(attributes) {
+		return errBadRequest
+	}
+	sessionId := 1
+	if raftSession := query.Get("raftsession"); raftSession != "" {
+		id, err := strconv.Atoi(raftSession)
+		if err != nil || s.getSession(id) == nil {
+			return errBadRequest
+		}
+		sessionId = id
+	}
+	bucket := &fakeBucket{
+		attributes: attributes,
+		keys:       newKeyspace(),
+		accessMode: bucketclient.BucketAccessModeReadWrite,
+		sessionId:  sessionId,
+	}
+	s.buckets[bucketName] = bucket
+	s.appendLog(bucket, bucketName, bucketclient.DBMethodCreate,
+		bucketclient.SessionLogEntry{Value: string(attributes)})
+	return nil
+}
+
+func (s *Server) deleteBucket(bucketName string) error {
+	bucket, err := s.getWritableBucket(bucketName)
+	if err != nil {
+		return err
+	}
+	delete(s.buckets, bucketName)
+	s.appendLog(bucket, bucketName, bucketclient.DBMethodDelete)
+	return nil
+}
+
+func (s *Server) getObject(bucketName string, key string) ([]byte, error) {
+	bucket, err := s.getBucket(bucketName)
+	if err != nil {
+		return nil, err
+	}
+	value, found := bucket.keys.get(key)
+	if !found {
+		return nil, errObjNotFound
+	}
+	return []byte(value), nil
+}
+
+func (s *Server) putObject(bucketName string, key string, value []byte) error {
+	bucket, err := s.getWritableBucket(bucketName)
+	if err != nil {
+		return err
+	}
+	bucket.keys.put(key, string(value))
+	s.appendLog(bucket, bucketName, bucketclient.DBMethodPut,
+		bucketclient.SessionLogEntry{Key: key, Value: string(value)})
+	return nil
+}
+
+func (s *Server) deleteObject(bucketName string, key string) error {
+	bucket, err := s.getWritableBucket(bucketName)
+	if err != nil {
+		return err
+	}
+	if !bucket.keys.delete(key) {
+		return errObjNotFound
+	}
+	s.appendLog(bucket, bucketName, bucketclient.DBMethodDel,
+		bucketclient.SessionLogEntry{Key: key})
+	return nil
+}
+
+func (s *Server) postBatch(bucketName string, body []byte) error {
+	bucket, err := s.getWritableBucket(bucketName)
+	if err != nil {
+		return err
+	}
+	var payload struct {
+		Batch []bucketclient.PostBatchEntry `json:"batch"`
+	}
+	if json.Unmarshal(body, &payload) != nil {
+		return errBadRequest
+	}
+	logEntries := make([]bucketclient.SessionLogEntry, 0, len(payload.Batch))
+	for _, entry := range payload.Batch {
+		if entry.Type != "" && entry.Type != "put" && entry.Type != "del" {
+			return errBadRequest
+		}
+	}
+	for _, entry := range payload.Batch {
+		if entry.Type == "del" {
+			bucket.keys.delete(entry.Key)
+		} else {
+			bucket.keys.put(entry.Key, entry.Value)
+		}
+		logEntries = append(logEntries, bucketclient.SessionLogEntry(entry))
+	}
+	s.appendLog(bucket, bucketName, bucketclient.DBMethodBatch, logEntries...)
+	return nil
+}
+
+func (s *Server) getMetastoreEntry(bucketName string) ([]byte, error) {
+	entry, found := s.metastore[bucketName]
+	if !found {
+		return nil, errObjNotFound
+	}
+	return json.Marshal(entry)
+}
+
+func (s *Server) putMetastoreEntry(bucketName string, body []byte) error {
+	var entry bucketclient.MetastoreEntry
+	if json.Unmarshal(body, &entry) != nil {
+		return errBadRequest
+	}
+	s.metastore[bucketName] = entry
+	return nil
+}
+
+func (s *Server) deleteMetastoreEntry(bucketName string) error {
+	if _, found := s.metastore[bucketName]; !found {
+		return errObjNotFound
+	}
+	delete(s.metastore, bucketName)
+	return nil
+}
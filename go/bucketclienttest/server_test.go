@@ -0,0 +1,146 @@
+package bucketclienttest_test
+
+import (
+	"errors"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/scality/bucketclient/go"
+	"github.com/scality/bucketclient/go/bucketclienttest"
+)
+
+// expectBucketdError expects err to be a BucketClientError with the
+// given status and error type
+func expectBucketdError(err error, statusCode int, errorType string) {
+	var bcErr *bucketclient.BucketClientError
+	ExpectWithOffset(1, errors.As(err, &bcErr)).To(BeTrue(), "unexpected error %v", err)
+	ExpectWithOffset(1, bcErr.StatusCode).To(Equal(statusCode))
+	ExpectWithOffset(1, bcErr.ErrorType).To(Equal(errorType))
+}
+
+var _ = Describe("Server", func() {
+	var (
+		server *bucketclienttest.Server
+		client *bucketclient.BucketClient
+	)
+
+	BeforeEach(func() {
+		server = bucketclienttest.NewServer()
+		DeferCleanup(server.Close)
+		client = server.NewClient()
+	})
+
+	Describe("buckets", func() {
+		It("creates buckets and gets and puts their attributes", func(ctx SpecContext) {
+			Expect(client.CreateBucket(ctx, "somebucket", []byte(`{"uid":"1"}`))).To(Succeed())
+			Expect(client.GetBucketAttributes(ctx, "somebucket")).To(Equal([]byte(`{"uid":"1"}`)))
+			Expect(client.PutBucketAttributes(ctx, "somebucket", []byte(`{"uid":"2"}`))).To(Succeed())
+			Expect(client.GetBucketAttributes(ctx, "somebucket")).To(Equal([]byte(`{"uid":"2"}`)))
+		})
+
+		It("refuses to create an existing bucket", func(ctx SpecContext) {
+			Expect(client.CreateBucket(ctx, "somebucket", []byte(`{"uid":"1"}`))).To(Succeed())
+			err := client.CreateBucket(ctx, "somebucket", []byte(`{"uid":"2"}`))
+			expectBucketdError(err, 409, "BucketAlreadyExists")
+			Expect(client.CreateBucket(ctx, "somebucket", []byte(`{"uid":"1"}`),
+				bucketclient.CreateBucketMakeIdempotent)).To(Succeed())
+		})
+
+		It("deletes buckets", func(ctx SpecContext) {
+			Expect(client.CreateBucket(ctx, "somebucket", []byte(`{"uid":"1"}`))).To(Succeed())
+			Expect(client.DeleteBucket(ctx, "somebucket")).To(Succeed())
+			_, err := client.GetBucketAttributes(ctx, "somebucket")
+			expectBucketdError(err, 404, "NoSuchBucket")
+			expectBucketdError(client.DeleteBucket(ctx, "somebucket"), 404, "NoSuchBucket")
+			Expect(client.DeleteBucket(ctx, "somebucket",
+				bucketclient.DeleteBucketMakeIdempotent)).To(Succeed())
+		})
+	})
+
+	Describe("objects", func() {
+		BeforeEach(func(ctx SpecContext) {
+			Expect(client.CreateBucket(ctx, "somebucket", []byte(`{}`))).To(Succeed())
+		})
+
+		It("puts, gets and deletes objects", func(ctx SpecContext) {
+			_, err := client.Request(ctx, "PutObject", "POST", "/default/bucket/somebucket/dir%2Fobj",
+				bucketclient.RequestBodyOption([]byte(`{"size":42}`)))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(client.Request(ctx, "GetObject", "GET",
+				"/default/bucket/somebucket/dir%2Fobj")).To(Equal([]byte(`{"size":42}`)))
+			_, err = client.Request(ctx, "DeleteObject", "DELETE", "/default/bucket/somebucket/dir%2Fobj")
+			Expect(err).ToNot(HaveOccurred())
+			_, err = client.Request(ctx, "GetObject", "GET", "/default/bucket/somebucket/dir%2Fobj")
+			expectBucketdError(err, 404, "ObjNotFound")
+		})
+
+		It("applies batches", func(ctx SpecContext) {
+			Expect(client.PostBatch(ctx, "somebucket", []bucketclient.PostBatchEntry{
+				{Key: "a", Value: "1"},
+				{Key: "b", Value: "2"},
+			})).To(Succeed())
+			Expect(client.PostBatch(ctx, "somebucket", []bucketclient.PostBatchEntry{
+				{Key: "a", Type: "del"},
+				{Key: "c", Value: "3"},
+			})).To(Succeed())
+			Expect(client.ListBasic(ctx, "somebucket")).To(Equal(&bucketclient.ListBasicResponse{
+				{Key: "b", Value: "2"},
+				{Key: "c", Value: "3"},
+			}))
+		})
+
+		It("returns an error on non-existing buckets", func(ctx SpecContext) {
+			err := client.PostBatch(ctx, "nosuchbucket", []bucketclient.PostBatchEntry{
+				{Key: "a", Value: "1"},
+			})
+			expectBucketdError(err, 404, "NoSuchBucket")
+		})
+	})
+
+	Describe("read-only access mode", func() {
+		It("refuses writes on read-only buckets", func(ctx SpecContext) {
+			Expect(client.CreateBucket(ctx, "somebucket", []byte(`{}`))).To(Succeed())
+			Expect(client.AdminSetBucketAccessMode(ctx, "somebucket",
+				bucketclient.BucketAccessModeReadOnly)).To(Succeed())
+			Expect(client.AdminGetBucketAccessMode(ctx, "somebucket")).To(
+				Equal(bucketclient.BucketAccessModeReadOnly))
+
+			err := client.PostBatch(ctx, "somebucket", []bucketclient.PostBatchEntry{
+				{Key: "a", Value: "1"},
+			})
+			expectBucketdError(err, 503, "ServiceUnavailable")
+			err = client.PutBucketAttributes(ctx, "somebucket", []byte(`{"uid":"2"}`))
+			expectBucketdError(err, 503, "ServiceUnavailable")
+			expectBucketdError(client.DeleteBucket(ctx, "somebucket"), 503, "ServiceUnavailable")
+			Expect(client.GetBucketAttributes(ctx, "somebucket")).To(Equal([]byte(`{}`)))
+
+			Expect(client.AdminSetBucketAccessMode(ctx, "somebucket",
+				bucketclient.BucketAccessModeReadWrite)).To(Succeed())
+			Expect(client.DeleteBucket(ctx, "somebucket")).To(Succeed())
+		})
+	})
+
+	Describe("metastore", func() {
+		It("creates, gets, swaps and deletes entries", func(ctx SpecContext) {
+			_, err := client.GetMetastoreEntry(ctx, "somebucket")
+			expectBucketdError(err, 404, "ObjNotFound")
+			Expect(client.CreateMetastoreEntry(ctx, "somebucket", bucketclient.MetastoreEntry{
+				Name: "somebucket", ID: "id1", Version: 1,
+			})).To(Succeed())
+			Expect(client.CompareAndSwapMetastoreEntry(ctx, "somebucket", 1,
+				bucketclient.MetastoreEntry{Name: "somebucket", ID: "id2"})).To(Succeed())
+			Expect(client.GetMetastoreEntry(ctx, "somebucket")).To(Equal(bucketclient.MetastoreEntry{
+				Name: "somebucket", ID: "id2", Version: 2,
+			}))
+			Expect(client.DeleteMetastoreEntry(ctx, "somebucket",
+				bucketclient.DeleteMetastoreEntryExpectedIDOption("id2"))).To(Succeed())
+			expectBucketdError(client.DeleteMetastoreEntry(ctx, "somebucket"), 404, "ObjNotFound")
+		})
+	})
+
+	It("returns an error on unknown routes", func(ctx SpecContext) {
+		_, err := client.Request(ctx, "Unknown", "GET", "/default/unknown")
+		expectBucketdError(err, 404, "RouteNotFound")
+	})
+})
@@ -0,0 +1,74 @@
+package bucketclient
+
+import (
+	"encoding/json"
+)
+
+// BucketInfo is the set of attributes of a bucket, as returned by
+// GetBucketAttributes, mirroring the BucketInfo model of Arsenal.
+//
+// Attributes not modeled here are preserved when decoding then
+// encoding the attributes, as are the original encodings of modeled
+// attributes left unchanged, so that read-modify-write cycles do not
+// lose information. Complex configurations are kept as raw JSON.
+type BucketInfo struct {
+	Name                      string                   `json:"name"`
+	Owner                     string                   `json:"owner"`
+	OwnerDisplayName          string                   `json:"ownerDisplayName"`
+	CreationDate              string                   `json:"creationDate"`
+	MDBucketModelVersion      int                      `json:"mdBucketModelVersion"`
+	ACL                       BucketACL                `json:"acl"`
+	Transient                 bool                     `json:"transient"`
+	Deleted                   bool                     `json:"deleted"`
+	UID                       string                   `json:"uid,omitempty"`
+	LocationConstraint        string                   `json:"locationConstraint"`
+	VersioningConfiguration   *VersioningConfiguration `json:"versioningConfiguration"`
+	ServerSideEncryption      json.RawMessage          `json:"serverSideEncryption,omitempty"`
+	WebsiteConfiguration      json.RawMessage          `json:"websiteConfiguration,omitempty"`
+	Cors                      json.RawMessage          `json:"cors,omitempty"`
+	ReplicationConfiguration  json.RawMessage          `json:"replicationConfiguration,omitempty"`
+	LifecycleConfiguration    json.RawMessage          `json:"lifecycleConfiguration,omitempty"`
+	BucketPolicy              json.RawMessage          `json:"bucketPolicy,omitempty"`
+	ObjectLockEnabled         bool                     `json:"objectLockEnabled"`
+	ObjectLockConfiguration   json.RawMessage          `json:"objectLockConfiguration,omitempty"`
+	NotificationConfiguration json.RawMessage          `json:"notificationConfiguration,omitempty"`
+
+	raw rawAttributes
+}
+
+type bucketInfoFields BucketInfo
+
+func (info *BucketInfo) UnmarshalJSON(data []byte) error {
+	*info = BucketInfo{}
+	raw, err := unmarshalMetadata(data, (*bucketInfoFields)(info))
+	info.raw = raw
+	return err
+}
+
+func (info BucketInfo) MarshalJSON() ([]byte, error) {
+	return marshalMetadata((*bucketInfoFields)(&info), info.raw)
+}
+
+// BucketACL is the access control list of a bucket, where each
+// permission lists the canonical IDs of grantees
+type BucketACL struct {
+	Canned      string   `json:"Canned"`
+	FullControl []string `json:"FULL_CONTROL"`
+	Write       []string `json:"WRITE"`
+	WriteACP    []string `json:"WRITE_ACP"`
+	Read        []string `json:"READ"`
+	ReadACP     []string `json:"READ_ACP"`
+}
+
+// VersioningConfiguration is the versioning configuration of a bucket
+type VersioningConfiguration struct {
+	Status    string `json:"Status"`
+	MfaDelete string `json:"MfaDelete,omitempty"`
+}
+
+// IsVersioningEnabled returns whether versioning is currently enabled
+// on the bucket. It returns false if versioning is suspended.
+func (info *BucketInfo) IsVersioningEnabled() bool {
+	return info.VersioningConfiguration != nil &&
+		info.VersioningConfiguration.Status == "Enabled"
+}
@@ -0,0 +1,68 @@
+package bucketclient_test
+
+import (
+	"encoding/json"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/scality/bucketclient/go"
+)
+
+var testBucketInfo = `{
+  "acl": {"Canned": "private", "FULL_CONTROL": [], "WRITE": [], "WRITE_ACP": [], "READ": [], "READ_ACP": []},
+  "name": "my-bucket",
+  "owner": "79a59df900b949e55d96a1e698fbacedfd6e09d98eacf8f8d5218e7cd47ef2be",
+  "ownerDisplayName": "owner",
+  "creationDate": "2024-03-01T10:20:30.456Z",
+  "mdBucketModelVersion": 10,
+  "transient": false,
+  "deleted": false,
+  "serverSideEncryption": null,
+  "versioningConfiguration": null,
+  "locationConstraint": "us-east-1",
+  "readLocationConstraint": "us-east-1",
+  "cors": null,
+  "replicationConfiguration": null,
+  "lifecycleConfiguration": {"rules": [{"ruleID": "expire", "ruleStatus": "Enabled", "actions": []}]},
+  "uid": "9f0bdf3d-a5fa-4a1b-9c9c-b6b4a6e2a1c5",
+  "isNFS": null,
+  "objectLockEnabled": false,
+  "capabilities": {"VeeamSOSApi": {}}
+}`
+
+var _ = Describe("BucketInfo", func() {
+	It("decodes known attributes", func() {
+		var info bucketclient.BucketInfo
+		Expect(json.Unmarshal([]byte(testBucketInfo), &info)).To(Succeed())
+		Expect(info.Name).To(Equal("my-bucket"))
+		Expect(info.UID).To(Equal("9f0bdf3d-a5fa-4a1b-9c9c-b6b4a6e2a1c5"))
+		Expect(info.MDBucketModelVersion).To(Equal(10))
+		Expect(info.VersioningConfiguration).To(BeNil())
+		Expect(info.IsVersioningEnabled()).To(BeFalse())
+		Expect(info.LifecycleConfiguration).To(MatchJSON(
+			`{"rules": [{"ruleID": "expire", "ruleStatus": "Enabled", "actions": []}]}`))
+	})
+
+	It("encodes back unchanged attributes losslessly", func() {
+		var info bucketclient.BucketInfo
+		Expect(json.Unmarshal([]byte(testBucketInfo), &info)).To(Succeed())
+		Expect(json.Marshal(info)).To(MatchJSON(testBucketInfo))
+	})
+
+	It("encodes modified attributes and preserves unknown ones", func() {
+		var info bucketclient.BucketInfo
+		Expect(json.Unmarshal([]byte(testBucketInfo), &info)).To(Succeed())
+		info.VersioningConfiguration = &bucketclient.VersioningConfiguration{Status: "Enabled"}
+		Expect(info.IsVersioningEnabled()).To(BeTrue())
+
+		encoded, err := json.Marshal(info)
+		Expect(err).ToNot(HaveOccurred())
+		var decoded map[string]any
+		Expect(json.Unmarshal(encoded, &decoded)).To(Succeed())
+		Expect(decoded["versioningConfiguration"]).To(Equal(map[string]any{"Status": "Enabled"}))
+		Expect(decoded["readLocationConstraint"]).To(Equal("us-east-1"))
+		Expect(decoded["capabilities"]).To(Equal(map[string]any{"VeeamSOSApi": map[string]any{}}))
+		Expect(decoded).To(HaveKeyWithValue("isNFS", BeNil()))
+	})
+})
@@ -0,0 +1,333 @@
+package bucketclient
+
+import (
+	"container/list"
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+type CachingBucketClientOption func(*cachingBucketClientOptionSet) error
+
+// CachingTTLOption sets how long successful responses are cached
+// (default 30s)
+func CachingTTLOption(ttl time.Duration) CachingBucketClientOption {
+	return func(opts *cachingBucketClientOptionSet) error {
+		if ttl <= 0 {
+			return fmt.Errorf("ttl=%s must be positive", ttl)
+		}
+		opts.ttl = ttl
+		return nil
+	}
+}
+
+// CachingNegativeTTLOption sets how long "404 Not Found" responses are
+// cached, or disables caching them if zero (default 5s)
+func CachingNegativeTTLOption(negativeTTL time.Duration) CachingBucketClientOption {
+	return func(opts *cachingBucketClientOptionSet) error {
+		if negativeTTL < 0 {
+			return fmt.Errorf("negativeTTL=%s must not be negative", negativeTTL)
+		}
+		opts.negativeTTL = negativeTTL
+		return nil
+	}
+}
+
+// CachingMaxEntriesOption bounds the number of buckets kept in each
+// cache, evicting the least recently used ones first (default 10000)
+func CachingMaxEntriesOption(maxEntries int) CachingBucketClientOption {
+	return func(opts *cachingBucketClientOptionSet) error {
+		if maxEntries < 1 {
+			return fmt.Errorf("maxEntries=%d must be at least 1", maxEntries)
+		}
+		opts.maxEntries = maxEntries
+		return nil
+	}
+}
+
+type cachingBucketClientOptionSet struct {
+	ttl         time.Duration
+	negativeTTL time.Duration
+	maxEntries  int
+}
+
+// CachingBucketClient wraps a BucketClientAPI, like a BucketClient, to
+// cache bucket attributes and raft session IDs of buckets, which are
+// read often and rarely change.
+//
+// Writes made through the CachingBucketClient invalidate the cached
+// entries of the modified bucket, but changes made by other clients
+// are only seen after the cached entries expire, or after calling
+// Invalidate. All other methods of BucketClientAPI are passed through.
+type CachingBucketClient struct {
+	BucketClientAPI
+
+	attributes *bucketCache[[]byte]
+	sessionIDs *bucketCache[int]
+}
+
+// NewCachingBucketClient returns a caching layer around client.
+func NewCachingBucketClient(client BucketClientAPI,
+	opts ...CachingBucketClientOption) (*CachingBucketClient, error) {
+	options := cachingBucketClientOptionSet{
+		ttl:         30 * time.Second,
+		negativeTTL: 5 * time.Second,
+		maxEntries:  10000,
+	}
+	for _, opt := range opts {
+		err := opt(&options)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return &CachingBucketClient{
+		BucketClientAPI: client,
+		attributes:      newBucketCache[[]byte](options),
+		sessionIDs:      newBucketCache[int](options),
+	}, nil
+}
+
+// GetBucketAttributes retrieves the JSON blob containing the bucket
+// attributes attached to a bucket, from the cache if available.
+//
+// The returned slice is a copy and may be modified by the caller.
+func (client *CachingBucketClient) GetBucketAttributes(ctx context.Context,
+	bucketName string) ([]byte, error) {
+	attributes, err := client.attributes.get(bucketName, func() ([]byte, error) {
+		return client.BucketClientAPI.GetBucketAttributes(ctx, bucketName)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(nil), attributes...), nil
+}
+
+// NewBatchBuilder returns an empty batch builder on the given bucket,
+// sending batches through the wrapped client.
+func (client *CachingBucketClient) NewBatchBuilder(bucketName string,
+	opts ...BatchBuilderOption) (*BatchBuilder, error) {
+	return newBatchBuilder(client, bucketName, opts)
+}
+
+// AdminGetBucketSessionID returns the raft session ID of the given
+// bucket, from the cache if available.
+func (client *CachingBucketClient) AdminGetBucketSessionID(ctx context.Context,
+	bucketName string) (int, error) {
+	return client.sessionIDs.get(bucketName, func() (int, error) {
+		return client.BucketClientAPI.AdminGetBucketSessionID(ctx, bucketName)
+	})
+}
+
+// PutBucketAttributes updates the bucket attributes with a new JSON
+// blob, and invalidates the cached attributes of the bucket.
+func (client *CachingBucketClient) PutBucketAttributes(ctx context.Context,
+	bucketName string, bucketAttributes []byte) error {
+	defer client.attributes.invalidate(bucketName)
+	return client.BucketClientAPI.PutBucketAttributes(ctx, bucketName, bucketAttributes)
+}
+
+// UpdateBucketAttributes applies a read-modify-write update to the
+// attributes of a bucket, bypassing the cache for reads, and
+// invalidates the cached attributes of the bucket.
+func (client *CachingBucketClient) UpdateBucketAttributes(ctx context.Context, bucketName string,
+	mutate func(bucketInfo *BucketInfo) error, opts ...UpdateBucketAttributesOption) error {
+	defer client.attributes.invalidate(bucketName)
+	return client.BucketClientAPI.UpdateBucketAttributes(ctx, bucketName, mutate, opts...)
+}
+
+// CreateBucket creates a bucket in metadata, and invalidates all
+// cached entries of the bucket, including cached "404 Not Found"
+// responses.
+func (client *CachingBucketClient) CreateBucket(ctx context.Context,
+	bucketName string, bucketAttributes []byte, opts ...CreateBucketOption) error {
+	defer client.Invalidate(bucketName)
+	return client.BucketClientAPI.CreateBucket(ctx, bucketName, bucketAttributes, opts...)
+}
+
+// DeleteBucket deletes a bucket from metadata, and invalidates all
+// cached entries of the bucket.
+func (client *CachingBucketClient) DeleteBucket(ctx context.Context,
+	bucketName string, opts ...DeleteBucketOption) error {
+	defer client.Invalidate(bucketName)
+	return client.BucketClientAPI.DeleteBucket(ctx, bucketName, opts...)
+}
+
+// AdminBucketRefreshCache refreshes the bucketd cache of metastore
+// entries for the given bucket, and invalidates the cached raft
+// session ID of the bucket.
+func (client *CachingBucketClient) AdminBucketRefreshCache(ctx context.Context,
+	bucketName string) error {
+	defer client.sessionIDs.invalidate(bucketName)
+	return client.BucketClientAPI.AdminBucketRefreshCache(ctx, bucketName)
+}
+
+// CreateMetastoreEntry creates or overwrites the metastore entry of a
+// bucket, and invalidates the cached raft session ID of the bucket.
+func (client *CachingBucketClient) CreateMetastoreEntry(ctx context.Context,
+	bucketName string, metastoreEntry MetastoreEntry) error {
+	defer client.sessionIDs.invalidate(bucketName)
+	return client.BucketClientAPI.CreateMetastoreEntry(ctx, bucketName, metastoreEntry)
+}
+
+// DeleteMetastoreEntry deletes the metastore entry of a bucket, and
+// invalidates the cached raft session ID of the bucket.
+func (client *CachingBucketClient) DeleteMetastoreEntry(ctx context.Context,
+	bucketName string, opts ...DeleteMetastoreEntryOption) error {
+	defer client.sessionIDs.invalidate(bucketName)
+	return client.BucketClientAPI.DeleteMetastoreEntry(ctx, bucketName, opts...)
+}
+
+// CompareAndSwapMetastoreEntry replaces the metastore entry of a bucket
+// if its version matches, and invalidates the cached raft session ID
+// of the bucket.
+func (client *CachingBucketClient) CompareAndSwapMetastoreEntry(ctx context.Context,
+	bucketName string, expectedVersion int, newEntry MetastoreEntry) error {
+	defer client.sessionIDs.invalidate(bucketName)
+	return client.BucketClientAPI.CompareAndSwapMetastoreEntry(ctx, bucketName,
+		expectedVersion, newEntry)
+}
+
+// Invalidate removes all cached entries of the given bucket, so that
+// the next reads fetch them from bucketd.
+func (client *CachingBucketClient) Invalidate(bucketName string) {
+	client.attributes.invalidate(bucketName)
+	client.sessionIDs.invalidate(bucketName)
+}
+
+// InvalidateAll removes all cached entries.
+func (client *CachingBucketClient) InvalidateAll() {
+	client.attributes.invalidateAll()
+	client.sessionIDs.invalidateAll()
+}
+
+// bucketCache is a LRU cache of values per bucket name with expiration
+type bucketCache[V any] struct {
+	ttl         time.Duration
+	negativeTTL time.Duration
+	maxEntries  int
+
+	mutex   sync.Mutex
+	entries map[string]*list.Element
+	lru     *list.List
+	// fetches tracks the buckets being fetched, so that responses to
+	// requests sent before an invalidation of the bucket are not
+	// cached
+	fetches map[string]*bucketFetches
+	// generation is incremented by invalidateAll, for the same purpose
+	generation uint64
+}
+
+// bucketFetches counts the fetches in flight of a bucket, and its
+// invalidations since the first one started
+type bucketFetches struct {
+	inFlight   int
+	generation uint64
+}
+
+type bucketCacheEntry[V any] struct {
+	bucketName string
+	value      V
+	err        *BucketClientError
+	expiresAt  time.Time
+}
+
+func newBucketCache[V any](options cachingBucketClientOptionSet) *bucketCache[V] {
+	return &bucketCache[V]{
+		ttl:         options.ttl,
+		negativeTTL: options.negativeTTL,
+		maxEntries:  options.maxEntries,
+		entries:     map[string]*list.Element{},
+		lru:         list.New(),
+		fetches:     map[string]*bucketFetches{},
+	}
+}
+
+// get returns the cached value of the bucket, or calls fetch and
+// caches its result if it succeeded or returned "404 Not Found"
+func (cache *bucketCache[V]) get(bucketName string, fetch func() (V, error)) (V, error) {
+	cache.mutex.Lock()
+	if elem, found := cache.entries[bucketName]; found {
+		entry := elem.Value.(*bucketCacheEntry[V])
+		if time.Now().Before(entry.expiresAt) {
+			cache.lru.MoveToFront(elem)
+			cache.mutex.Unlock()
+			if entry.err != nil {
+				var zero V
+				errCopy := *entry.err
+				return zero, &errCopy
+			}
+			return entry.value, nil
+		}
+		cache.removeElement(elem)
+	}
+	fetches, found := cache.fetches[bucketName]
+	if !found {
+		fetches = &bucketFetches{}
+		cache.fetches[bucketName] = fetches
+	}
+	fetches.inFlight += 1
+	bucketGeneration, generation := fetches.generation, cache.generation
+	cache.mutex.Unlock()
+
+	value, err := fetch()
+
+	cache.mutex.Lock()
+	defer cache.mutex.Unlock()
+	fetches.inFlight -= 1
+	if fetches.inFlight == 0 {
+		delete(cache.fetches, bucketName)
+	}
+	if fetches.generation != bucketGeneration || cache.generation != generation {
+		return value, err
+	}
+	entry := &bucketCacheEntry[V]{bucketName: bucketName, value: value}
+	if err != nil {
+		var bcErr *BucketClientError
+		if cache.negativeTTL == 0 || !errors.As(err, &bcErr) || !isNotFoundError(err) {
+			return value, err
+		}
+		errCopy := *bcErr
+		entry.err = &errCopy
+		entry.expiresAt = time.Now().Add(cache.negativeTTL)
+	} else {
+		entry.expiresAt = time.Now().Add(cache.ttl)
+	}
+	cache.store(entry)
+	return value, err
+}
+
+func (cache *bucketCache[V]) store(entry *bucketCacheEntry[V]) {
+	if elem, found := cache.entries[entry.bucketName]; found {
+		cache.removeElement(elem)
+	}
+	cache.entries[entry.bucketName] = cache.lru.PushFront(entry)
+	for cache.lru.Len() > cache.maxEntries {
+		cache.removeElement(cache.lru.Back())
+	}
+}
+
+func (cache *bucketCache[V]) removeElement(elem *list.Element) {
+	cache.lru.Remove(elem)
+	delete(cache.entries, elem.Value.(*bucketCacheEntry[V]).bucketName)
+}
+
+func (cache *bucketCache[V]) invalidate(bucketName string) {
+	cache.mutex.Lock()
+	defer cache.mutex.Unlock()
+	if fetches, found := cache.fetches[bucketName]; found {
+		fetches.generation += 1
+	}
+	if elem, found := cache.entries[bucketName]; found {
+		cache.removeElement(elem)
+	}
+}
+
+func (cache *bucketCache[V]) invalidateAll() {
+	cache.mutex.Lock()
+	defer cache.mutex.Unlock()
+	cache.generation += 1
+	cache.entries = map[string]*list.Element{}
+	cache.lru.Init()
+}
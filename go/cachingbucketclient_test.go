@@ -0,0 +1,236 @@
+package bucketclient_test
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/jarcoal/httpmock"
+
+	"github.com/scality/bucketclient/go"
+)
+
+var _ = Describe("CachingBucketClient", func() {
+	var cachingClient *bucketclient.CachingBucketClient
+
+	BeforeEach(func() {
+		var err error
+		cachingClient, err = bucketclient.NewCachingBucketClient(client,
+			bucketclient.CachingTTLOption(time.Hour),
+			bucketclient.CachingNegativeTTLOption(time.Hour))
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	It("caches bucket attributes", func(ctx SpecContext) {
+		httpmock.RegisterResponder(
+			"GET", "/default/attributes/somebucket",
+			httpmock.NewStringResponder(200, `{"name":"somebucket"}`),
+		)
+		for i := 0; i < 3; i++ {
+			attributes, err := cachingClient.GetBucketAttributes(ctx, "somebucket")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(attributes).To(Equal([]byte(`{"name":"somebucket"}`)))
+			// modifying the returned attributes does not alter the cache
+			attributes[0] = 'X'
+		}
+		Expect(httpmock.GetTotalCallCount()).To(Equal(1))
+	})
+
+	It("caches bucket session IDs", func(ctx SpecContext) {
+		httpmock.RegisterResponder(
+			"GET", "/_/buckets/somebucket/id",
+			httpmock.NewStringResponder(200, "42"),
+		)
+		for i := 0; i < 3; i++ {
+			Expect(cachingClient.AdminGetBucketSessionID(ctx, "somebucket")).To(Equal(42))
+		}
+		Expect(httpmock.GetTotalCallCount()).To(Equal(1))
+	})
+
+	It("caches 404 Not Found responses", func(ctx SpecContext) {
+		httpmock.RegisterResponder(
+			"GET", "/default/attributes/somebucket",
+			httpmock.NewStringResponder(404, ""),
+		)
+		for i := 0; i < 2; i++ {
+			_, err := cachingClient.GetBucketAttributes(ctx, "somebucket")
+			var bcErr *bucketclient.BucketClientError
+			Expect(errors.As(err, &bcErr)).To(BeTrue())
+			Expect(bcErr.StatusCode).To(Equal(404))
+		}
+		Expect(httpmock.GetTotalCallCount()).To(Equal(1))
+	})
+
+	It("does not cache other errors", func(ctx SpecContext) {
+		httpmock.RegisterResponder(
+			"GET", "/default/attributes/somebucket",
+			httpmock.NewStringResponder(400, ""),
+		)
+		for i := 0; i < 2; i++ {
+			_, err := cachingClient.GetBucketAttributes(ctx, "somebucket")
+			Expect(err).To(HaveOccurred())
+		}
+		Expect(httpmock.GetTotalCallCount()).To(Equal(2))
+	})
+
+	It("expires cached entries after their TTL", func(ctx SpecContext) {
+		var err error
+		cachingClient, err = bucketclient.NewCachingBucketClient(client,
+			bucketclient.CachingTTLOption(20*time.Millisecond))
+		Expect(err).ToNot(HaveOccurred())
+		httpmock.RegisterResponder(
+			"GET", "/default/attributes/somebucket",
+			httpmock.NewStringResponder(200, `{}`),
+		)
+		Expect(cachingClient.GetBucketAttributes(ctx, "somebucket")).To(Equal([]byte(`{}`)))
+		Expect(cachingClient.GetBucketAttributes(ctx, "somebucket")).To(Equal([]byte(`{}`)))
+		Expect(httpmock.GetTotalCallCount()).To(Equal(1))
+		time.Sleep(30 * time.Millisecond)
+		Expect(cachingClient.GetBucketAttributes(ctx, "somebucket")).To(Equal([]byte(`{}`)))
+		Expect(httpmock.GetTotalCallCount()).To(Equal(2))
+	})
+
+	It("evicts the least recently used entries", func(ctx SpecContext) {
+		var err error
+		cachingClient, err = bucketclient.NewCachingBucketClient(client,
+			bucketclient.CachingMaxEntriesOption(2))
+		Expect(err).ToNot(HaveOccurred())
+		for _, bucket := range []string{"bucket1", "bucket2", "bucket3"} {
+			httpmock.RegisterResponder(
+				"GET", "/default/attributes/"+bucket,
+				httpmock.NewStringResponder(200, `{}`),
+			)
+		}
+		for _, bucket := range []string{"bucket1", "bucket2", "bucket1", "bucket3", "bucket1", "bucket2"} {
+			_, err := cachingClient.GetBucketAttributes(ctx, bucket)
+			Expect(err).ToNot(HaveOccurred())
+		}
+		Expect(httpmock.GetCallCountInfo()).To(Equal(map[string]int{
+			"GET /default/attributes/bucket1": 1,
+			"GET /default/attributes/bucket2": 2,
+			"GET /default/attributes/bucket3": 1,
+		}))
+	})
+
+	It("invalidates entries on writes", func(ctx SpecContext) {
+		httpmock.RegisterResponder(
+			"GET", "/default/attributes/somebucket",
+			httpmock.NewStringResponder(200, `{}`),
+		)
+		httpmock.RegisterResponder(
+			"GET", "/_/buckets/somebucket/id",
+			httpmock.NewStringResponder(200, "42"),
+		)
+		httpmock.RegisterResponder(
+			"POST", "/default/attributes/somebucket",
+			httpmock.NewStringResponder(200, ""),
+		)
+		httpmock.RegisterResponder(
+			"POST", "/default/bucket/somebucket",
+			httpmock.NewStringResponder(200, ""),
+		)
+		httpmock.RegisterResponder(
+			"DELETE", "/default/bucket/somebucket",
+			httpmock.NewStringResponder(200, ""),
+		)
+		readAll := func() {
+			_, err := cachingClient.GetBucketAttributes(ctx, "somebucket")
+			Expect(err).ToNot(HaveOccurred())
+			_, err = cachingClient.AdminGetBucketSessionID(ctx, "somebucket")
+			Expect(err).ToNot(HaveOccurred())
+		}
+		readAll()
+		readAll()
+		Expect(cachingClient.PutBucketAttributes(ctx, "somebucket", []byte(`{}`))).To(Succeed())
+		readAll()
+		Expect(cachingClient.CreateBucket(ctx, "somebucket", []byte(`{}`))).To(Succeed())
+		readAll()
+		Expect(cachingClient.DeleteBucket(ctx, "somebucket")).To(Succeed())
+		readAll()
+		cachingClient.Invalidate("somebucket")
+		readAll()
+		Expect(httpmock.GetCallCountInfo()).To(Equal(map[string]int{
+			"GET /default/attributes/somebucket":  5,
+			"GET /_/buckets/somebucket/id":        4,
+			"POST /default/attributes/somebucket": 1,
+			"POST /default/bucket/somebucket":     1,
+			"DELETE /default/bucket/somebucket":   1,
+		}))
+	})
+
+	It("invalidates session IDs on AdminBucketRefreshCache", func(ctx SpecContext) {
+		httpmock.RegisterResponder(
+			"GET", "/_/buckets/somebucket/id",
+			httpmock.NewStringResponder(200, "42"),
+		)
+		httpmock.RegisterResponder(
+			"GET", "/_/buckets/somebucket/refreshCache",
+			httpmock.NewStringResponder(200, ""),
+		)
+		Expect(cachingClient.AdminGetBucketSessionID(ctx, "somebucket")).To(Equal(42))
+		Expect(cachingClient.AdminBucketRefreshCache(ctx, "somebucket")).To(Succeed())
+		Expect(cachingClient.AdminGetBucketSessionID(ctx, "somebucket")).To(Equal(42))
+		Expect(httpmock.GetCallCountInfo()["GET /_/buckets/somebucket/id"]).To(Equal(2))
+	})
+
+	It("invalidates session IDs on metastore entry writes", func(ctx SpecContext) {
+		httpmock.RegisterResponder(
+			"GET", "/_/buckets/somebucket/id",
+			httpmock.NewStringResponder(200, "42"),
+		)
+		httpmock.RegisterResponder(
+			"GET", "/default/metastore/db/somebucket",
+			httpmock.NewStringResponder(200, `{"name":"somebucket","version":1}`),
+		)
+		httpmock.RegisterResponder(
+			"POST", "/default/metastore/db/somebucket",
+			httpmock.NewStringResponder(200, ""),
+		)
+		httpmock.RegisterResponder(
+			"DELETE", "/default/metastore/db/somebucket",
+			httpmock.NewStringResponder(200, ""),
+		)
+		entry := bucketclient.MetastoreEntry{Name: "somebucket", RaftSessionID: 42}
+		Expect(cachingClient.AdminGetBucketSessionID(ctx, "somebucket")).To(Equal(42))
+		Expect(cachingClient.CreateMetastoreEntry(ctx, "somebucket", entry)).To(Succeed())
+		Expect(cachingClient.AdminGetBucketSessionID(ctx, "somebucket")).To(Equal(42))
+		Expect(cachingClient.CompareAndSwapMetastoreEntry(ctx, "somebucket", 1, entry)).To(Succeed())
+		Expect(cachingClient.AdminGetBucketSessionID(ctx, "somebucket")).To(Equal(42))
+		Expect(cachingClient.DeleteMetastoreEntry(ctx, "somebucket")).To(Succeed())
+		Expect(cachingClient.AdminGetBucketSessionID(ctx, "somebucket")).To(Equal(42))
+		Expect(httpmock.GetCallCountInfo()["GET /_/buckets/somebucket/id"]).To(Equal(4))
+	})
+
+	It("does not cache responses fetched across an invalidation of their bucket", func(ctx SpecContext) {
+		for _, bucket := range []string{"bucket1", "bucket2"} {
+			httpmock.RegisterResponder(
+				"GET", "/default/attributes/"+bucket,
+				func(req *http.Request) (*http.Response, error) {
+					// invalidate bucket2 while the request is in flight
+					cachingClient.Invalidate("bucket2")
+					return httpmock.NewStringResponse(200, `{}`), nil
+				},
+			)
+		}
+		for _, bucket := range []string{"bucket1", "bucket1", "bucket2", "bucket2"} {
+			_, err := cachingClient.GetBucketAttributes(ctx, bucket)
+			Expect(err).ToNot(HaveOccurred())
+		}
+		Expect(httpmock.GetCallCountInfo()).To(Equal(map[string]int{
+			"GET /default/attributes/bucket1": 1,
+			"GET /default/attributes/bucket2": 2,
+		}))
+	})
+
+	It("passes through other methods", func(ctx SpecContext) {
+		httpmock.RegisterResponder(
+			"GET", "/default/bucket/somebucket?listingType=Basic",
+			httpmock.NewStringResponder(200, `[{"key":"foo","value":"bar"}]`),
+		)
+		Expect(cachingClient.ListBasic(ctx, "somebucket")).To(Equal(
+			&bucketclient.ListBasicResponse{{Key: "foo", Value: "bar"}}))
+	})
+})
@@ -0,0 +1,258 @@
+package bucketclient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// ErrCircuitBreakerOpen is wrapped in the error returned without
+// sending the request when the circuit breaker of the endpoint is open
+var ErrCircuitBreakerOpen = errors.New("circuit breaker open")
+
+type CircuitBreakerState int
+
+const (
+	// CircuitBreakerClosed lets all requests through
+	CircuitBreakerClosed CircuitBreakerState = iota
+	// CircuitBreakerOpen fails all requests fast
+	CircuitBreakerOpen
+	// CircuitBreakerHalfOpen lets a limited number of trial
+	// requests through to probe whether the endpoint recovered
+	CircuitBreakerHalfOpen
+)
+
+func (state CircuitBreakerState) String() string {
+	switch state {
+	case CircuitBreakerClosed:
+		return "closed"
+	case CircuitBreakerOpen:
+		return "open"
+	case CircuitBreakerHalfOpen:
+		return "half-open"
+	default:
+		return fmt.Sprintf("CircuitBreakerState(%d)", int(state))
+	}
+}
+
+type CircuitBreakerOption func(*circuitBreakerOptionSet)
+
+// CircuitBreakerFailureThresholdOption sets the number of consecutive
+// failures after which the circuit breaker opens (default 5)
+func CircuitBreakerFailureThresholdOption(threshold int) CircuitBreakerOption {
+	return func(opts *circuitBreakerOptionSet) {
+		opts.failureThreshold = max(threshold, 1)
+	}
+}
+
+// CircuitBreakerOpenDurationOption sets how long the circuit breaker
+// stays open before letting trial requests through (default 10s)
+func CircuitBreakerOpenDurationOption(duration time.Duration) CircuitBreakerOption {
+	return func(opts *circuitBreakerOptionSet) {
+		opts.openDuration = max(duration, 0)
+	}
+}
+
+// CircuitBreakerHalfOpenRequestsOption sets the number of trial
+// requests let through when half-open, which must all succeed to close
+// the circuit breaker again (default 1)
+func CircuitBreakerHalfOpenRequestsOption(requests int) CircuitBreakerOption {
+	return func(opts *circuitBreakerOptionSet) {
+		opts.halfOpenRequests = max(requests, 1)
+	}
+}
+
+// CircuitBreakerOnStateChangeOption sets a function called on each
+// state change of the circuit breaker of an endpoint
+func CircuitBreakerOnStateChangeOption(
+	onStateChange func(endpoint string, from CircuitBreakerState, to CircuitBreakerState)) CircuitBreakerOption {
+	return func(opts *circuitBreakerOptionSet) {
+		opts.onStateChange = onStateChange
+	}
+}
+
+type circuitBreakerOptionSet struct {
+	failureThreshold int
+	openDuration     time.Duration
+	halfOpenRequests int
+	onStateChange    func(endpoint string, from CircuitBreakerState, to CircuitBreakerState)
+}
+
+// BucketClientCircuitBreakerOption enables a circuit breaker per
+// bucketd endpoint, which opens after consecutive failures of requests
+// to the endpoint. While open, requests to the endpoint fail fast with
+// an error wrapping ErrCircuitBreakerOpen.
+//
+// Only transport errors and 5xx responses count as failures: other
+// error responses show that the endpoint is healthy, and requests
+// interrupted by the end of their context are ignored.
+func BucketClientCircuitBreakerOption(opts ...CircuitBreakerOption) BucketClientOption {
+	options := circuitBreakerOptionSet{
+		failureThreshold: 5,
+		openDuration:     10 * time.Second,
+		halfOpenRequests: 1,
+	}
+	for _, opt := range opts {
+		opt(&options)
+	}
+	return func(client *BucketClient) {
+		client.circuitBreakers = &circuitBreakerSet{
+			options:  options,
+			breakers: map[string]*circuitBreaker{},
+		}
+	}
+}
+
+// CircuitBreakerState returns the state of the circuit breaker of the
+// given endpoint, which is closed if circuit breakers are not enabled.
+func (client *BucketClient) CircuitBreakerState(endpoint string) CircuitBreakerState {
+	if client.circuitBreakers == nil {
+		return CircuitBreakerClosed
+	}
+	return client.circuitBreakers.get(endpoint).currentState()
+}
+
+type circuitBreakerSet struct {
+	options circuitBreakerOptionSet
+
+	mutex    sync.Mutex
+	breakers map[string]*circuitBreaker
+}
+
+func (set *circuitBreakerSet) get(endpoint string) *circuitBreaker {
+	set.mutex.Lock()
+	defer set.mutex.Unlock()
+	breaker, found := set.breakers[endpoint]
+	if !found {
+		breaker = &circuitBreaker{endpoint: endpoint, options: &set.options}
+		set.breakers[endpoint] = breaker
+	}
+	return breaker
+}
+
+type circuitBreaker struct {
+	endpoint string
+	options  *circuitBreakerOptionSet
+
+	mutex               sync.Mutex
+	state               CircuitBreakerState
+	consecutiveFailures int
+	openedAt            time.Time
+	trialsInFlight      int
+	trialSuccesses      int
+}
+
+type requestOutcome int
+
+const (
+	requestSucceeded requestOutcome = iota
+	requestFailed
+	requestIgnored
+)
+
+// requestOutcomeOf classifies the result of a request for the circuit
+// breaker
+func requestOutcomeOf(ctx context.Context, err error) requestOutcome {
+	if err == nil {
+		return requestSucceeded
+	}
+	if ctx.Err() != nil {
+		return requestIgnored
+	}
+	if isRetryableError(err) {
+		return requestFailed
+	}
+	return requestSucceeded
+}
+
+// allow returns whether a request may be sent, and whether it is a
+// trial request of the half-open state
+func (cb *circuitBreaker) allow() (allowed bool, trial bool) {
+	cb.mutex.Lock()
+	var transition func()
+	defer func() {
+		cb.mutex.Unlock()
+		if transition != nil {
+			transition()
+		}
+	}()
+	if cb.state == CircuitBreakerOpen {
+		if time.Since(cb.openedAt) < cb.options.openDuration {
+			return false, false
+		}
+		transition = cb.setState(CircuitBreakerHalfOpen)
+		cb.trialsInFlight = 0
+		cb.trialSuccesses = 0
+	}
+	if cb.state == CircuitBreakerHalfOpen {
+		if cb.trialsInFlight+cb.trialSuccesses >= cb.options.halfOpenRequests {
+			return false, false
+		}
+		cb.trialsInFlight += 1
+		return true, true
+	}
+	return true, false
+}
+
+// record updates the circuit breaker with the outcome of an allowed
+// request
+func (cb *circuitBreaker) record(trial bool, outcome requestOutcome) {
+	cb.mutex.Lock()
+	var transition func()
+	defer func() {
+		cb.mutex.Unlock()
+		if transition != nil {
+			transition()
+		}
+	}()
+	if trial && cb.state == CircuitBreakerHalfOpen {
+		cb.trialsInFlight -= 1
+		switch outcome {
+		case requestSucceeded:
+			cb.trialSuccesses += 1
+			if cb.trialSuccesses >= cb.options.halfOpenRequests {
+				cb.consecutiveFailures = 0
+				transition = cb.setState(CircuitBreakerClosed)
+			}
+		case requestFailed:
+			cb.openedAt = time.Now()
+			transition = cb.setState(CircuitBreakerOpen)
+		}
+		return
+	}
+	if cb.state != CircuitBreakerClosed {
+		// outcome of a request allowed before the last transition
+		return
+	}
+	switch outcome {
+	case requestSucceeded:
+		cb.consecutiveFailures = 0
+	case requestFailed:
+		cb.consecutiveFailures += 1
+		if cb.consecutiveFailures >= cb.options.failureThreshold {
+			cb.openedAt = time.Now()
+			transition = cb.setState(CircuitBreakerOpen)
+		}
+	}
+}
+
+// setState changes the state, returning a function notifying the
+// change to be called once the mutex is released
+func (cb *circuitBreaker) setState(state CircuitBreakerState) func() {
+	from := cb.state
+	cb.state = state
+	if cb.options.onStateChange == nil || from == state {
+		return nil
+	}
+	return func() {
+		cb.options.onStateChange(cb.endpoint, from, state)
+	}
+}
+
+func (cb *circuitBreaker) currentState() CircuitBreakerState {
+	cb.mutex.Lock()
+	defer cb.mutex.Unlock()
+	return cb.state
+}
@@ -0,0 +1,197 @@
+package bucketclient_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/jarcoal/httpmock"
+
+	"github.com/scality/bucketclient/go"
+)
+
+var _ = Describe("BucketClientCircuitBreakerOption", func() {
+	var (
+		breakerClient *bucketclient.BucketClient
+		mutex         sync.Mutex
+		transitions   []string
+		status        int
+	)
+
+	BeforeEach(func() {
+		// responders of cancelled requests may still be running
+		mutex.Lock()
+		transitions = nil
+		status = 500
+		mutex.Unlock()
+		breakerClient = bucketclient.New("http://localhost:9000",
+			bucketclient.BucketClientCircuitBreakerOption(
+				bucketclient.CircuitBreakerFailureThresholdOption(3),
+				bucketclient.CircuitBreakerOpenDurationOption(50*time.Millisecond),
+				bucketclient.CircuitBreakerOnStateChangeOption(
+					func(endpoint string, from, to bucketclient.CircuitBreakerState) {
+						mutex.Lock()
+						defer mutex.Unlock()
+						transitions = append(transitions,
+							fmt.Sprintf("%s: %s -> %s", endpoint, from, to))
+					})))
+		httpmock.RegisterResponder(
+			"GET", "/default/attributes/somebucket",
+			func(req *http.Request) (*http.Response, error) {
+				mutex.Lock()
+				defer mutex.Unlock()
+				return httpmock.NewStringResponse(status, `{}`), nil
+			},
+		)
+	})
+
+	setStatus := func(newStatus int) {
+		mutex.Lock()
+		defer mutex.Unlock()
+		status = newStatus
+	}
+	getTransitions := func() []string {
+		mutex.Lock()
+		defer mutex.Unlock()
+		return append([]string(nil), transitions...)
+	}
+	expectOpenError := func(err error) {
+		Expect(errors.Is(err, bucketclient.ErrCircuitBreakerOpen)).To(BeTrue())
+		var bcErr *bucketclient.BucketClientError
+		Expect(errors.As(err, &bcErr)).To(BeTrue())
+		Expect(bcErr.ApiMethod).To(Equal("GetBucketAttributes"))
+		Expect(bcErr.Endpoint).To(Equal("http://localhost:9000"))
+	}
+
+	It("opens after consecutive 5xx failures and fails fast", func(ctx SpecContext) {
+		for i := 0; i < 3; i++ {
+			_, err := breakerClient.GetBucketAttributes(ctx, "somebucket")
+			Expect(err).To(HaveOccurred())
+			Expect(errors.Is(err, bucketclient.ErrCircuitBreakerOpen)).To(BeFalse())
+		}
+		Expect(breakerClient.CircuitBreakerState("http://localhost:9000")).To(
+			Equal(bucketclient.CircuitBreakerOpen))
+		_, err := breakerClient.GetBucketAttributes(ctx, "somebucket")
+		expectOpenError(err)
+		Expect(httpmock.GetTotalCallCount()).To(Equal(3))
+		Expect(getTransitions()).To(Equal([]string{
+			"http://localhost:9000: closed -> open",
+		}))
+	})
+
+	It("does not count 4xx responses as failures", func(ctx SpecContext) {
+		setStatus(404)
+		for i := 0; i < 5; i++ {
+			_, err := breakerClient.GetBucketAttributes(ctx, "somebucket")
+			Expect(errors.Is(err, bucketclient.ErrCircuitBreakerOpen)).To(BeFalse())
+		}
+		Expect(httpmock.GetTotalCallCount()).To(Equal(5))
+		Expect(breakerClient.CircuitBreakerState("http://localhost:9000")).To(
+			Equal(bucketclient.CircuitBreakerClosed))
+	})
+
+	It("resets the failure count after a success", func(ctx SpecContext) {
+		for _, s := range []int{500, 500, 200, 500, 500, 200} {
+			setStatus(s)
+			_, _ = breakerClient.GetBucketAttributes(ctx, "somebucket")
+		}
+		Expect(breakerClient.CircuitBreakerState("http://localhost:9000")).To(
+			Equal(bucketclient.CircuitBreakerClosed))
+	})
+
+	It("does not count cancelled requests as failures", func(ctx SpecContext) {
+		canceledCtx, cancel := context.WithCancel(ctx)
+		cancel()
+		for i := 0; i < 5; i++ {
+			_, err := breakerClient.GetBucketAttributes(canceledCtx, "somebucket")
+			Expect(errors.Is(err, bucketclient.ErrCircuitBreakerOpen)).To(BeFalse())
+		}
+		Expect(breakerClient.CircuitBreakerState("http://localhost:9000")).To(
+			Equal(bucketclient.CircuitBreakerClosed))
+	})
+
+	It("closes again after a successful trial request", func(ctx SpecContext) {
+		for i := 0; i < 3; i++ {
+			_, _ = breakerClient.GetBucketAttributes(ctx, "somebucket")
+		}
+		time.Sleep(60 * time.Millisecond)
+		setStatus(200)
+		Expect(breakerClient.GetBucketAttributes(ctx, "somebucket")).To(Equal([]byte(`{}`)))
+		Expect(breakerClient.CircuitBreakerState("http://localhost:9000")).To(
+			Equal(bucketclient.CircuitBreakerClosed))
+		Expect(getTransitions()).To(Equal([]string{
+			"http://localhost:9000: closed -> open",
+			"http://localhost:9000: open -> half-open",
+			"http://localhost:9000: half-open -> closed",
+		}))
+	})
+
+	It("opens again after a failed trial request", func(ctx SpecContext) {
+		for i := 0; i < 3; i++ {
+			_, _ = breakerClient.GetBucketAttributes(ctx, "somebucket")
+		}
+		time.Sleep(60 * time.Millisecond)
+		_, err := breakerClient.GetBucketAttributes(ctx, "somebucket")
+		Expect(errors.Is(err, bucketclient.ErrCircuitBreakerOpen)).To(BeFalse())
+		_, err = breakerClient.GetBucketAttributes(ctx, "somebucket")
+		expectOpenError(err)
+		Expect(httpmock.GetTotalCallCount()).To(Equal(4))
+		Expect(getTransitions()).To(Equal([]string{
+			"http://localhost:9000: closed -> open",
+			"http://localhost:9000: open -> half-open",
+			"http://localhost:9000: half-open -> open",
+		}))
+	})
+
+	It("lets only a limited number of trial requests through", func(ctx SpecContext) {
+		release := make(chan struct{})
+		for i := 0; i < 3; i++ {
+			_, _ = breakerClient.GetBucketAttributes(ctx, "somebucket")
+		}
+		httpmock.RegisterResponder(
+			"GET", "/default/attributes/somebucket",
+			func(req *http.Request) (*http.Response, error) {
+				<-release
+				return httpmock.NewStringResponse(200, `{}`), nil
+			},
+		)
+		time.Sleep(60 * time.Millisecond)
+		trialDone := make(chan error)
+		go func() {
+			_, err := breakerClient.GetBucketAttributes(ctx, "somebucket")
+			trialDone <- err
+		}()
+		Eventually(httpmock.GetTotalCallCount).Should(Equal(4))
+		_, err := breakerClient.GetBucketAttributes(ctx, "somebucket")
+		expectOpenError(err)
+		close(release)
+		Expect(<-trialDone).ToNot(HaveOccurred())
+		Expect(breakerClient.GetBucketAttributes(ctx, "somebucket")).To(Equal([]byte(`{}`)))
+	})
+
+	It("lets hedged reads fail over to another endpoint", func(ctx SpecContext) {
+		breakerClient = bucketclient.New("http://localhost:9000",
+			bucketclient.BucketClientHedgingOption([]string{"http://localhost:9001"},
+				bucketclient.HedgingDelayOption(time.Hour)),
+			bucketclient.BucketClientCircuitBreakerOption(
+				bucketclient.CircuitBreakerFailureThresholdOption(1)))
+		httpmock.RegisterResponder(
+			"GET", "http://localhost:9001/default/attributes/somebucket",
+			httpmock.NewStringResponder(200, `{"secondary":true}`),
+		)
+		for i := 0; i < 2; i++ {
+			Expect(breakerClient.GetBucketAttributes(ctx, "somebucket")).To(
+				Equal([]byte(`{"secondary":true}`)))
+		}
+		Expect(httpmock.GetCallCountInfo()).To(Equal(map[string]int{
+			"GET /default/attributes/somebucket":                      1,
+			"GET http://localhost:9001/default/attributes/somebucket": 2,
+		}))
+	})
+})
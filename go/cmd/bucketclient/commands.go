@@ -0,0 +1,329 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/scality/bucketclient/go"
+)
+
+// errUsage is returned for commands called with invalid arguments
+var errUsage = errors.New("usage")
+
+// errExit is returned by the exit command
+var errExit = errors.New("exit")
+
+// command is a command of the shell, called with positional arguments
+// followed by options given as "name=value", or as "name" alone for
+// boolean options
+type command struct {
+	name string
+	// args describes the positional arguments, e.g. "<bucket> <key>"
+	args    string
+	help    string
+	minArgs int
+	maxArgs int
+	// options lists the names of the options accepted
+	options []string
+	// pageOption names the option limiting the number of entries of
+	// listings, set by the interactive shell to page through them
+	pageOption string
+	// destructive commands delete or lock data: the interactive shell
+	// never passes them the current bucket, it must be given
+	destructive bool
+	run         func(ctx context.Context, sh *shell, args []string, opts commandOptions) (result, error)
+}
+
+func (cmd *command) usage() string {
+	usage := cmd.name
+	if cmd.args != "" {
+		usage += " " + cmd.args
+	}
+	for _, option := range cmd.options {
+		usage += fmt.Sprintf(" [%s=]", option)
+	}
+	return usage
+}
+
+// commandOptions holds the options given to a command
+type commandOptions map[string]string
+
+func (opts commandOptions) has(name string) bool {
+	_, found := opts[name]
+	return found
+}
+
+func (opts commandOptions) string(name string) (string, bool) {
+	value, found := opts[name]
+	return value, found
+}
+
+func (opts commandOptions) int(name string) (int, bool, error) {
+	value, found := opts[name]
+	if !found {
+		return 0, false, nil
+	}
+	parsed, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, false, fmt.Errorf("invalid value %q of option %s: expected an integer", value, name)
+	}
+	return parsed, true, nil
+}
+
+func (opts commandOptions) bool(name string) (bool, error) {
+	value, found := opts[name]
+	if !found {
+		return false, nil
+	}
+	parsed, err := strconv.ParseBool(value)
+	if err != nil {
+		return false, fmt.Errorf("invalid value %q of option %s: expected a boolean", value, name)
+	}
+	return parsed, nil
+}
+
+func (opts commandOptions) time(name string) (time.Time, bool, error) {
+	value, found := opts[name]
+	if !found {
+		return time.Time{}, false, nil
+	}
+	parsed, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return time.Time{}, false, fmt.Errorf("invalid value %q of option %s: expected an RFC 3339 date",
+			value, name)
+	}
+	return parsed, true, nil
+}
+
+// shell runs commands against bucketd and prints their results
+type shell struct {
+	client   bucketclient.BucketClientAPI
+	commands []*command
+	out      io.Writer
+	errOut   io.Writer
+	format   outputFormat
+	// timeout limits the duration of each command, if not zero
+	timeout time.Duration
+	// timing prints the duration of each command to errOut
+	timing bool
+	// stdin is read by commands given "-" as file name
+	stdin io.Reader
+	// pretty expands the JSON values embedded in strings of JSON
+	// output, like object metadata in listings
+	pretty bool
+}
+
+func newShell(client bucketclient.BucketClientAPI, out io.Writer, errOut io.Writer,
+	format outputFormat) *shell {
+	sh := &shell{
+		client: client,
+		out:    out,
+		errOut: errOut,
+		format: format,
+	}
+	sh.commands = slices.Concat(
+		dataCommands,
+		listingCommands,
+		metastoreCommands,
+		adminCommands,
+		[]*command{
+			{
+				name:    "help",
+				args:    "[command]",
+				help:    "describe commands",
+				maxArgs: 1,
+				run:     runHelp,
+			},
+			{
+				name: "exit",
+				help: "exit the shell",
+				run: func(context.Context, *shell, []string, commandOptions) (result, error) {
+					return result{}, errExit
+				},
+			},
+		},
+	)
+	return sh
+}
+
+func (sh *shell) findCommand(name string) *command {
+	for _, cmd := range sh.commands {
+		if cmd.name == name {
+			return cmd
+		}
+	}
+	return nil
+}
+
+// runLine runs the command on a line of input, ignoring empty lines and
+// comments starting with "#"
+func (sh *shell) runLine(ctx context.Context, line string) error {
+	line = strings.TrimSpace(line)
+	if line == "" || strings.HasPrefix(line, "#") {
+		return nil
+	}
+	words, err := splitCommandLine(line)
+	if err != nil {
+		return err
+	}
+	return sh.runCommand(ctx, words)
+}
+
+// runCommand runs the command named by the first word, with the
+// following words as arguments, and prints its result
+func (sh *shell) runCommand(ctx context.Context, words []string) error {
+	cmd, args, opts, err := sh.parseCommand(words)
+	if err != nil {
+		return err
+	}
+	res, err := sh.execute(ctx, cmd, args, opts)
+	if err != nil {
+		return err
+	}
+	return sh.print(res)
+}
+
+// parseCommand finds the command named by the first word and checks
+// the arguments and options given by the following words
+func (sh *shell) parseCommand(words []string) (*command, []string, commandOptions, error) {
+	cmd := sh.findCommand(words[0])
+	if cmd == nil {
+		return nil, nil, nil, fmt.Errorf("command not found: %s (try \"help\")", words[0])
+	}
+	args, opts := parseArguments(cmd, words[1:])
+	if len(args) < cmd.minArgs || len(args) > cmd.maxArgs {
+		return nil, nil, nil, fmt.Errorf("%w: %s", errUsage, cmd.usage())
+	}
+	return cmd, args, opts, nil
+}
+
+// execute runs a command within the timeout of the shell
+func (sh *shell) execute(ctx context.Context, cmd *command, args []string,
+	opts commandOptions) (result, error) {
+	if sh.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, sh.timeout)
+		defer cancel()
+	}
+	start := time.Now()
+	res, err := cmd.run(ctx, sh, args, opts)
+	if sh.timing && !errors.Is(err, errExit) {
+		fmt.Fprintf(sh.errOut, "time: %s\n", time.Since(start).Round(time.Microsecond))
+	}
+	return res, err
+}
+
+// print prints the result of a command in the output format of the
+// shell
+func (sh *shell) print(res result) error {
+	if sh.pretty && res.data != nil {
+		expanded, err := expandJSONStrings(res.data)
+		if err != nil {
+			return err
+		}
+		res.data = expanded
+	}
+	return printResult(sh.out, sh.format, res)
+}
+
+// parseArguments splits the words following a command name into
+// positional arguments and the options it accepts
+func parseArguments(cmd *command, words []string) ([]string, commandOptions) {
+	var args []string
+	opts := commandOptions{}
+	for _, word := range words {
+		name, value, hasValue := strings.Cut(word, "=")
+		if !slices.Contains(cmd.options, name) {
+			args = append(args, word)
+		} else if hasValue {
+			opts[name] = value
+		} else {
+			opts[name] = "true"
+		}
+	}
+	return args, opts
+}
+
+// splitCommandLine splits a line into words separated by spaces.
+// Single quotes preserve their content as is, double quotes and
+// backslashes allow escaping the following character, e.g. to pass
+// JSON: putbucketattr mybucket '{"owner": "abc"}'. Outside of quotes,
+// "\0" stands for the null character separating the keys of objects
+// and their version IDs, as printed in tables.
+func splitCommandLine(line string) ([]string, error) {
+	var words []string
+	var word strings.Builder
+	inWord := false
+	var quote rune
+	escaped := false
+	for _, c := range line {
+		switch {
+		case escaped && c == '0' && quote == 0:
+			word.WriteRune(0)
+			escaped = false
+		case escaped:
+			word.WriteRune(c)
+			escaped = false
+		case quote == '\'':
+			if c == '\'' {
+				quote = 0
+			} else {
+				word.WriteRune(c)
+			}
+		case c == '\\':
+			escaped = true
+			inWord = true
+		case quote == '"':
+			if c == '"' {
+				quote = 0
+			} else {
+				word.WriteRune(c)
+			}
+		case c == '\'' || c == '"':
+			quote = c
+			inWord = true
+		case c == ' ' || c == '\t':
+			if inWord {
+				words = append(words, word.String())
+				word.Reset()
+				inWord = false
+			}
+		default:
+			word.WriteRune(c)
+			inWord = true
+		}
+	}
+	if quote != 0 {
+		return nil, fmt.Errorf("unterminated %c quote", quote)
+	}
+	if escaped {
+		return nil, errors.New("trailing backslash")
+	}
+	if inWord {
+		words = append(words, word.String())
+	}
+	return words, nil
+}
+
+func runHelp(ctx context.Context, sh *shell, args []string, opts commandOptions) (result, error) {
+	var help strings.Builder
+	for _, cmd := range sh.commands {
+		if len(args) == 1 && cmd.name != args[0] {
+			continue
+		}
+		fmt.Fprintf(&help, "%s\n    %s\n", cmd.usage(), cmd.help)
+	}
+	if help.Len() == 0 {
+		return result{}, fmt.Errorf("command not found: %s", args[0])
+	}
+	// help is meant to be read, print it as text whatever the
+	// output format
+	_, err := io.WriteString(sh.out, help.String())
+	return result{}, err
+}
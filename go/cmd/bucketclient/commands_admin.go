@@ -0,0 +1,258 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/scality/bucketclient/go"
+)
+
+var adminCommands = []*command{
+	{
+		name:    "healthcheck",
+		help:    "check the health of bucketd",
+		maxArgs: 0,
+		run:     runHealthcheck,
+	},
+	{
+		name:    "refreshcache",
+		args:    "<bucket>",
+		help:    "make bucketd reload the attributes of a bucket",
+		minArgs: 1,
+		maxArgs: 1,
+		run:     runRefreshCache,
+	},
+	{
+		name:    "getaccessmode",
+		args:    "<bucket>",
+		help:    "get the access mode of a bucket",
+		minArgs: 1,
+		maxArgs: 1,
+		run:     runGetAccessMode,
+	},
+	{
+		name:        "setaccessmode",
+		args:        "<bucket> <read-write|read-only>",
+		help:        "set the access mode of a bucket",
+		minArgs:     2,
+		maxArgs:     2,
+		destructive: true,
+		run:         runSetAccessMode,
+	},
+	{
+		name:    "getbucketsession",
+		args:    "<bucket>",
+		help:    "get the raft session hosting a bucket",
+		minArgs: 1,
+		maxArgs: 1,
+		run:     runGetBucketSession,
+	},
+	{
+		name: "sessions",
+		help: "list the raft sessions and their members",
+		run:  runSessions,
+	},
+	{
+		name:    "session",
+		args:    "<sessionId>",
+		help:    "get the members of a raft session",
+		minArgs: 1,
+		maxArgs: 1,
+		run:     runSession,
+	},
+	{
+		name:    "sessionleader",
+		args:    "<sessionId>",
+		help:    "get the leader of a raft session",
+		minArgs: 1,
+		maxArgs: 1,
+		run:     runSessionLeader,
+	},
+	{
+		name:    "sessionlog",
+		args:    "<sessionId>",
+		help:    "get records of the oplog of a raft session, from the leader if set (default begin=1 limit=100)",
+		minArgs: 1,
+		maxArgs: 1,
+		options: []string{"begin", "limit", "leader"},
+		run:     runSessionLog,
+	},
+}
+
+// dbMethodNames names the methods of raft log records in tables
+var dbMethodNames = map[bucketclient.DBMethodType]string{
+	bucketclient.DBMethodCreate:        "create",
+	bucketclient.DBMethodDelete:        "delete",
+	bucketclient.DBMethodGet:           "get",
+	bucketclient.DBMethodPut:           "put",
+	bucketclient.DBMethodList:          "list",
+	bucketclient.DBMethodDel:           "del",
+	bucketclient.DBMethodGetAttributes: "getAttributes",
+	bucketclient.DBMethodPutAttributes: "putAttributes",
+	bucketclient.DBMethodBatch:         "batch",
+	bucketclient.DBMethodNoop:          "noop",
+}
+
+func dbMethodName(method bucketclient.DBMethodType) string {
+	if name, found := dbMethodNames[method]; found {
+		return name
+	}
+	return fmt.Sprint(int(method))
+}
+
+func parseSessionId(sessionId string) (int, error) {
+	id, err := strconv.Atoi(sessionId)
+	if err != nil {
+		return 0, fmt.Errorf("invalid raft session ID %q: expected an integer", sessionId)
+	}
+	return id, nil
+}
+
+func membersTable(sessions ...bucketclient.SessionInfo) *table {
+	membersTable := &table{header: []string{"SESSION", "ID", "NAME", "HOST", "PORT", "ADMIN PORT"}}
+	for _, session := range sessions {
+		for _, member := range session.RaftMembers {
+			membersTable.addRow(fmt.Sprint(session.ID), fmt.Sprint(member.ID), member.Name,
+				member.Host, fmt.Sprint(member.Port), fmt.Sprint(member.AdminPort))
+		}
+	}
+	return membersTable
+}
+
+func runHealthcheck(ctx context.Context, sh *shell, args []string, opts commandOptions) (result, error) {
+	responseBody, err := sh.client.Request(ctx, "Healthcheck", "GET", "/_/healthcheck")
+	if err != nil {
+		return result{}, err
+	}
+	res := result{message: "bucketd is healthy"}
+	if len(responseBody) > 0 {
+		res.data = json.RawMessage(responseBody)
+	}
+	return res, nil
+}
+
+func runRefreshCache(ctx context.Context, sh *shell, args []string, opts commandOptions) (result, error) {
+	if err := sh.client.AdminBucketRefreshCache(ctx, args[0]); err != nil {
+		return result{}, err
+	}
+	return result{message: fmt.Sprintf("cache of bucket %s refreshed", args[0])}, nil
+}
+
+func runGetAccessMode(ctx context.Context, sh *shell, args []string, opts commandOptions) (result, error) {
+	accessMode, err := sh.client.AdminGetBucketAccessMode(ctx, args[0])
+	if err != nil {
+		return result{}, err
+	}
+	data := struct {
+		AccessMode bucketclient.BucketAccessMode `json:"accessMode"`
+	}{accessMode}
+	return result{data: data, message: string(accessMode)}, nil
+}
+
+func runSetAccessMode(ctx context.Context, sh *shell, args []string, opts commandOptions) (result, error) {
+	accessMode := bucketclient.BucketAccessMode(args[1])
+	switch accessMode {
+	case bucketclient.BucketAccessModeReadWrite, bucketclient.BucketAccessModeReadOnly:
+	default:
+		return result{}, fmt.Errorf("invalid access mode %q, expected %q or %q", args[1],
+			bucketclient.BucketAccessModeReadWrite, bucketclient.BucketAccessModeReadOnly)
+	}
+	if err := sh.client.AdminSetBucketAccessMode(ctx, args[0], accessMode); err != nil {
+		return result{}, err
+	}
+	return result{message: fmt.Sprintf("bucket %s is now %s", args[0], accessMode)}, nil
+}
+
+func runGetBucketSession(ctx context.Context, sh *shell, args []string, opts commandOptions) (result, error) {
+	sessionId, err := sh.client.AdminGetBucketSessionID(ctx, args[0])
+	if err != nil {
+		return result{}, err
+	}
+	data := struct {
+		SessionID int `json:"sessionId"`
+	}{sessionId}
+	return result{data: data, message: fmt.Sprint(sessionId)}, nil
+}
+
+func runSessions(ctx context.Context, sh *shell, args []string, opts commandOptions) (result, error) {
+	sessions, err := sh.client.AdminGetAllSessionsInfo(ctx)
+	if err != nil {
+		return result{}, err
+	}
+	return result{data: sessions, table: membersTable(sessions...)}, nil
+}
+
+func runSession(ctx context.Context, sh *shell, args []string, opts commandOptions) (result, error) {
+	sessionId, err := parseSessionId(args[0])
+	if err != nil {
+		return result{}, err
+	}
+	session, err := sh.client.AdminGetSessionInfo(ctx, sessionId)
+	if err != nil {
+		return result{}, err
+	}
+	return result{data: session, table: membersTable(*session)}, nil
+}
+
+func runSessionLeader(ctx context.Context, sh *shell, args []string, opts commandOptions) (result, error) {
+	sessionId, err := parseSessionId(args[0])
+	if err != nil {
+		return result{}, err
+	}
+	leader, err := sh.client.AdminGetSessionLeader(ctx, sessionId)
+	if err != nil {
+		return result{}, err
+	}
+	return result{data: leader, table: membersTable(bucketclient.SessionInfo{
+		ID:          sessionId,
+		RaftMembers: []bucketclient.MemberInfo{*leader},
+	})}, nil
+}
+
+func runSessionLog(ctx context.Context, sh *shell, args []string, opts commandOptions) (result, error) {
+	sessionId, err := parseSessionId(args[0])
+	if err != nil {
+		return result{}, err
+	}
+	begin, hasBegin, err := opts.int("begin")
+	if err != nil {
+		return result{}, err
+	}
+	if !hasBegin {
+		begin = 1
+	}
+	limit, hasLimit, err := opts.int("limit")
+	if err != nil {
+		return result{}, err
+	}
+	if !hasLimit {
+		limit = 100
+	}
+	leader, err := opts.bool("leader")
+	if err != nil {
+		return result{}, err
+	}
+	response, err := sh.client.AdminGetSessionLog(ctx, sessionId, int64(begin), limit, leader)
+	if err != nil {
+		return result{}, err
+	}
+	logTable := &table{header: []string{"BUCKET", "METHOD", "TIMESTAMP", "ENTRIES"}}
+	for _, record := range response.Log {
+		var entries []string
+		for _, entry := range record.Entries {
+			if entry.Type != "" {
+				entries = append(entries, fmt.Sprintf("%s %s", entry.Type, entry.Key))
+			} else {
+				entries = append(entries, entry.Key)
+			}
+		}
+		logTable.addRow(record.Bucket, dbMethodName(record.DBMethod), record.Timestamp,
+			strings.Join(entries, ", "))
+	}
+	logTable.footer = fmt.Sprintf("(start=%d cseq=%d prune=%d)",
+		response.Info.Start, response.Info.CSeq, response.Info.Prune)
+	return result{data: response, table: logTable}, nil
+}
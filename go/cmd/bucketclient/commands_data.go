@@ -0,0 +1,351 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/scality/bucketclient/go"
+)
+
+var dataCommands = []*command{
+	{
+		name:    "createbucket",
+		args:    "<bucket> <attributes>",
+		help:    "create a bucket with the given JSON attributes, on a raft session if given",
+		minArgs: 2,
+		maxArgs: 2,
+		options: []string{"session", "idempotent"},
+		run:     runCreateBucket,
+	},
+	{
+		name:        "deletebucket",
+		args:        "<bucket>",
+		help:        "delete a bucket, only if its attributes have the given uid if set",
+		minArgs:     1,
+		maxArgs:     1,
+		options:     []string{"expectedUid", "idempotent"},
+		destructive: true,
+		run:         runDeleteBucket,
+	},
+	{
+		name:    "getbucketattr",
+		args:    "<bucket>",
+		help:    "get the attributes of a bucket",
+		minArgs: 1,
+		maxArgs: 1,
+		run:     runGetBucketAttributes,
+	},
+	{
+		name: "putbucketattr",
+		args: "<bucket> <attributes>",
+		help: "replace the attributes of a bucket, given as JSON or as " +
+			"\"name=value;name=value\"",
+		minArgs: 2,
+		maxArgs: 2,
+		run:     runPutBucketAttributes,
+	},
+	{
+		name:    "getobject",
+		args:    "<bucket> <key>",
+		help:    "get the metadata of an object",
+		minArgs: 2,
+		maxArgs: 2,
+		run:     runGetObject,
+	},
+	{
+		name:    "putobject",
+		args:    "<bucket> <key> <value>",
+		help:    "put the metadata of an object",
+		minArgs: 3,
+		maxArgs: 3,
+		run:     runPutObject,
+	},
+	{
+		name:        "deleteobject",
+		args:        "<bucket> <key>",
+		help:        "delete the metadata of an object",
+		minArgs:     2,
+		maxArgs:     2,
+		destructive: true,
+		run:         runDeleteObject,
+	},
+	{
+		name: "batch",
+		args: "<bucket> <file>",
+		help: "apply a batch of puts and deletes read from a JSON file (\"-\" for stdin), " +
+			"as an array of {\"key\", \"value\"} or {\"key\", \"type\": \"del\"} entries",
+		minArgs: 2,
+		maxArgs: 2,
+		run:     runBatch,
+	},
+	{
+		name:        "deleterange",
+		args:        "<bucket>",
+		help:        "delete all keys of a bucket in the given range, and return their count",
+		minArgs:     1,
+		maxArgs:     1,
+		options:     []string{"gt", "gte", "lt", "lte", "prefix", "batchSize", "concurrency", "dryRun"},
+		destructive: true,
+		run:         runDeleteRange,
+	},
+	{
+		name:    "putusersbucket",
+		args:    "<canonicalId> <bucket>",
+		help:    "index a bucket under its owner in the users bucket",
+		minArgs: 2,
+		maxArgs: 2,
+		options: []string{"creationDate"},
+		run:     runPutUsersBucketEntry,
+	},
+	{
+		name:    "deleteusersbucket",
+		args:    "<canonicalId> <bucket>",
+		help:    "remove a bucket from the index of its owner in the users bucket",
+		minArgs: 2,
+		maxArgs: 2,
+		run:     runDeleteUsersBucketEntry,
+	},
+	{
+		name:    "request",
+		args:    "<httpMethod> <resource>",
+		help:    "send a raw request to bucketd, e.g. \"request GET /_/healthcheck\"",
+		minArgs: 2,
+		maxArgs: 2,
+		options: []string{"body", "apiMethod"},
+		run:     runRequest,
+	},
+}
+
+// objectResource returns the bucketd resource of an object
+func objectResource(bucketName string, key string) string {
+	return fmt.Sprintf("/default/bucket/%s/%s", url.PathEscape(bucketName), url.PathEscape(key))
+}
+
+func runCreateBucket(ctx context.Context, sh *shell, args []string, opts commandOptions) (result, error) {
+	var createOpts []bucketclient.CreateBucketOption
+	sessionId, hasSessionId, err := opts.int("session")
+	if err != nil {
+		return result{}, err
+	}
+	if hasSessionId {
+		createOpts = append(createOpts, bucketclient.CreateBucketSessionIdOption(sessionId))
+	}
+	idempotent, err := opts.bool("idempotent")
+	if err != nil {
+		return result{}, err
+	}
+	if idempotent {
+		createOpts = append(createOpts, bucketclient.CreateBucketMakeIdempotent)
+	}
+	err = sh.client.CreateBucket(ctx, args[0], []byte(args[1]), createOpts...)
+	if err != nil {
+		return result{}, err
+	}
+	return result{message: fmt.Sprintf("bucket %s created", args[0])}, nil
+}
+
+func runDeleteBucket(ctx context.Context, sh *shell, args []string, opts commandOptions) (result, error) {
+	var deleteOpts []bucketclient.DeleteBucketOption
+	if uid, hasUid := opts.string("expectedUid"); hasUid {
+		deleteOpts = append(deleteOpts, bucketclient.DeleteBucketExpectedUIDOption(uid))
+	}
+	idempotent, err := opts.bool("idempotent")
+	if err != nil {
+		return result{}, err
+	}
+	if idempotent {
+		deleteOpts = append(deleteOpts, bucketclient.DeleteBucketMakeIdempotent)
+	}
+	if err := sh.client.DeleteBucket(ctx, args[0], deleteOpts...); err != nil {
+		return result{}, err
+	}
+	return result{message: fmt.Sprintf("bucket %s deleted", args[0])}, nil
+}
+
+func runGetBucketAttributes(ctx context.Context, sh *shell, args []string, opts commandOptions) (result, error) {
+	attributes, err := sh.client.GetBucketAttributes(ctx, args[0])
+	if err != nil {
+		return result{}, err
+	}
+	return result{data: json.RawMessage(attributes)}, nil
+}
+
+// parseAttributes returns attributes given as JSON as is, and turns
+// attributes given as "name=value;name=value" like the Node.js shell
+// into a JSON object
+func parseAttributes(attributes string) ([]byte, error) {
+	if strings.HasPrefix(strings.TrimSpace(attributes), "{") {
+		if !json.Valid([]byte(attributes)) {
+			return nil, fmt.Errorf("invalid JSON attributes %q", attributes)
+		}
+		return []byte(attributes), nil
+	}
+	object := map[string]string{}
+	for _, attribute := range strings.Split(attributes, ";") {
+		name, value, _ := strings.Cut(attribute, "=")
+		object[name] = value
+	}
+	return json.Marshal(object)
+}
+
+func runPutBucketAttributes(ctx context.Context, sh *shell, args []string, opts commandOptions) (result, error) {
+	attributes, err := parseAttributes(args[1])
+	if err != nil {
+		return result{}, err
+	}
+	if err := sh.client.PutBucketAttributes(ctx, args[0], attributes); err != nil {
+		return result{}, err
+	}
+	return result{message: fmt.Sprintf("attributes of bucket %s updated", args[0])}, nil
+}
+
+func runGetObject(ctx context.Context, sh *shell, args []string, opts commandOptions) (result, error) {
+	value, err := sh.client.Request(ctx, "GetObject", "GET", objectResource(args[0], args[1]))
+	if err != nil {
+		return result{}, err
+	}
+	return result{data: json.RawMessage(value)}, nil
+}
+
+func runPutObject(ctx context.Context, sh *shell, args []string, opts commandOptions) (result, error) {
+	_, err := sh.client.Request(ctx, "PutObject", "POST", objectResource(args[0], args[1]),
+		bucketclient.RequestBodyOption([]byte(args[2])))
+	if err != nil {
+		return result{}, err
+	}
+	return result{message: fmt.Sprintf("object %s put", args[1])}, nil
+}
+
+func runDeleteObject(ctx context.Context, sh *shell, args []string, opts commandOptions) (result, error) {
+	_, err := sh.client.Request(ctx, "DeleteObject", "DELETE", objectResource(args[0], args[1]))
+	if err != nil {
+		return result{}, err
+	}
+	return result{message: fmt.Sprintf("object %s deleted", args[1])}, nil
+}
+
+// readInputFile reads the file at path, or the standard input of the
+// shell if path is "-"
+func (sh *shell) readInputFile(path string) ([]byte, error) {
+	if path == "-" {
+		if sh.stdin == nil {
+			return nil, fmt.Errorf("no standard input to read from")
+		}
+		return io.ReadAll(sh.stdin)
+	}
+	return os.ReadFile(path)
+}
+
+func runBatch(ctx context.Context, sh *shell, args []string, opts commandOptions) (result, error) {
+	data, err := sh.readInputFile(args[1])
+	if err != nil {
+		return result{}, err
+	}
+	var batch []bucketclient.PostBatchEntry
+	if err := json.Unmarshal(data, &batch); err != nil {
+		// also accept the payload of bucketd batch requests
+		var payload struct {
+			Batch []bucketclient.PostBatchEntry `json:"batch"`
+		}
+		if payloadErr := json.Unmarshal(data, &payload); payloadErr != nil || payload.Batch == nil {
+			return result{}, fmt.Errorf("invalid batch file %s: %w", args[1], err)
+		}
+		batch = payload.Batch
+	}
+	if err := sh.client.PostBatch(ctx, args[0], batch); err != nil {
+		return result{}, err
+	}
+	return result{message: fmt.Sprintf("batch of %d entries applied", len(batch))}, nil
+}
+
+func runDeleteRange(ctx context.Context, sh *shell, args []string, opts commandOptions) (result, error) {
+	var deleteOpts []bucketclient.DeleteRangeOption
+	for name, option := range map[string]func(string) bucketclient.DeleteRangeOption{
+		"gt":     bucketclient.DeleteRangeGTOption,
+		"gte":    bucketclient.DeleteRangeGTEOption,
+		"lt":     bucketclient.DeleteRangeLTOption,
+		"lte":    bucketclient.DeleteRangeLTEOption,
+		"prefix": bucketclient.DeleteRangePrefixOption,
+	} {
+		if value, found := opts.string(name); found {
+			deleteOpts = append(deleteOpts, option(value))
+		}
+	}
+	for name, option := range map[string]func(int) bucketclient.DeleteRangeOption{
+		"batchSize":   bucketclient.DeleteRangeBatchSizeOption,
+		"concurrency": bucketclient.DeleteRangeConcurrencyOption,
+	} {
+		value, found, err := opts.int(name)
+		if err != nil {
+			return result{}, err
+		}
+		if found {
+			deleteOpts = append(deleteOpts, option(value))
+		}
+	}
+	dryRun, err := opts.bool("dryRun")
+	if err != nil {
+		return result{}, err
+	}
+	if dryRun {
+		deleteOpts = append(deleteOpts, bucketclient.DeleteRangeDryRunOption())
+	}
+	count, err := sh.client.DeleteRange(ctx, args[0], deleteOpts...)
+	if err != nil {
+		return result{}, err
+	}
+	data := struct {
+		Count  int  `json:"count"`
+		DryRun bool `json:"dryRun,omitempty"`
+	}{count, dryRun}
+	countTable := &table{header: []string{"COUNT", "DRY RUN"}}
+	countTable.addRow(fmt.Sprint(count), fmt.Sprint(dryRun))
+	return result{data: data, table: countTable}, nil
+}
+
+func runPutUsersBucketEntry(ctx context.Context, sh *shell, args []string, opts commandOptions) (result, error) {
+	creationDate, hasCreationDate, err := opts.time("creationDate")
+	if err != nil {
+		return result{}, err
+	}
+	if !hasCreationDate {
+		creationDate = time.Now()
+	}
+	if err := sh.client.PutUsersBucketEntry(ctx, args[0], args[1], creationDate); err != nil {
+		return result{}, err
+	}
+	return result{message: fmt.Sprintf("bucket %s indexed under owner %s", args[1], args[0])}, nil
+}
+
+func runDeleteUsersBucketEntry(ctx context.Context, sh *shell, args []string, opts commandOptions) (result, error) {
+	if err := sh.client.DeleteUsersBucketEntry(ctx, args[0], args[1]); err != nil {
+		return result{}, err
+	}
+	return result{message: fmt.Sprintf("bucket %s removed from owner %s", args[1], args[0])}, nil
+}
+
+func runRequest(ctx context.Context, sh *shell, args []string, opts commandOptions) (result, error) {
+	apiMethod, found := opts.string("apiMethod")
+	if !found {
+		apiMethod = "Request"
+	}
+	var requestOpts []bucketclient.RequestOption
+	if body, hasBody := opts.string("body"); hasBody {
+		requestOpts = append(requestOpts, bucketclient.RequestBodyOption([]byte(body)))
+	}
+	responseBody, err := sh.client.Request(ctx, apiMethod, strings.ToUpper(args[0]), args[1],
+		requestOpts...)
+	if err != nil {
+		return result{}, err
+	}
+	if len(responseBody) == 0 {
+		return result{}, nil
+	}
+	return result{data: json.RawMessage(responseBody)}, nil
+}
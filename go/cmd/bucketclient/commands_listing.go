@@ -0,0 +1,436 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/scality/bucketclient/go"
+)
+
+var listingCommands = []*command{
+	{
+		name:       "listobject",
+		args:       "<bucket>",
+		help:       "list the objects of a bucket, like the Node.js shell",
+		minArgs:    1,
+		maxArgs:    1,
+		options:    []string{"prefix", "marker", "maxKeys", "delimiter", "listingType"},
+		pageOption: "maxKeys",
+		run:        runListObject,
+	},
+	{
+		name:       "listbasic",
+		args:       "<bucket>",
+		help:       "list the raw keys and values of a bucket in the given range",
+		minArgs:    1,
+		maxArgs:    1,
+		options:    []string{"gt", "gte", "lt", "lte", "maxKeys", "noKeys", "noValues"},
+		pageOption: "maxKeys",
+		run:        runListBasic,
+	},
+	{
+		name:    "listversions",
+		args:    "<bucket>",
+		help:    "list the object versions of a bucket",
+		minArgs: 1,
+		maxArgs: 1,
+		options: []string{"keyMarker", "versionIdMarker", "maxKeys",
+			"lastKeyMarker", "lastVersionIdMarker"},
+		pageOption: "maxKeys",
+		run:        runListObjectVersions,
+	},
+	{
+		name:       "listmpu",
+		args:       "<bucket>",
+		help:       "list the ongoing multipart uploads of a bucket",
+		minArgs:    1,
+		maxArgs:    1,
+		options:    []string{"prefix", "delimiter", "keyMarker", "uploadIdMarker", "maxUploads"},
+		pageOption: "maxUploads",
+		run:        runListMultipartUploads,
+	},
+	{
+		name:       "listparts",
+		args:       "<bucket> <uploadId>",
+		help:       "list the parts of a multipart upload",
+		minArgs:    2,
+		maxArgs:    2,
+		options:    []string{"partNumberMarker", "maxParts"},
+		pageOption: "maxParts",
+		run:        runListParts,
+	},
+	{
+		name:    "listbucketsbyowner",
+		args:    "<canonicalId>",
+		help:    "list the buckets of an owner from the users bucket",
+		minArgs: 1,
+		maxArgs: 1,
+		run:     runListBucketsByOwner,
+	},
+	{
+		name:    "listlifecycle",
+		args:    "<current|noncurrent|orphans> <bucket>",
+		help:    "list the current versions, non-current versions or orphan delete markers of a bucket",
+		minArgs: 2,
+		maxArgs: 2,
+		options: []string{"prefix", "beforeDate", "excludedDataStoreName", "maxScannedEntries",
+			"maxKeys", "marker", "keyMarker", "versionIdMarker"},
+		pageOption: "maxKeys",
+		run:        runListLifecycle,
+	},
+}
+
+func runListObject(ctx context.Context, sh *shell, args []string, opts commandOptions) (result, error) {
+	query := url.Values{}
+	for name, value := range opts {
+		query.Set(name, value)
+	}
+	resource := fmt.Sprintf("/default/bucket/%s", url.PathEscape(args[0]))
+	if len(query) > 0 {
+		resource += "?" + query.Encode()
+	}
+	responseBody, err := sh.client.Request(ctx, "ListObject", "GET", resource)
+	if err != nil {
+		return result{}, err
+	}
+	var listing struct {
+		CommonPrefixes []string
+		Contents       []struct {
+			Key   string `json:"key"`
+			Value string `json:"value"`
+		}
+		IsTruncated bool
+		NextMarker  string
+	}
+	res := result{data: json.RawMessage(responseBody)}
+	if err := json.Unmarshal(responseBody, &listing); err != nil {
+		// unknown listing format: print it as JSON
+		return res, nil
+	}
+	res.table = &table{header: []string{"KEY", "VALUE"}}
+	for _, prefix := range listing.CommonPrefixes {
+		res.table.addRow(prefix, "(common prefix)")
+	}
+	for _, entry := range listing.Contents {
+		res.table.addRow(entry.Key, entry.Value)
+	}
+	if listing.IsTruncated {
+		nextMarker := listing.NextMarker
+		if nextMarker == "" && len(listing.Contents) > 0 {
+			// listings without delimiter continue after their last key
+			nextMarker = listing.Contents[len(listing.Contents)-1].Key
+		}
+		res.table.footer = truncatedFooter("marker", nextMarker)
+		res.next = nextPageOptions(opts, "marker", nextMarker)
+	}
+	return res, nil
+}
+
+// truncatedFooter returns the footer of truncated listings, giving the
+// options to list the next page
+func truncatedFooter(markers ...string) string {
+	var options []string
+	for i := 0; i+1 < len(markers); i += 2 {
+		if markers[i+1] != "" {
+			options = append(options, fmt.Sprintf("%s=%s", markers[i], quoteWord(markers[i+1])))
+		}
+	}
+	if len(options) == 0 {
+		return "(truncated)"
+	}
+	return fmt.Sprintf("(truncated, next page with %s)", strings.Join(options, " "))
+}
+
+// nextPageOptions returns the options listing the next page of a
+// truncated listing: those of the current page with the given markers
+// set, or nil if there is no marker to continue from
+func nextPageOptions(opts commandOptions, markers ...string) commandOptions {
+	next := commandOptions{}
+	maps.Copy(next, opts)
+	hasMarker := false
+	for i := 0; i+1 < len(markers); i += 2 {
+		if markers[i+1] == "" {
+			delete(next, markers[i])
+		} else {
+			next[markers[i]] = markers[i+1]
+			hasMarker = true
+		}
+	}
+	if !hasMarker {
+		return nil
+	}
+	return next
+}
+
+// quoteWord quotes word if needed to be read back as a single word by
+// splitCommandLine
+func quoteWord(word string) string {
+	if strings.Contains(word, "\x00") {
+		// null characters can only be escaped outside of quotes
+		return strings.NewReplacer(`\`, `\\`, " ", `\ `, "\t", "\\\t",
+			"'", `\'`, `"`, `\"`, "\x00", `\0`).Replace(word)
+	}
+	if word != "" && !strings.ContainsAny(word, " \t'\"\\") {
+		return word
+	}
+	return "'" + strings.ReplaceAll(word, "'", `'\''`) + "'"
+}
+
+func runListBasic(ctx context.Context, sh *shell, args []string, opts commandOptions) (result, error) {
+	var listOpts []bucketclient.ListBasicOption
+	for name, option := range map[string]func(string) bucketclient.ListBasicOption{
+		"gt":  bucketclient.ListBasicGTOption,
+		"gte": bucketclient.ListBasicGTEOption,
+		"lt":  bucketclient.ListBasicLTOption,
+		"lte": bucketclient.ListBasicLTEOption,
+	} {
+		if value, found := opts.string(name); found {
+			listOpts = append(listOpts, option(value))
+		}
+	}
+	maxKeys, hasMaxKeys, err := opts.int("maxKeys")
+	if err != nil {
+		return result{}, err
+	}
+	if hasMaxKeys {
+		listOpts = append(listOpts, bucketclient.ListBasicMaxKeysOption(maxKeys))
+	}
+	for name, option := range map[string]func() bucketclient.ListBasicOption{
+		"noKeys":   bucketclient.ListBasicNoKeysOption,
+		"noValues": bucketclient.ListBasicNoValuesOption,
+	} {
+		enabled, err := opts.bool(name)
+		if err != nil {
+			return result{}, err
+		}
+		if enabled {
+			listOpts = append(listOpts, option())
+		}
+	}
+	listing, err := sh.client.ListBasic(ctx, args[0], listOpts...)
+	if err != nil {
+		return result{}, err
+	}
+	listTable := &table{header: []string{"KEY", "VALUE"}}
+	for _, entry := range *listing {
+		listTable.addRow(entry.Key, entry.Value)
+	}
+	res := result{data: listing, table: listTable}
+	noKeys, _ := opts.bool("noKeys")
+	if hasMaxKeys && maxKeys > 0 && len(*listing) == maxKeys && !noKeys {
+		// bucketd doesn't tell whether the listing is truncated: more
+		// keys may follow the last one
+		lastKey := (*listing)[len(*listing)-1].Key
+		res.table.footer = fmt.Sprintf("(more keys may follow, next page with gt=%s)", quoteWord(lastKey))
+		res.next = nextPageOptions(opts, "gt", lastKey)
+		delete(res.next, "gte")
+	}
+	return res, nil
+}
+
+func runListObjectVersions(ctx context.Context, sh *shell, args []string, opts commandOptions) (result, error) {
+	var listOpts []bucketclient.ListObjectVersionsOption
+	keyMarker, hasKeyMarker := opts.string("keyMarker")
+	versionIdMarker, hasVersionIdMarker := opts.string("versionIdMarker")
+	if hasKeyMarker || hasVersionIdMarker {
+		listOpts = append(listOpts,
+			bucketclient.ListObjectVersionsMarkerOption(keyMarker, versionIdMarker))
+	}
+	lastKeyMarker, hasLastKeyMarker := opts.string("lastKeyMarker")
+	lastVersionIdMarker, hasLastVersionIdMarker := opts.string("lastVersionIdMarker")
+	if hasLastKeyMarker || hasLastVersionIdMarker {
+		listOpts = append(listOpts,
+			bucketclient.ListObjectVersionsLastMarkerOption(lastKeyMarker, lastVersionIdMarker))
+	}
+	maxKeys, hasMaxKeys, err := opts.int("maxKeys")
+	if err != nil {
+		return result{}, err
+	}
+	if hasMaxKeys {
+		listOpts = append(listOpts, bucketclient.ListObjectVersionsMaxKeysOption(maxKeys))
+	}
+	listing, err := sh.client.ListObjectVersions(ctx, args[0], listOpts...)
+	if err != nil {
+		return result{}, err
+	}
+	listTable := &table{header: []string{"KEY", "VERSION ID", "VALUE"}}
+	for _, prefix := range listing.CommonPrefixes {
+		listTable.addRow(prefix, "", "(common prefix)")
+	}
+	for _, version := range listing.Versions {
+		listTable.addRow(version.Key, version.VersionId, version.Value)
+	}
+	var next commandOptions
+	if listing.IsTruncated {
+		markers := []string{
+			"keyMarker", listing.NextKeyMarker,
+			"versionIdMarker", listing.NextVersionIdMarker,
+		}
+		listTable.footer = truncatedFooter(markers...)
+		next = nextPageOptions(opts, markers...)
+	}
+	return result{data: listing, table: listTable, next: next}, nil
+}
+
+func runListMultipartUploads(ctx context.Context, sh *shell, args []string, opts commandOptions) (result, error) {
+	var listOpts []bucketclient.ListMultipartUploadsOption
+	if prefix, found := opts.string("prefix"); found {
+		listOpts = append(listOpts, bucketclient.ListMultipartUploadsPrefixOption(prefix))
+	}
+	if delimiter, found := opts.string("delimiter"); found {
+		listOpts = append(listOpts, bucketclient.ListMultipartUploadsDelimiterOption(delimiter))
+	}
+	keyMarker, hasKeyMarker := opts.string("keyMarker")
+	uploadIdMarker, hasUploadIdMarker := opts.string("uploadIdMarker")
+	if hasKeyMarker || hasUploadIdMarker {
+		listOpts = append(listOpts,
+			bucketclient.ListMultipartUploadsKeyMarkerOption(keyMarker, uploadIdMarker))
+	}
+	maxUploads, hasMaxUploads, err := opts.int("maxUploads")
+	if err != nil {
+		return result{}, err
+	}
+	if hasMaxUploads {
+		listOpts = append(listOpts, bucketclient.ListMultipartUploadsMaxUploadsOption(maxUploads))
+	}
+	listing, err := sh.client.ListMultipartUploads(ctx, args[0], listOpts...)
+	if err != nil {
+		return result{}, err
+	}
+	listTable := &table{header: []string{"KEY", "UPLOAD ID", "INITIATED", "OWNER", "STORAGE CLASS"}}
+	for _, prefix := range listing.CommonPrefixes {
+		listTable.addRow(prefix, "(common prefix)", "", "", "")
+	}
+	for _, upload := range listing.Uploads {
+		listTable.addRow(upload.Key, upload.UploadId, upload.Initiated,
+			upload.Owner.DisplayName, upload.StorageClass)
+	}
+	var next commandOptions
+	if listing.IsTruncated {
+		markers := []string{
+			"keyMarker", listing.NextKeyMarker,
+			"uploadIdMarker", listing.NextUploadIdMarker,
+		}
+		listTable.footer = truncatedFooter(markers...)
+		next = nextPageOptions(opts, markers...)
+	}
+	return result{data: listing, table: listTable, next: next}, nil
+}
+
+func runListParts(ctx context.Context, sh *shell, args []string, opts commandOptions) (result, error) {
+	var listOpts []bucketclient.ListPartsOption
+	for name, option := range map[string]func(int) bucketclient.ListPartsOption{
+		"partNumberMarker": bucketclient.ListPartsPartNumberMarkerOption,
+		"maxParts":         bucketclient.ListPartsMaxPartsOption,
+	} {
+		value, found, err := opts.int(name)
+		if err != nil {
+			return result{}, err
+		}
+		if found {
+			listOpts = append(listOpts, option(value))
+		}
+	}
+	listing, err := sh.client.ListParts(ctx, args[0], args[1], listOpts...)
+	if err != nil {
+		return result{}, err
+	}
+	listTable := &table{header: []string{"PART NUMBER", "ETAG", "SIZE", "LAST MODIFIED"}}
+	for _, part := range listing.Parts {
+		listTable.addRow(fmt.Sprint(part.PartNumber), part.ETag, fmt.Sprint(part.Size), part.LastModified)
+	}
+	var next commandOptions
+	if listing.IsTruncated {
+		markers := []string{"partNumberMarker", fmt.Sprint(listing.NextPartNumberMarker)}
+		listTable.footer = truncatedFooter(markers...)
+		next = nextPageOptions(opts, markers...)
+	}
+	return result{data: listing, table: listTable, next: next}, nil
+}
+
+func runListBucketsByOwner(ctx context.Context, sh *shell, args []string, opts commandOptions) (result, error) {
+	buckets, err := sh.client.ListBucketsByOwner(ctx, args[0])
+	if err != nil {
+		return result{}, err
+	}
+	listTable := &table{header: []string{"NAME", "CREATION DATE"}}
+	for _, bucket := range buckets {
+		listTable.addRow(bucket.Name, bucket.CreationDate.Format(time.RFC3339))
+	}
+	return result{data: buckets, table: listTable}, nil
+}
+
+func runListLifecycle(ctx context.Context, sh *shell, args []string, opts commandOptions) (result, error) {
+	var list func(ctx context.Context, bucketName string,
+		opts ...bucketclient.ListLifecycleOption) (*bucketclient.ListLifecycleResponse, error)
+	switch args[0] {
+	case "current":
+		list = sh.client.ListLifecycleCurrent
+	case "noncurrent":
+		list = sh.client.ListLifecycleNonCurrent
+	case "orphans":
+		list = sh.client.ListLifecycleOrphanDeleteMarkers
+	default:
+		return result{}, fmt.Errorf("invalid lifecycle listing %q, expected "+
+			"\"current\", \"noncurrent\" or \"orphans\"", args[0])
+	}
+	var listOpts []bucketclient.ListLifecycleOption
+	for name, option := range map[string]func(string) bucketclient.ListLifecycleOption{
+		"prefix":                bucketclient.ListLifecyclePrefixOption,
+		"excludedDataStoreName": bucketclient.ListLifecycleExcludedDataStoreNameOption,
+		"marker":                bucketclient.ListLifecycleMarkerOption,
+	} {
+		if value, found := opts.string(name); found {
+			listOpts = append(listOpts, option(value))
+		}
+	}
+	for name, option := range map[string]func(int) bucketclient.ListLifecycleOption{
+		"maxScannedEntries": bucketclient.ListLifecycleMaxScannedEntriesOption,
+		"maxKeys":           bucketclient.ListLifecycleMaxKeysOption,
+	} {
+		value, found, err := opts.int(name)
+		if err != nil {
+			return result{}, err
+		}
+		if found {
+			listOpts = append(listOpts, option(value))
+		}
+	}
+	beforeDate, hasBeforeDate, err := opts.time("beforeDate")
+	if err != nil {
+		return result{}, err
+	}
+	if hasBeforeDate {
+		listOpts = append(listOpts, bucketclient.ListLifecycleBeforeDateOption(beforeDate))
+	}
+	keyMarker, hasKeyMarker := opts.string("keyMarker")
+	versionIdMarker, hasVersionIdMarker := opts.string("versionIdMarker")
+	if hasKeyMarker || hasVersionIdMarker {
+		listOpts = append(listOpts,
+			bucketclient.ListLifecycleVersionMarkerOption(keyMarker, versionIdMarker))
+	}
+	listing, err := list(ctx, args[1], listOpts...)
+	if err != nil {
+		return result{}, err
+	}
+	listTable := &table{header: []string{"KEY", "VALUE"}}
+	for _, entry := range listing.Contents {
+		listTable.addRow(entry.Key, entry.Value)
+	}
+	var next commandOptions
+	if listing.IsTruncated {
+		markers := []string{
+			"marker", listing.NextMarker,
+			"keyMarker", listing.NextKeyMarker,
+			"versionIdMarker", listing.NextVersionIdMarker,
+		}
+		listTable.footer = truncatedFooter(markers...)
+		next = nextPageOptions(opts, markers...)
+	}
+	return result{data: listing, table: listTable, next: next}, nil
+}
@@ -0,0 +1,109 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+
+	"github.com/scality/bucketclient/go"
+)
+
+var metastoreCommands = []*command{
+	{
+		name:    "getmetastore",
+		args:    "<bucket>",
+		help:    "get the metastore entry of a bucket",
+		minArgs: 1,
+		maxArgs: 1,
+		run:     runGetMetastoreEntry,
+	},
+	{
+		name:    "putmetastore",
+		args:    "<bucket> <entry>",
+		help:    "create the metastore entry of a bucket, given as JSON",
+		minArgs: 2,
+		maxArgs: 2,
+		run:     runPutMetastoreEntry,
+	},
+	{
+		name:    "casmetastore",
+		args:    "<bucket> <expectedVersion> <entry>",
+		help:    "replace the metastore entry of a bucket only if it has the expected version",
+		minArgs: 3,
+		maxArgs: 3,
+		run:     runCompareAndSwapMetastoreEntry,
+	},
+	{
+		name:        "deletemetastore",
+		args:        "<bucket>",
+		help:        "delete the metastore entry of a bucket, only if it has the given id if set",
+		minArgs:     1,
+		maxArgs:     1,
+		options:     []string{"expectedId"},
+		destructive: true,
+		run:         runDeleteMetastoreEntry,
+	},
+}
+
+func metastoreEntryTable(entry bucketclient.MetastoreEntry) *table {
+	entryTable := &table{header: []string{"NAME", "ID", "VERSION", "RAFT SESSION", "CREATING", "DELETING"}}
+	entryTable.addRow(entry.Name, entry.ID, fmt.Sprint(entry.Version), fmt.Sprint(entry.RaftSessionID),
+		fmt.Sprint(entry.Creating), fmt.Sprint(entry.Deleting))
+	return entryTable
+}
+
+func parseMetastoreEntry(entryJSON string) (bucketclient.MetastoreEntry, error) {
+	var entry bucketclient.MetastoreEntry
+	if err := json.Unmarshal([]byte(entryJSON), &entry); err != nil {
+		return entry, fmt.Errorf("invalid metastore entry %q: %w", entryJSON, err)
+	}
+	return entry, nil
+}
+
+func runGetMetastoreEntry(ctx context.Context, sh *shell, args []string, opts commandOptions) (result, error) {
+	entry, err := sh.client.GetMetastoreEntry(ctx, args[0])
+	if err != nil {
+		return result{}, err
+	}
+	return result{data: entry, table: metastoreEntryTable(entry)}, nil
+}
+
+func runPutMetastoreEntry(ctx context.Context, sh *shell, args []string, opts commandOptions) (result, error) {
+	entry, err := parseMetastoreEntry(args[1])
+	if err != nil {
+		return result{}, err
+	}
+	if err := sh.client.CreateMetastoreEntry(ctx, args[0], entry); err != nil {
+		return result{}, err
+	}
+	return result{message: fmt.Sprintf("metastore entry of bucket %s created", args[0])}, nil
+}
+
+func runCompareAndSwapMetastoreEntry(ctx context.Context, sh *shell, args []string,
+	opts commandOptions) (result, error) {
+	expectedVersion, err := strconv.Atoi(args[1])
+	if err != nil {
+		return result{}, fmt.Errorf("invalid expected version %q: expected an integer", args[1])
+	}
+	entry, err := parseMetastoreEntry(args[2])
+	if err != nil {
+		return result{}, err
+	}
+	err = sh.client.CompareAndSwapMetastoreEntry(ctx, args[0], expectedVersion, entry)
+	if err != nil {
+		return result{}, err
+	}
+	return result{message: fmt.Sprintf("metastore entry of bucket %s replaced", args[0])}, nil
+}
+
+func runDeleteMetastoreEntry(ctx context.Context, sh *shell, args []string, opts commandOptions) (result, error) {
+	var deleteOpts []bucketclient.DeleteMetastoreEntryOption
+	if id, hasId := opts.string("expectedId"); hasId {
+		deleteOpts = append(deleteOpts, bucketclient.DeleteMetastoreEntryExpectedIDOption(id))
+	}
+	if err := sh.client.DeleteMetastoreEntry(ctx, args[0], deleteOpts...); err != nil {
+		return result{}, err
+	}
+	return result{message: fmt.Sprintf("metastore entry of bucket %s deleted", args[0])}, nil
+}
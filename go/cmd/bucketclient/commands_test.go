@@ -0,0 +1,280 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/scality/bucketclient/go"
+	"github.com/scality/bucketclient/go/bucketclienttest"
+)
+
+var _ = Describe("splitCommandLine()", func() {
+	DescribeTable("splits lines into words",
+		func(line string, expectedWords []string) {
+			Expect(splitCommandLine(line)).To(Equal(expectedWords))
+		},
+		Entry("spaces", "  getobject  bucket\tkey ", []string{"getobject", "bucket", "key"}),
+		Entry("single quotes", `putbucketattr b '{"a": "b c"}'`,
+			[]string{"putbucketattr", "b", `{"a": "b c"}`}),
+		Entry("double quotes and escapes", `getobject b "my \"key\"" a\ b ''`,
+			[]string{"getobject", "b", `my "key"`, "a b", ""}),
+		Entry("null characters", `getobject b obj\0v1 '\0'`, []string{"getobject", "b", "obj\x00v1", `\0`}),
+	)
+
+	It("reads back quoted words", func() {
+		for _, word := range []string{"a", "my key", `it's "quoted" \ `, "obj\x00v 1\t'"} {
+			Expect(splitCommandLine("getobject " + quoteWord(word))).To(Equal([]string{"getobject", word}))
+		}
+	})
+
+	It("fails on unterminated quotes", func() {
+		_, err := splitCommandLine(`getobject b 'key`)
+		Expect(err).To(MatchError("unterminated ' quote"))
+	})
+})
+
+var _ = Describe("commands", func() {
+	var (
+		server *bucketclienttest.Server
+		client *bucketclient.BucketClient
+		out    *bytes.Buffer
+		sh     *shell
+	)
+
+	BeforeEach(func() {
+		server = bucketclienttest.NewServer(bucketclienttest.ServerRaftSessionsOption(2))
+		DeferCleanup(server.Close)
+		client = server.NewClient()
+		out = &bytes.Buffer{}
+		sh = newShell(client, out, GinkgoWriter, outputTable)
+	})
+
+	// runLines runs each line as a command and returns the output of
+	// the last one
+	runLines := func(ctx context.Context, lines ...string) string {
+		for _, line := range lines {
+			out.Reset()
+			ExpectWithOffset(1, sh.runLine(ctx, line)).To(Succeed(), "running %q", line)
+		}
+		return out.String()
+	}
+
+	Describe("data commands", func() {
+		It("puts bucket attributes given as name=value pairs", func(ctx SpecContext) {
+			runLines(ctx,
+				"createbucket somebucket {}",
+				"putbucketattr somebucket 'owner=abc;name=somebucket'")
+			Expect(client.GetBucketAttributes(ctx, "somebucket")).To(MatchJSON(
+				`{"owner":"abc","name":"somebucket"}`))
+		})
+
+		It("applies batches read from files", func(ctx SpecContext) {
+			batchPath := filepath.Join(GinkgoT().TempDir(), "batch.json")
+			Expect(os.WriteFile(batchPath, []byte(`[
+				{"key": "a", "value": "1"},
+				{"key": "b", "value": "2"}
+			]`), 0o644)).To(Succeed())
+			sh.stdin = strings.NewReader(`{"batch": [{"key": "a", "type": "del"}]}`)
+			Expect(runLines(ctx,
+				"createbucket somebucket {}",
+				"batch somebucket "+batchPath,
+			)).To(Equal("batch of 2 entries applied\n"))
+			Expect(runLines(ctx, "batch somebucket -", "listbasic somebucket")).To(Equal(
+				"KEY  VALUE\n" +
+					"b    2\n"))
+		})
+
+		It("deletes ranges of keys", func(ctx SpecContext) {
+			runLines(ctx, "createbucket somebucket {}")
+			Expect(client.PostBatch(ctx, "somebucket", []bucketclient.PostBatchEntry{
+				{Key: "a", Value: "1"}, {Key: "b", Value: "2"}, {Key: "c", Value: "3"},
+			})).To(Succeed())
+			Expect(runLines(ctx, "deleterange somebucket gte=b dryRun")).To(Equal(
+				"COUNT  DRY RUN\n" +
+					"2      true\n"))
+			sh.format = outputJSON
+			Expect(runLines(ctx, "deleterange somebucket gte=b")).To(Equal("{\n  \"count\": 2\n}\n"))
+			Expect(client.ListBasic(ctx, "somebucket")).To(HaveValue(HaveLen(1)))
+		})
+
+		It("indexes buckets under their owner", func(ctx SpecContext) {
+			runLines(ctx,
+				"createbucket users..bucket {}",
+				"putusersbucket owner1 bucket1 creationDate=2024-01-02T03:04:05Z",
+			)
+			Expect(runLines(ctx, "listbucketsbyowner owner1")).To(Equal(
+				"NAME     CREATION DATE\n" +
+					"bucket1  2024-01-02T03:04:05Z\n"))
+			runLines(ctx, "deleteusersbucket owner1 bucket1")
+			Expect(runLines(ctx, "listbucketsbyowner owner1")).To(Equal("NAME  CREATION DATE\n"))
+		})
+
+		It("sends raw requests", func(ctx SpecContext) {
+			sh.format = outputJSON
+			runLines(ctx, "createbucket somebucket '{\"uid\":\"1\"}'")
+			Expect(runLines(ctx, "request get /default/attributes/somebucket")).To(Equal(
+				"{\n  \"uid\": \"1\"\n}\n"))
+		})
+
+		It("escapes bucket names in object resources", func(ctx SpecContext) {
+			sh.format = outputJSON
+			runLines(ctx,
+				"request post /default/bucket/some%23bucket body={}",
+				"putobject some#bucket key '{\"a\":1}'")
+			Expect(runLines(ctx, "getobject some#bucket key")).To(Equal("{\n  \"a\": 1\n}\n"))
+			runLines(ctx, "deleteobject some#bucket key")
+			Expect(client.ListBasic(ctx, "some%23bucket")).To(HaveValue(BeEmpty()))
+
+			sh.client = &bucketclienttest.MockBucketClient{
+				RequestFunc: func(ctx context.Context, apiMethod string, httpMethod string,
+					resource string, opts ...bucketclient.RequestOption) ([]byte, error) {
+					Expect(resource).To(Equal("/default/bucket/some%23bucket"))
+					return []byte(`{"Contents":[]}`), nil
+				},
+			}
+			runLines(ctx, "listobject some#bucket")
+		})
+
+		It("checks arguments", func(ctx SpecContext) {
+			Expect(sh.runLine(ctx, "putobject somebucket key")).To(MatchError(
+				"usage: putobject <bucket> <key> <value>"))
+			Expect(sh.runLine(ctx, "createbucket somebucket {} session=one")).To(MatchError(
+				`invalid value "one" of option session: expected an integer`))
+			Expect(sh.runLine(ctx, "nosuchcommand")).To(MatchError(
+				`command not found: nosuchcommand (try "help")`))
+		})
+	})
+
+	Describe("listing commands", func() {
+		BeforeEach(func(ctx SpecContext) {
+			runLines(ctx, "createbucket somebucket {}")
+			Expect(client.PostBatch(ctx, "somebucket", []bucketclient.PostBatchEntry{
+				{Key: "obj1", Value: `{"versionId":"v1"}`},
+				{Key: "obj1\x00v1", Value: `{"versionId":"v1"}`},
+				{Key: "obj2", Value: `{}`},
+			})).To(Succeed())
+		})
+
+		It("lists keys with options", func(ctx SpecContext) {
+			Expect(runLines(ctx, "listbasic somebucket gt=obj1 noValues")).To(Equal(
+				"KEY       VALUE\n" +
+					`obj1\0v1` + "  \n" +
+					"obj2      \n"))
+		})
+
+		It("gives the options to get the next page of keys", func(ctx SpecContext) {
+			Expect(runLines(ctx, "listbasic somebucket maxKeys=2 gte=a")).To(Equal(
+				"KEY       VALUE\n" +
+					`obj1      {"versionId":"v1"}` + "\n" +
+					`obj1\0v1  {"versionId":"v1"}` + "\n" +
+					`(more keys may follow, next page with gt=obj1\0v1)` + "\n"))
+			Expect(runLines(ctx, "listbasic somebucket maxKeys=4")).ToNot(ContainSubstring("more keys"))
+		})
+
+		It("lists versions with the options to get the next page", func(ctx SpecContext) {
+			Expect(runLines(ctx, "listversions somebucket maxKeys=1")).To(Equal(
+				"KEY   VERSION ID  VALUE\n" +
+					`obj1  v1          {"versionId":"v1"}` + "\n" +
+					"(truncated, next page with keyMarker=obj1 versionIdMarker=v1)\n"))
+			Expect(runLines(ctx, "listversions somebucket keyMarker=obj1 versionIdMarker=v1")).To(Equal(
+				"KEY   VERSION ID  VALUE\n" +
+					"obj2  null        {}\n"))
+		})
+
+		It("lists objects with the Node.js shell parameters", func(ctx SpecContext) {
+			var resources []string
+			sh.client = &bucketclienttest.MockBucketClient{
+				RequestFunc: func(ctx context.Context, apiMethod string, httpMethod string,
+					resource string, opts ...bucketclient.RequestOption) ([]byte, error) {
+					resources = append(resources, resource)
+					return []byte(`{"CommonPrefixes":["dir/"],` +
+						`"Contents":[{"key":"obj","value":"{}"}],` +
+						`"IsTruncated":true,"NextMarker":"obj"}`), nil
+				},
+			}
+			Expect(runLines(ctx, "listobject somebucket delimiter=/ maxKeys=2")).To(Equal(
+				"KEY   VALUE\n" +
+					"dir/  (common prefix)\n" +
+					"obj   {}\n" +
+					"(truncated, next page with marker=obj)\n"))
+			Expect(resources).To(Equal([]string{"/default/bucket/somebucket?delimiter=%2F&maxKeys=2"}))
+		})
+
+		It("lists lifecycle entries", func(ctx SpecContext) {
+			sh.client = &bucketclienttest.MockBucketClient{
+				ListLifecycleNonCurrentFunc: func(ctx context.Context, bucketName string,
+					opts ...bucketclient.ListLifecycleOption) (*bucketclient.ListLifecycleResponse, error) {
+					Expect(bucketName).To(Equal("somebucket"))
+					Expect(opts).To(HaveLen(2))
+					return &bucketclient.ListLifecycleResponse{
+						Contents:            []bucketclient.ListLifecycleEntry{{Key: "obj", Value: "{}"}},
+						IsTruncated:         true,
+						NextKeyMarker:       "obj",
+						NextVersionIdMarker: "v 1",
+					}, nil
+				},
+			}
+			Expect(runLines(ctx, "listlifecycle noncurrent somebucket prefix=o maxKeys=1")).To(Equal(
+				"KEY  VALUE\n" +
+					"obj  {}\n" +
+					"(truncated, next page with keyMarker=obj versionIdMarker='v 1')\n"))
+			Expect(sh.runLine(ctx, "listlifecycle expired somebucket")).To(MatchError(
+				ContainSubstring(`invalid lifecycle listing "expired"`)))
+		})
+	})
+
+	Describe("metastore commands", func() {
+		It("puts, gets, swaps and deletes entries", func(ctx SpecContext) {
+			runLines(ctx, `putmetastore somebucket '{"name":"somebucket","id":"id1","version":1}'`)
+			Expect(runLines(ctx, "getmetastore somebucket")).To(Equal(
+				"NAME        ID   VERSION  RAFT SESSION  CREATING  DELETING\n" +
+					"somebucket  id1  1        0             false     false\n"))
+			runLines(ctx, `casmetastore somebucket 1 '{"name":"somebucket","id":"id2"}'`)
+			sh.format = outputJSON
+			Expect(runLines(ctx, "getmetastore somebucket")).To(ContainSubstring(`"id": "id2"`))
+			Expect(sh.runLine(ctx, "deletemetastore somebucket expectedId=id1")).ToNot(Succeed())
+			runLines(ctx, "deletemetastore somebucket expectedId=id2")
+		})
+	})
+
+	Describe("admin commands", func() {
+		It("gets and sets access modes", func(ctx SpecContext) {
+			runLines(ctx, "createbucket somebucket {} session=2")
+			Expect(runLines(ctx, "getbucketsession somebucket")).To(Equal("2\n"))
+			runLines(ctx, "setaccessmode somebucket read-only")
+			Expect(runLines(ctx, "getaccessmode somebucket")).To(Equal("read-only\n"))
+			sh.format = outputJSON
+			Expect(runLines(ctx, "getaccessmode somebucket")).To(Equal(
+				"{\n  \"accessMode\": \"read-only\"\n}\n"))
+			Expect(sh.runLine(ctx, "setaccessmode somebucket read-mostly")).To(MatchError(
+				ContainSubstring(`invalid access mode "read-mostly"`)))
+		})
+
+		It("lists raft sessions and their logs", func(ctx SpecContext) {
+			Expect(runLines(ctx, "sessions")).To(Equal(
+				"SESSION  ID  NAME          HOST       PORT  ADMIN PORT\n" +
+					"1        10  md1-cluster1  127.0.0.1  4201  4251\n" +
+					"2        20  md2-cluster1  127.0.0.1  4202  4252\n"))
+
+			runLines(ctx, "createbucket somebucket {}")
+			Expect(client.PostBatch(ctx, "somebucket", []bucketclient.PostBatchEntry{
+				{Key: "a", Value: "1"}, {Key: "b", Type: "del"},
+			})).To(Succeed())
+			output := runLines(ctx, "sessionlog 1 begin=2")
+			Expect(output).To(HavePrefix("BUCKET      METHOD  TIMESTAMP"))
+			Expect(output).To(ContainSubstring("somebucket  batch"))
+			Expect(output).To(ContainSubstring("a, del b\n(start=2 cseq=2 prune=1)\n"))
+		})
+	})
+
+	It("describes commands", func(ctx SpecContext) {
+		Expect(runLines(ctx, "help getobject")).To(Equal(
+			"getobject <bucket> <key>\n    get the metadata of an object\n"))
+	})
+})
@@ -0,0 +1,60 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// defaultEndpoint is the bucketd endpoint used without configuration
+const defaultEndpoint = "http://localhost:9000"
+
+// config is the bucketclient configuration file, in the format of the
+// config.json file of the Node.js client
+type config struct {
+	// Bootstrap lists the bucketd endpoints as "host:port"
+	Bootstrap []string `json:"bootstrap"`
+}
+
+func loadConfig(path string) (*config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var cfg config
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return nil, fmt.Errorf("error parsing config %q: %w", path, err)
+	}
+	return &cfg, nil
+}
+
+// endpointURL turns a bootstrap entry like "localhost:9000" into the
+// URL of a bucketd endpoint
+func endpointURL(endpoint string) string {
+	if strings.Contains(endpoint, "://") {
+		return endpoint
+	}
+	return "http://" + endpoint
+}
+
+// selectEndpoint returns the bucketd endpoint requests are sent to:
+// the endpoint flag if set, else the first endpoint of the bootstrap
+// list of the configuration file if given, else defaultEndpoint. The
+// other bootstrap endpoints are not used.
+func selectEndpoint(endpointFlag string, configPath string) (string, error) {
+	if endpointFlag != "" {
+		return endpointURL(strings.TrimSpace(endpointFlag)), nil
+	}
+	if configPath == "" {
+		return defaultEndpoint, nil
+	}
+	cfg, err := loadConfig(configPath)
+	if err != nil {
+		return "", err
+	}
+	if len(cfg.Bootstrap) == 0 {
+		return "", fmt.Errorf("no bootstrap endpoint in config %q", configPath)
+	}
+	return endpointURL(cfg.Bootstrap[0]), nil
+}
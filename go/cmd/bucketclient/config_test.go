@@ -0,0 +1,35 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("selectEndpoint()", func() {
+	It("prefers the endpoint flag", func() {
+		Expect(selectEndpoint("host1:9000", "config.json")).To(Equal("http://host1:9000"))
+		Expect(selectEndpoint("https://host2:9000", "")).To(Equal("https://host2:9000"))
+	})
+
+	It("uses the first endpoint of the bootstrap list of the config file", func() {
+		configPath := filepath.Join(GinkgoT().TempDir(), "config.json")
+		Expect(os.WriteFile(configPath, []byte(`{
+			"bootstrap": ["bucketclient.testing.local:9000", "other:9000"]
+		}`), 0o644)).To(Succeed())
+		Expect(selectEndpoint("", configPath)).To(Equal("http://bucketclient.testing.local:9000"))
+	})
+
+	It("falls back to the default endpoint", func() {
+		Expect(selectEndpoint("", "")).To(Equal(defaultEndpoint))
+	})
+
+	It("fails on config files without endpoints", func() {
+		configPath := filepath.Join(GinkgoT().TempDir(), "config.json")
+		Expect(os.WriteFile(configPath, []byte(`{"bootstrap": []}`), 0o644)).To(Succeed())
+		_, err := selectEndpoint("", configPath)
+		Expect(err).To(MatchError(ContainSubstring("no bootstrap endpoint")))
+	})
+})
@@ -0,0 +1,91 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"slices"
+	"strings"
+)
+
+// maxHistoryEntries is the number of lines kept in the history of the
+// interactive shell
+const maxHistoryEntries = 1000
+
+// history is the line history of the interactive shell, implementing
+// term.History. It is saved to a file if opened with openHistory.
+type history struct {
+	// entries are ordered from the oldest to the most recent
+	entries []string
+	// file is appended the lines added, if set
+	file *os.File
+	// paused stops recording lines, e.g. the answers to paging prompts
+	paused bool
+}
+
+// openHistory loads the history saved in the file at path, created if
+// missing, and appends the lines added later to it
+func openHistory(path string) (*history, error) {
+	data, err := os.ReadFile(path)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return nil, err
+	}
+	hist := &history{}
+	for _, line := range strings.Split(string(data), "\n") {
+		if line != "" {
+			hist.entries = append(hist.entries, line)
+		}
+	}
+	if len(hist.entries) > maxHistoryEntries {
+		// rewrite the file with the lines kept so that it doesn't grow
+		// forever
+		hist.entries = hist.entries[len(hist.entries)-maxHistoryEntries:]
+		content := strings.Join(hist.entries, "\n") + "\n"
+		if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+			return nil, err
+		}
+	}
+	hist.file, err = os.OpenFile(path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0o600)
+	if err != nil {
+		return nil, err
+	}
+	return hist, nil
+}
+
+// Add records a line, unless blank or repeating the previous one
+func (hist *history) Add(entry string) {
+	if hist.paused || strings.TrimSpace(entry) == "" {
+		return
+	}
+	if len(hist.entries) > 0 && hist.entries[len(hist.entries)-1] == entry {
+		return
+	}
+	hist.entries = append(hist.entries, entry)
+	if len(hist.entries) > maxHistoryEntries {
+		hist.entries = slices.Delete(hist.entries, 0, 1)
+	}
+	if hist.file != nil {
+		// the history is a convenience: failing to save it must not
+		// get in the way of commands
+		_, _ = fmt.Fprintln(hist.file, entry)
+	}
+}
+
+// Len returns the number of lines recorded
+func (hist *history) Len() int {
+	return len(hist.entries)
+}
+
+// At returns a recorded line, 0 being the most recent
+func (hist *history) At(idx int) string {
+	return hist.entries[len(hist.entries)-1-idx]
+}
+
+// Close closes the history file, if any
+func (hist *history) Close() error {
+	if hist.file == nil {
+		return nil
+	}
+	return hist.file.Close()
+}
@@ -0,0 +1,58 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("history", func() {
+	var historyPath string
+
+	BeforeEach(func() {
+		historyPath = filepath.Join(GinkgoT().TempDir(), "history")
+	})
+
+	It("loads and saves lines to its file", func() {
+		Expect(os.WriteFile(historyPath, []byte("getobject b k\nlistbasic b\n"), 0o600)).To(Succeed())
+		hist, err := openHistory(historyPath)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(hist.Len()).To(Equal(2))
+		Expect(hist.At(0)).To(Equal("listbasic b"))
+
+		hist.Add("listbasic b")
+		hist.Add("  ")
+		hist.paused = true
+		hist.Add("q")
+		hist.paused = false
+		hist.Add("use b")
+		Expect(hist.Close()).To(Succeed())
+		Expect(hist.At(0)).To(Equal("use b"))
+		Expect(hist.At(2)).To(Equal("getobject b k"))
+		Expect(os.ReadFile(historyPath)).To(BeEquivalentTo("getobject b k\nlistbasic b\nuse b\n"))
+	})
+
+	It("keeps the most recent lines", func() {
+		var lines strings.Builder
+		for i := range maxHistoryEntries + 10 {
+			fmt.Fprintf(&lines, "getobject b %d\n", i)
+		}
+		Expect(os.WriteFile(historyPath, []byte(lines.String()), 0o600)).To(Succeed())
+		hist, err := openHistory(historyPath)
+		Expect(err).ToNot(HaveOccurred())
+		DeferCleanup(hist.Close)
+		Expect(hist.Len()).To(Equal(maxHistoryEntries))
+		Expect(hist.At(maxHistoryEntries - 1)).To(Equal("getobject b 10"))
+
+		hist.Add("exit")
+		Expect(hist.Len()).To(Equal(maxHistoryEntries))
+		Expect(hist.At(maxHistoryEntries - 1)).To(Equal("getobject b 11"))
+		content, err := os.ReadFile(historyPath)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(strings.Count(string(content), "\n")).To(Equal(maxHistoryEntries + 1))
+	})
+})
@@ -0,0 +1,212 @@
+// Command bucketclient runs commands against bucketd: bucket, object
+// and metastore operations, listings and admin commands on raft
+// sessions and bucket access modes.
+//
+// Usage:
+//
+//	bucketclient [flags] <command> [arguments...]
+//	bucketclient [flags] -f <script>
+//	bucketclient [flags]
+//
+// Given a command, bucketclient runs it and exits. Otherwise, it runs
+// the commands of the script given with -f, or read from standard
+// input, one per line, stopping at the first error unless -keep-going
+// is set. When standard input is a terminal, it prompts for commands
+// interactively like the Node.js shell, with:
+//
+//   - line editing and a history saved to -history
+//   - completion with Tab of command and option names, bucket names from
+//     the metastore and keys of buckets
+//   - a current bucket set with "use <bucket>", passed to commands called
+//     without their bucket argument, except destructive ones like
+//     deletebucket or deleterange
+//   - listings paged to the terminal height, the next page being listed
+//     on Enter
+//   - JSON values embedded in results, like object metadata, expanded in
+//     JSON output
+//
+// Commands take positional arguments followed by options given as
+// "name=value", e.g.:
+//
+//	bucketclient -output json listbasic mybucket gte=a maxKeys=10
+//
+// Run "bucketclient help" for the list of commands.
+package main
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"time"
+
+	"golang.org/x/term"
+
+	"github.com/scality/bucketclient/go"
+)
+
+func main() {
+	os.Exit(run(context.Background(), os.Args[1:], os.Stdin, os.Stdout, os.Stderr))
+}
+
+// run runs bucketclient with the given command-line arguments and
+// returns its exit status
+func run(ctx context.Context, args []string, stdin io.Reader, stdout io.Writer, stderr io.Writer) int {
+	flags := flag.NewFlagSet("bucketclient", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	configPath := flags.String("config", os.Getenv("BUCKETCLIENT_CONFIG"),
+		"path of a config.json file listing bucketd endpoints in \"bootstrap\", "+
+			"only the first one is used (default $BUCKETCLIENT_CONFIG)")
+	endpointFlag := flags.String("endpoint", "",
+		"bucketd endpoint, overriding the config file (default "+defaultEndpoint+")")
+	outputFlag := flags.String("output", string(outputTable), "output format, \"table\" or \"json\"")
+	timeout := flags.Duration("timeout", 30*time.Second, "timeout of each command, 0 for none")
+	timing := flags.Bool("timing", false, "print the duration of each command to stderr")
+	scriptPath := flags.String("f", "", "run the commands of a script file, \"-\" for stdin")
+	keepGoing := flags.Bool("keep-going", false, "keep running a script after a command fails")
+	historyPath := flags.String("history", defaultHistoryPath(),
+		"file saving the history of the interactive shell, empty for none")
+	flags.Usage = func() {
+		fmt.Fprintf(stderr, "Usage: bucketclient [flags] [<command> [arguments...]]\n\nFlags:\n")
+		flags.PrintDefaults()
+		fmt.Fprintf(stderr, "\nRun \"bucketclient help\" for the list of commands.\n")
+	}
+	if err := flags.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return 0
+		}
+		return 2
+	}
+	format, err := parseOutputFormat(*outputFlag)
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return 2
+	}
+	endpoint, err := selectEndpoint(*endpointFlag, *configPath)
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return 2
+	}
+	client := bucketclient.New(endpoint)
+
+	sh := newShell(client, stdout, stderr, format)
+	sh.timeout = *timeout
+	sh.timing = *timing
+	sh.stdin = stdin
+
+	interactive := flags.NArg() == 0 && *scriptPath == "" && isTerminal(stdin)
+	if !interactive {
+		// the interactive shell only cancels its running command
+		var stop context.CancelFunc
+		ctx, stop = signal.NotifyContext(ctx, os.Interrupt)
+		defer stop()
+	}
+
+	switch {
+	case interactive:
+		// commands can't read the terminal the shell reads
+		sh.stdin = nil
+		return sh.runInteractive(ctx, stdin.(*os.File), stdout, *historyPath)
+	case flags.NArg() > 0:
+		err := sh.runCommand(ctx, flags.Args())
+		if err != nil && !errors.Is(err, errExit) {
+			fmt.Fprintf(stderr, "error: %v\n", err)
+			return 1
+		}
+		return 0
+	case *scriptPath != "" && *scriptPath != "-":
+		script, err := os.Open(*scriptPath)
+		if err != nil {
+			fmt.Fprintln(stderr, err)
+			return 2
+		}
+		defer script.Close()
+		return sh.runScript(ctx, script, *keepGoing)
+	default:
+		// commands are read from stdin, which commands can't read
+		sh.stdin = nil
+		return sh.runScript(ctx, stdin, *keepGoing)
+	}
+}
+
+// isTerminal returns whether input is an interactive terminal
+func isTerminal(input io.Reader) bool {
+	file, isFile := input.(*os.File)
+	return isFile && term.IsTerminal(int(file.Fd()))
+}
+
+// defaultHistoryPath returns the path of the history file in the home
+// directory, or "" if there is none
+func defaultHistoryPath() string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(home, ".bucketclient_history")
+}
+
+// runScript runs the commands of a script, one per line, and returns
+// the exit status: 1 if a command failed, 0 otherwise
+func (sh *shell) runScript(ctx context.Context, script io.Reader, keepGoing bool) int {
+	status := 0
+	scanner := bufio.NewScanner(script)
+	scanner.Buffer(nil, 16*1024*1024)
+	for lineNumber := 1; scanner.Scan(); lineNumber++ {
+		err := sh.runLine(ctx, scanner.Text())
+		if errors.Is(err, errExit) {
+			return status
+		}
+		if err != nil {
+			fmt.Fprintf(sh.errOut, "line %d: error: %v\n", lineNumber, err)
+			status = 1
+			if !keepGoing {
+				return status
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		fmt.Fprintf(sh.errOut, "error reading script: %v\n", err)
+		return 1
+	}
+	return status
+}
+
+// runInteractive runs the interactive shell on the terminal of stdin,
+// and returns the exit status
+func (sh *shell) runInteractive(ctx context.Context, stdin *os.File, stdout io.Writer,
+	historyPath string) int {
+	hist := &history{}
+	if historyPath != "" {
+		savedHistory, err := openHistory(historyPath)
+		if err != nil {
+			fmt.Fprintf(sh.errOut, "error loading history, not saving it: %v\n", err)
+		} else {
+			hist = savedHistory
+			defer hist.Close()
+		}
+	}
+	r := newREPL(sh, struct {
+		io.Reader
+		io.Writer
+	}{stdin, stdout}, hist)
+	fd := int(stdin.Fd())
+	// terminals may not know their size, e.g. pseudo-terminals
+	if width, height, err := term.GetSize(fd); err == nil && width > 0 && height > 0 {
+		_ = r.terminal.SetSize(width, height)
+		// leave room for the table header, footer and paging prompt
+		r.pageSize = max(height-3, 1)
+	}
+	r.makeRaw = func() (func(), error) {
+		state, err := term.MakeRaw(fd)
+		if err != nil {
+			return nil, err
+		}
+		return func() { _ = term.Restore(fd, state) }, nil
+	}
+	return r.run(ctx)
+}
@@ -0,0 +1,87 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/scality/bucketclient/go/bucketclienttest"
+)
+
+var _ = Describe("bucketclient", func() {
+	var (
+		server         *bucketclienttest.Server
+		stdout, stderr *bytes.Buffer
+	)
+
+	BeforeEach(func() {
+		server = bucketclienttest.NewServer()
+		DeferCleanup(server.Close)
+		stdout = &bytes.Buffer{}
+		stderr = &bytes.Buffer{}
+	})
+
+	runWithStdin := func(ctx SpecContext, stdin string, args ...string) int {
+		return run(ctx, append([]string{"-endpoint", server.URL}, args...),
+			strings.NewReader(stdin), stdout, stderr)
+	}
+
+	It("runs the command given as arguments", func(ctx SpecContext) {
+		Expect(runWithStdin(ctx, "", "createbucket", "somebucket", `{"uid":"1"}`)).To(Equal(0))
+		Expect(stdout.String()).To(Equal("bucket somebucket created\n"))
+		stdout.Reset()
+		Expect(runWithStdin(ctx, "", "-output", "json", "getbucketattr", "somebucket")).To(Equal(0))
+		Expect(stdout.String()).To(Equal("{\n  \"uid\": \"1\"\n}\n"))
+	})
+
+	It("reports failed commands on stderr with a non-zero status", func(ctx SpecContext) {
+		Expect(runWithStdin(ctx, "", "getbucketattr", "nosuchbucket")).To(Equal(1))
+		Expect(stderr.String()).To(ContainSubstring("NoSuchBucket"))
+		stderr.Reset()
+		Expect(runWithStdin(ctx, "", "getbucketattr")).To(Equal(1))
+		Expect(stderr.String()).To(Equal("error: usage: getbucketattr <bucket>\n"))
+		Expect(runWithStdin(ctx, "", "-output", "yaml", "help")).To(Equal(2))
+	})
+
+	It("runs scripts read from stdin", func(ctx SpecContext) {
+		Expect(runWithStdin(ctx, `
+			# create a bucket and put an object
+			createbucket somebucket '{"uid": "1"}'
+			putobject somebucket "my key" '{"size":42}'
+			getobject somebucket "my key"
+		`, "-output", "json")).To(Equal(0))
+		Expect(stderr.String()).To(BeEmpty())
+		Expect(stdout.String()).To(Equal("{\n  \"size\": 42\n}\n"))
+	})
+
+	It("stops scripts at the first failed command unless -keep-going is set", func(ctx SpecContext) {
+		script := "getbucketattr nosuchbucket\ncreatebucket somebucket {}\n"
+		Expect(runWithStdin(ctx, script)).To(Equal(1))
+		Expect(stderr.String()).To(HavePrefix("line 1: error: "))
+		Expect(stdout.String()).To(BeEmpty())
+
+		Expect(runWithStdin(ctx, script, "-keep-going")).To(Equal(1))
+		Expect(stdout.String()).To(Equal("bucket somebucket created\n"))
+	})
+
+	It("runs script files and stops at the exit command", func(ctx SpecContext) {
+		scriptPath := filepath.Join(GinkgoT().TempDir(), "script")
+		Expect(os.WriteFile(scriptPath, []byte("createbucket somebucket {}\nexit\nunknowncommand\n"),
+			0o644)).To(Succeed())
+		Expect(runWithStdin(ctx, "", "-f", scriptPath)).To(Equal(0))
+		Expect(stdout.String()).To(Equal("bucket somebucket created\n"))
+	})
+
+	It("reads endpoints from a config file", func(ctx SpecContext) {
+		configPath := filepath.Join(GinkgoT().TempDir(), "config.json")
+		Expect(os.WriteFile(configPath, []byte(`{"bootstrap": ["`+
+			strings.TrimPrefix(server.URL, "http://")+`"]}`), 0o644)).To(Succeed())
+		Expect(run(ctx, []string{"-config", configPath, "healthcheck"},
+			strings.NewReader(""), stdout, stderr)).To(Equal(0))
+		Expect(stdout.String()).To(Equal("bucketd is healthy\n"))
+	})
+})
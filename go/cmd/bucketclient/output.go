@@ -0,0 +1,184 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+	"unicode/utf8"
+)
+
+type outputFormat string
+
+const (
+	outputJSON  outputFormat = "json"
+	outputTable outputFormat = "table"
+)
+
+func parseOutputFormat(format string) (outputFormat, error) {
+	switch outputFormat(format) {
+	case outputJSON, outputTable:
+		return outputFormat(format), nil
+	default:
+		return "", fmt.Errorf("invalid output format %q, expected \"json\" or \"table\"", format)
+	}
+}
+
+// maxCellLength is the maximum length of table cells, longer values
+// like object metadata being truncated
+const maxCellLength = 80
+
+// result is the outcome of a successful command
+type result struct {
+	// data is printed as JSON. It may be raw JSON from bucketd, as
+	// json.RawMessage.
+	data any
+	// table is printed in table output, or data as JSON if not set
+	table *table
+	// message is printed in table output when there is no table,
+	// instead of data
+	message string
+	// next holds the options listing the next page of a truncated
+	// listing, nil if there is none
+	next commandOptions
+}
+
+// table is the tabular rendering of the result of a command
+type table struct {
+	header []string
+	rows   [][]string
+	// footer is printed after the rows, e.g. to give the marker of
+	// the next page of a listing
+	footer string
+}
+
+func (t *table) addRow(cells ...string) {
+	t.rows = append(t.rows, cells)
+}
+
+func printResult(out io.Writer, format outputFormat, res result) error {
+	if format == outputTable {
+		if res.table != nil {
+			return printTable(out, res.table)
+		}
+		if res.message != "" {
+			_, err := fmt.Fprintln(out, res.message)
+			return err
+		}
+	}
+	if res.data == nil {
+		return nil
+	}
+	return printJSON(out, res.data)
+}
+
+func printJSON(out io.Writer, data any) error {
+	var encoded bytes.Buffer
+	if raw, isRaw := data.(json.RawMessage); isRaw {
+		if err := json.Indent(&encoded, raw, "", "  "); err != nil {
+			// not JSON: print it as is
+			encoded.Reset()
+			encoded.Write(raw)
+		}
+		encoded.WriteString("\n")
+	} else {
+		encoder := json.NewEncoder(&encoded)
+		encoder.SetEscapeHTML(false)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(data); err != nil {
+			return err
+		}
+	}
+	_, err := out.Write(encoded.Bytes())
+	return err
+}
+
+// expandJSONStrings returns data with its string values holding JSON
+// objects or arrays, like object metadata, replaced by their decoded
+// content
+func expandJSONStrings(data any) (any, error) {
+	raw, isRaw := data.(json.RawMessage)
+	if !isRaw {
+		var err error
+		if raw, err = json.Marshal(data); err != nil {
+			return nil, err
+		}
+	}
+	decoded, err := decodeJSON(raw)
+	if err != nil {
+		// not JSON: keep it as is
+		return data, nil
+	}
+	return expandJSONValue(decoded), nil
+}
+
+func decodeJSON(raw []byte) (any, error) {
+	decoder := json.NewDecoder(bytes.NewReader(raw))
+	// keep numbers as they are, e.g. large integers
+	decoder.UseNumber()
+	var decoded any
+	if err := decoder.Decode(&decoded); err != nil {
+		return nil, err
+	}
+	if decoder.More() {
+		return nil, errors.New("trailing data after JSON value")
+	}
+	return decoded, nil
+}
+
+func expandJSONValue(value any) any {
+	switch typedValue := value.(type) {
+	case map[string]any:
+		for name, fieldValue := range typedValue {
+			typedValue[name] = expandJSONValue(fieldValue)
+		}
+	case []any:
+		for i, item := range typedValue {
+			typedValue[i] = expandJSONValue(item)
+		}
+	case string:
+		trimmed := strings.TrimSpace(typedValue)
+		if !strings.HasPrefix(trimmed, "{") && !strings.HasPrefix(trimmed, "[") {
+			return typedValue
+		}
+		if decoded, err := decodeJSON([]byte(trimmed)); err == nil {
+			return expandJSONValue(decoded)
+		}
+	}
+	return value
+}
+
+func printTable(out io.Writer, t *table) error {
+	writer := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(writer, strings.Join(t.header, "\t"))
+	for _, row := range t.rows {
+		cells := make([]string, len(row))
+		for i, cell := range row {
+			cells[i] = truncateCell(cell)
+		}
+		fmt.Fprintln(writer, strings.Join(cells, "\t"))
+	}
+	if err := writer.Flush(); err != nil {
+		return err
+	}
+	if t.footer != "" {
+		if _, err := fmt.Fprintln(out, t.footer); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// truncateCell makes cell fit on one line of a table
+func truncateCell(cell string) string {
+	cell = strings.NewReplacer("\t", " ", "\n", " ", "\x00", `\0`).Replace(cell)
+	if utf8.RuneCountInString(cell) > maxCellLength {
+		// cut on a character boundary, the length being counted in
+		// characters like tabwriter does
+		return string([]rune(cell)[:maxCellLength-3]) + "..."
+	}
+	return cell
+}
@@ -0,0 +1,84 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("printResult()", func() {
+	var out *bytes.Buffer
+
+	BeforeEach(func() {
+		out = &bytes.Buffer{}
+	})
+
+	res := result{
+		data: []map[string]string{{"key": "a", "value": "<1>"}},
+		table: &table{
+			header: []string{"KEY", "VALUE"},
+			rows: [][]string{
+				{"a", "<1>"},
+				{"long\x00key", strings.Repeat("x", 100)},
+			},
+			footer: "(truncated)",
+		},
+		message: "unused",
+	}
+
+	It("prints tables with long cells truncated", func() {
+		Expect(printResult(out, outputTable, res)).To(Succeed())
+		Expect(out.String()).To(Equal("KEY        VALUE\n" +
+			"a          <1>\n" +
+			`long\0key  ` + strings.Repeat("x", 77) + "...\n" +
+			"(truncated)\n"))
+	})
+
+	It("truncates long cells on a character boundary", func() {
+		Expect(printResult(out, outputTable, result{table: &table{
+			header: []string{"KEY", "VALUE"},
+			rows:   [][]string{{"clé", strings.Repeat("é", 100)}},
+		}})).To(Succeed())
+		Expect(out.String()).To(Equal("KEY  VALUE\n" +
+			"clé  " + strings.Repeat("é", 77) + "...\n"))
+	})
+
+	It("prints data as indented JSON", func() {
+		Expect(printResult(out, outputJSON, res)).To(Succeed())
+		Expect(out.String()).To(Equal("[\n  {\n    \"key\": \"a\",\n    \"value\": \"<1>\"\n  }\n]\n"))
+	})
+
+	It("indents raw JSON and prints other raw data as is", func() {
+		Expect(printResult(out, outputJSON, result{data: json.RawMessage(`{"a":1}`)})).To(Succeed())
+		Expect(printResult(out, outputTable, result{data: json.RawMessage(`not json`)})).To(Succeed())
+		Expect(out.String()).To(Equal("{\n  \"a\": 1\n}\nnot json\n"))
+	})
+
+	It("prints messages in table output only", func() {
+		Expect(printResult(out, outputJSON, result{message: "done"})).To(Succeed())
+		Expect(out.String()).To(BeEmpty())
+		Expect(printResult(out, outputTable, result{message: "done"})).To(Succeed())
+		Expect(out.String()).To(Equal("done\n"))
+	})
+})
+
+var _ = Describe("expandJSONStrings()", func() {
+	It("decodes JSON objects and arrays embedded in strings", func() {
+		expanded, err := expandJSONStrings([]map[string]string{
+			{"key": "a", "value": `{"size":12345678901234567890,"tags":"[\"x\"]"}`},
+			{"key": "b", "value": "{not json"},
+		})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(json.Marshal(expanded)).To(MatchJSON(`[
+			{"key": "a", "value": {"size": 12345678901234567890, "tags": ["x"]}},
+			{"key": "b", "value": "{not json"}
+		]`))
+	})
+
+	It("keeps raw data that is not JSON", func() {
+		Expect(expandJSONStrings(json.RawMessage("not json"))).To(Equal(json.RawMessage("not json")))
+	})
+})
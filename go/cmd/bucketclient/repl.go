@@ -0,0 +1,411 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"golang.org/x/term"
+
+	"github.com/scality/bucketclient/go"
+)
+
+const (
+	// defaultPageSize is the number of entries per page of listings in
+	// the interactive shell when the terminal height is unknown
+	defaultPageSize = 20
+	// maxCompletions is the number of bucket names or keys listed to
+	// complete a word
+	maxCompletions = 100
+	// completionTimeout limits the listings made to complete a word
+	completionTimeout = 2 * time.Second
+	// metastoreBucket is the bucket of bucketd holding the metastore
+	// entries of all buckets, keyed by bucket name
+	metastoreBucket = "__metastore"
+)
+
+// keyOptions are the options of listing commands taking a key, whose
+// value is completed with the keys of the bucket
+var keyOptions = []string{"prefix", "marker", "keyMarker", "lastKeyMarker", "gt", "gte", "lt", "lte"}
+
+// repl is the interactive shell: it reads commands from a terminal with
+// line editing, history and completion of commands, options, bucket
+// names and keys, and pages through listings
+type repl struct {
+	sh       *shell
+	terminal *term.Terminal
+	history  *history
+	// bucket is the current bucket, passed to the commands taking a
+	// bucket as first argument when they are called without it, unless
+	// they are destructive
+	bucket string
+	// pageSize is the number of entries per page of listings, unless
+	// set with the page option of the listing command
+	pageSize int
+	// ctx is the context of the listings made to complete words
+	ctx context.Context
+	// makeRaw puts the terminal in raw mode while reading lines and
+	// returns a function restoring its state. Commands run in the
+	// original state so that they can be interrupted. It is not set
+	// when the input is not a real terminal, e.g. in tests.
+	makeRaw func() (restore func(), err error)
+}
+
+// newREPL returns an interactive shell running the commands of sh on
+// the terminal rw, recording lines in hist
+func newREPL(sh *shell, rw io.ReadWriter, hist *history) *repl {
+	r := &repl{
+		sh:       sh,
+		terminal: term.NewTerminal(rw, ""),
+		history:  hist,
+		pageSize: defaultPageSize,
+		ctx:      context.Background(),
+	}
+	r.terminal.History = hist
+	r.terminal.AutoCompleteCallback = r.autoComplete
+	sh.out = r.terminal
+	sh.errOut = r.terminal
+	sh.pretty = true
+	sh.commands = append(sh.commands,
+		&command{
+			name: "use",
+			args: "[bucket]",
+			help: "set the current bucket, passed to commands called without their bucket argument " +
+				"except destructive ones, or unset it",
+			maxArgs: 1,
+			run:     r.runUse,
+		},
+		&command{
+			name:    "output",
+			args:    "<json|table>",
+			help:    "set the output format",
+			minArgs: 1,
+			maxArgs: 1,
+			run:     r.runOutput,
+		},
+	)
+	return r
+}
+
+// run prompts for commands until the end of input or the exit command,
+// and returns the exit status
+func (r *repl) run(ctx context.Context) int {
+	r.ctx = ctx
+	for {
+		prompt := "client> "
+		if r.bucket != "" {
+			prompt = fmt.Sprintf("client:%s> ", r.bucket)
+		}
+		r.terminal.SetPrompt(prompt)
+		line, err := r.readLine()
+		if errors.Is(err, io.EOF) {
+			// Ctrl-D or Ctrl-C
+			return 0
+		}
+		if err != nil {
+			fmt.Fprintf(r.terminal, "error: %v\n", err)
+			return 1
+		}
+		// interrupting cancels the running command, not the shell
+		commandCtx, stop := signal.NotifyContext(ctx, os.Interrupt)
+		err = r.runLine(commandCtx, line)
+		stop()
+		if errors.Is(err, errExit) {
+			return 0
+		}
+		if err != nil {
+			fmt.Fprintf(r.terminal, "error: %v\n", err)
+		}
+	}
+}
+
+func (r *repl) readLine() (string, error) {
+	if r.makeRaw != nil {
+		restore, err := r.makeRaw()
+		if err != nil {
+			return "", err
+		}
+		defer restore()
+	}
+	line, err := r.terminal.ReadLine()
+	if errors.Is(err, term.ErrPasteIndicator) {
+		// pasted lines are run like typed ones
+		err = nil
+	}
+	return line, err
+}
+
+// runLine runs the command of a line with the current bucket, paging
+// through listings
+func (r *repl) runLine(ctx context.Context, line string) error {
+	line = strings.TrimSpace(line)
+	if line == "" || strings.HasPrefix(line, "#") {
+		return nil
+	}
+	words, err := splitCommandLine(line)
+	if err != nil {
+		return err
+	}
+	cmd, args, opts, err := r.sh.parseCommand(r.withCurrentBucket(words))
+	if err != nil {
+		return err
+	}
+	if cmd.pageOption != "" && !opts.has(cmd.pageOption) {
+		opts[cmd.pageOption] = strconv.Itoa(r.pageSize)
+	}
+	for {
+		res, err := r.sh.execute(ctx, cmd, args, opts)
+		if err != nil {
+			return err
+		}
+		if err := r.sh.print(res); err != nil {
+			return err
+		}
+		if res.next == nil || !r.confirmNextPage() {
+			return nil
+		}
+		opts = res.next
+	}
+}
+
+// withCurrentBucket inserts the current bucket as first argument of the
+// commands taking a bucket when they are given fewer arguments than they
+// need
+func (r *repl) withCurrentBucket(words []string) []string {
+	cmd := r.sh.findCommand(words[0])
+	if cmd == nil {
+		return words
+	}
+	args, _ := parseArguments(cmd, words[1:])
+	if !r.omitsBucket(cmd, len(args)) {
+		return words
+	}
+	return slices.Concat([]string{words[0], r.bucket}, words[1:])
+}
+
+// omitsBucket returns whether a call to cmd with nArgs arguments is
+// given the current bucket as first argument: cmd must take a bucket
+// as first argument and not be destructive, so that deleting or
+// locking data always requires naming the bucket
+func (r *repl) omitsBucket(cmd *command, nArgs int) bool {
+	return r.bucket != "" && strings.HasPrefix(cmd.args, "<bucket>") &&
+		!cmd.destructive && nArgs < cmd.minArgs
+}
+
+// confirmNextPage asks whether to list the next page of a listing
+func (r *repl) confirmNextPage() bool {
+	r.terminal.SetPrompt("-- more: Enter for the next page, q to stop -- ")
+	r.history.paused = true
+	r.terminal.AutoCompleteCallback = nil
+	defer func() {
+		r.history.paused = false
+		r.terminal.AutoCompleteCallback = r.autoComplete
+	}()
+	answer, err := r.readLine()
+	return err == nil && strings.TrimSpace(answer) == ""
+}
+
+func (r *repl) runUse(ctx context.Context, sh *shell, args []string, opts commandOptions) (result, error) {
+	if len(args) == 0 {
+		r.bucket = ""
+		return result{message: "no current bucket"}, nil
+	}
+	if _, err := sh.client.GetBucketAttributes(ctx, args[0]); err != nil {
+		return result{}, err
+	}
+	r.bucket = args[0]
+	return result{message: fmt.Sprintf("current bucket is %s", args[0])}, nil
+}
+
+func (r *repl) runOutput(ctx context.Context, sh *shell, args []string, opts commandOptions) (result, error) {
+	format, err := parseOutputFormat(args[0])
+	if err != nil {
+		return result{}, err
+	}
+	sh.format = format
+	return result{}, nil
+}
+
+// autoComplete completes the word before the cursor when Tab is
+// pressed. When several completions share no longer prefix than the
+// word, they are listed.
+func (r *repl) autoComplete(line string, pos int, key rune) (string, int, bool) {
+	if key != '\t' {
+		return "", 0, false
+	}
+	wordStart := strings.LastIndexAny(line[:pos], " \t") + 1
+	word := line[wordStart:pos]
+	if strings.ContainsAny(word, `'"\`) {
+		// quoted words are not completed
+		return "", 0, false
+	}
+	candidates := r.completions(line[:wordStart], word)
+	if len(candidates) == 0 {
+		return "", 0, false
+	}
+	completion := commonPrefix(candidates)
+	if len(candidates) == 1 {
+		completion = quoteWord(completion)
+		if !strings.HasSuffix(completion, "=") {
+			completion += " "
+		}
+	} else if len(completion) == len(word) || quoteWord(completion) != completion {
+		fmt.Fprintln(r.terminal, strings.Join(candidates, "  "))
+		return line, pos, true
+	}
+	return line[:wordStart] + completion + line[pos:], wordStart + len(completion), true
+}
+
+// completions returns the completions of word, following the words of
+// before on the line
+func (r *repl) completions(before string, word string) []string {
+	words, err := splitCommandLine(before)
+	if err != nil {
+		return nil
+	}
+	if len(words) == 0 {
+		return r.commandNames(word)
+	}
+	cmd := r.sh.findCommand(words[0])
+	if cmd == nil {
+		return nil
+	}
+	args, _ := parseArguments(cmd, words[1:])
+	argNames := strings.Fields(cmd.args)
+	// bucket returns the bucket the line targets, depending on whether
+	// the current bucket is omitted once the word is completed
+	bucket := func(omitted bool) string {
+		if omitted {
+			return r.bucket
+		}
+		if bucketIndex := slices.Index(argNames, "<bucket>"); bucketIndex >= 0 && bucketIndex < len(args) {
+			return args[bucketIndex]
+		}
+		return ""
+	}
+
+	if name, keyPrefix, isOption := strings.Cut(word, "="); isOption {
+		bucket := bucket(r.omitsBucket(cmd, len(args)))
+		if !slices.Contains(keyOptions, name) || !slices.Contains(cmd.options, name) || bucket == "" {
+			return nil
+		}
+		var candidates []string
+		for _, key := range r.listKeys(bucket, keyPrefix) {
+			candidates = append(candidates, name+"="+key)
+		}
+		return candidates
+	}
+	var candidates []string
+	// the word completed as an argument
+	argIndex := len(args)
+	omitted := r.omitsBucket(cmd, len(args)+1)
+	if omitted {
+		argIndex++
+	}
+	if argIndex < len(argNames) {
+		candidates = r.argumentCompletions(argNames[argIndex], bucket(omitted), word)
+	}
+	// the word completed as an option
+	nArgs := len(args)
+	if r.omitsBucket(cmd, nArgs) {
+		nArgs++
+	}
+	if nArgs >= cmd.minArgs {
+		for _, option := range cmd.options {
+			if strings.HasPrefix(option, word) {
+				candidates = append(candidates, option+"=")
+			}
+		}
+	}
+	return candidates
+}
+
+// argumentCompletions returns the completions of word as the argument
+// described by argName, e.g. "<bucket>" or "<read-write|read-only>"
+func (r *repl) argumentCompletions(argName string, bucket string, word string) []string {
+	switch name := strings.Trim(argName, "<>[]"); name {
+	case "bucket":
+		return r.listKeys(metastoreBucket, word)
+	case "key":
+		if bucket == "" {
+			return nil
+		}
+		return r.listKeys(bucket, word)
+	case "command":
+		return r.commandNames(word)
+	default:
+		if !strings.Contains(name, "|") {
+			return nil
+		}
+		var candidates []string
+		for _, alternative := range strings.Split(name, "|") {
+			if strings.HasPrefix(alternative, word) {
+				candidates = append(candidates, alternative)
+			}
+		}
+		return candidates
+	}
+}
+
+func (r *repl) commandNames(prefix string) []string {
+	var names []string
+	for _, cmd := range r.sh.commands {
+		if strings.HasPrefix(cmd.name, prefix) {
+			names = append(names, cmd.name)
+		}
+	}
+	return names
+}
+
+// listKeys returns the first keys of a bucket starting with prefix,
+// leaving out the keys of object versions. Completion is best effort:
+// errors return no key.
+func (r *repl) listKeys(bucket string, prefix string) []string {
+	ctx, cancel := context.WithTimeout(r.ctx, completionTimeout)
+	defer cancel()
+	listOpts := []bucketclient.ListBasicOption{
+		bucketclient.ListBasicMaxKeysOption(maxCompletions),
+		bucketclient.ListBasicNoValuesOption(),
+	}
+	if prefix != "" {
+		listOpts = append(listOpts, bucketclient.ListBasicGTEOption(prefix))
+	}
+	listing, err := r.sh.client.ListBasic(ctx, bucket, listOpts...)
+	if err != nil {
+		return nil
+	}
+	var keys []string
+	for _, entry := range *listing {
+		if !strings.HasPrefix(entry.Key, prefix) {
+			break
+		}
+		if !strings.Contains(entry.Key, "\x00") {
+			keys = append(keys, entry.Key)
+		}
+	}
+	return keys
+}
+
+func commonPrefix(words []string) string {
+	prefix := words[0]
+	for _, word := range words[1:] {
+		length := 0
+		for length < len(prefix) && length < len(word) && prefix[length] == word[length] {
+			length++
+		}
+		prefix = prefix[:length]
+	}
+	// don't cut multi-byte characters
+	for !utf8.ValidString(prefix) {
+		prefix = prefix[:len(prefix)-1]
+	}
+	return prefix
+}
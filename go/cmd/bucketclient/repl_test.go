@@ -0,0 +1,168 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"strings"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/scality/bucketclient/go"
+	"github.com/scality/bucketclient/go/bucketclienttest"
+)
+
+var _ = Describe("repl", func() {
+	var (
+		client *bucketclient.BucketClient
+		input  *strings.Reader
+		output *bytes.Buffer
+		hist   *history
+		r      *repl
+	)
+
+	BeforeEach(func(ctx SpecContext) {
+		server := bucketclienttest.NewServer()
+		DeferCleanup(server.Close)
+		client = server.NewClient()
+		for _, bucketName := range []string{"bucket1", "bucket2", "other"} {
+			Expect(client.CreateBucket(ctx, bucketName, []byte(`{}`))).To(Succeed())
+		}
+		// the fake bucketd has no __metastore bucket listing buckets:
+		// create one
+		Expect(client.CreateBucket(ctx, metastoreBucket, []byte(`{}`))).To(Succeed())
+		Expect(client.PostBatch(ctx, metastoreBucket, []bucketclient.PostBatchEntry{
+			{Key: "bucket1", Value: "{}"}, {Key: "bucket2", Value: "{}"}, {Key: "other", Value: "{}"},
+		})).To(Succeed())
+		Expect(client.PostBatch(ctx, "bucket1", []bucketclient.PostBatchEntry{
+			{Key: "dir/a", Value: `{"versionId":"v1","tags":"{\"color\":\"blue\"}"}`},
+			{Key: "dir/a\x00v1", Value: `{"versionId":"v1"}`},
+			{Key: "dir/b", Value: "{}"},
+			{Key: "file", Value: "{}"},
+			{Key: "my key", Value: "{}"},
+		})).To(Succeed())
+
+		input = strings.NewReader("")
+		output = &bytes.Buffer{}
+		hist = &history{}
+		r = newREPL(newShell(client, nil, nil, outputTable), struct {
+			io.Reader
+			io.Writer
+		}{input, output}, hist)
+	})
+
+	// run runs the shell on the lines of input until its end
+	run := func(ctx context.Context, lines ...string) {
+		input.Reset(strings.Join(lines, "\r") + "\r")
+		ExpectWithOffset(1, r.run(ctx)).To(Equal(0))
+	}
+
+	It("runs commands with the current bucket and pages through listings", func(ctx SpecContext) {
+		r.pageSize = 3
+		run(ctx, "use bucket1", "listbasic", "")
+		Expect(output.String()).To(Equal("client> use bucket1\r\n" +
+			"current bucket is bucket1\r\n" +
+			"client:bucket1> listbasic\r\n" +
+			"KEY        VALUE\r\n" +
+			`dir/a      {"versionId":"v1","tags":"{\"color\":\"blue\"}"}` + "\r\n" +
+			`dir/a\0v1  {"versionId":"v1"}` + "\r\n" +
+			"dir/b      {}\r\n" +
+			"(more keys may follow, next page with gt=dir/b)\r\n" +
+			"-- more: Enter for the next page, q to stop -- \r\n" +
+			"KEY     VALUE\r\n" +
+			"file    {}\r\n" +
+			"my key  {}\r\n" +
+			"client:bucket1> "))
+		Expect(hist.entries).To(Equal([]string{"use bucket1", "listbasic"}))
+	})
+
+	It("requires the bucket of destructive commands", func(ctx SpecContext) {
+		run(ctx, "use bucket1", "deletebucket", "deleterange gte=", "setaccessmode read-only")
+		Expect(output.String()).To(Equal("client> use bucket1\r\n" +
+			"current bucket is bucket1\r\n" +
+			"client:bucket1> deletebucket\r\n" +
+			"error: usage: deletebucket <bucket> [expectedUid=] [idempotent=]\r\n" +
+			"client:bucket1> deleterange gte=\r\n" +
+			"error: usage: deleterange <bucket> [gt=] [gte=] [lt=] [lte=] [prefix=] [batchSize=] " +
+			"[concurrency=] [dryRun=]\r\n" +
+			"client:bucket1> setaccessmode read-only\r\n" +
+			"error: usage: setaccessmode <bucket> <read-write|read-only>\r\n" +
+			"client:bucket1> "))
+		Expect(client.ListBasic(r.ctx, "bucket1")).To(HaveValue(HaveLen(5)))
+	})
+
+	It("stops paging on request", func(ctx SpecContext) {
+		r.pageSize = 1
+		run(ctx, "listversions bucket1", "q", "getbucketattr bucket1")
+		Expect(output.String()).To(Equal("client> listversions bucket1\r\n" +
+			"KEY    VERSION ID  VALUE\r\n" +
+			`dir/a  v1          {"versionId":"v1"}` + "\r\n" +
+			"(truncated, next page with keyMarker=dir/a versionIdMarker=v1)\r\n" +
+			"-- more: Enter for the next page, q to stop -- q\r\n" +
+			"client> getbucketattr bucket1\r\n" +
+			"{}\r\n" +
+			"client> "))
+	})
+
+	It("expands the JSON values of results", func(ctx SpecContext) {
+		run(ctx, "output json", "getobject bucket1 dir/a")
+		Expect(output.String()).To(HaveSuffix("client> getobject bucket1 dir/a\r\n" +
+			"{\r\n" +
+			"  \"tags\": {\r\n" +
+			"    \"color\": \"blue\"\r\n" +
+			"  },\r\n" +
+			"  \"versionId\": \"v1\"\r\n" +
+			"}\r\n" +
+			"client> "))
+	})
+
+	It("reports errors and keeps running until exit", func(ctx SpecContext) {
+		run(ctx, "use nosuchbucket", "getobject", "exit", "help")
+		Expect(output.String()).To(Equal("client> use nosuchbucket\r\n" +
+			"error: error in GetBucketAttributes [GET " + client.Endpoint +
+			"/default/attributes/nosuchbucket]: bucketd returned HTTP status 404 NoSuchBucket\r\n" +
+			"client> getobject\r\n" +
+			"error: usage: getobject <bucket> <key>\r\n" +
+			"client> exit\r\n"))
+	})
+
+	DescribeTable("completes words",
+		func(bucket string, line string, expectedLine string) {
+			r.bucket = bucket
+			r.ctx = context.Background()
+			newLine, newPos, ok := r.autoComplete(line, len(line), '\t')
+			Expect(ok).To(BeTrue())
+			Expect(newLine).To(Equal(expectedLine))
+			Expect(newPos).To(Equal(len(expectedLine)))
+		},
+		Entry("command names", "", "getob", "getobject "),
+		Entry("option names", "", "listbasic bucket1 max", "listbasic bucket1 maxKeys="),
+		Entry("bucket names", "", "getobject bu", "getobject bucket"),
+		Entry("keys", "", "getobject bucket1 f", "getobject bucket1 file "),
+		Entry("quoted keys", "", "getobject bucket1 my", "getobject bucket1 'my key' "),
+		Entry("keys of the current bucket", "bucket1", "getobject fi", "getobject file "),
+		Entry("keys of another bucket", "bucket2", "getobject bucket1 fi", "getobject bucket1 file "),
+		Entry("options with the current bucket", "bucket1", "listbasic max", "listbasic maxKeys="),
+		Entry("buckets of destructive commands", "bucket1", "deleteobject bu", "deleteobject bucket"),
+		Entry("keys as option values", "", "listversions bucket1 keyMarker=f",
+			"listversions bucket1 keyMarker=file "),
+		Entry("alternatives", "", "setaccessmode bucket1 read-o", "setaccessmode bucket1 read-only "),
+	)
+
+	It("lists the completions sharing no longer prefix", func() {
+		newLine, _, ok := r.autoComplete("getobject bucket1 dir/", 22, '\t')
+		Expect(ok).To(BeTrue())
+		Expect(newLine).To(Equal("getobject bucket1 dir/"))
+		Expect(output.String()).To(Equal("dir/a  dir/b\r\n"))
+	})
+
+	It("doesn't complete unknown words or other keys", func() {
+		for _, line := range []string{"nosuchcommand ", "getobject nosuchbucket ", "getobject 'bu"} {
+			_, _, ok := r.autoComplete(line, len(line), '\t')
+			Expect(ok).To(BeFalse(), line)
+		}
+		_, _, ok := r.autoComplete("getob", 5, 'j')
+		Expect(ok).To(BeFalse())
+	})
+})
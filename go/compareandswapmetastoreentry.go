@@ -0,0 +1,59 @@
+package bucketclient
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+)
+
+// MetastoreEntryConflictError is returned by
+// CompareAndSwapMetastoreEntry when the stored metastore entry does
+// not have the expected version
+type MetastoreEntryConflictError struct {
+	BucketName      string
+	ExpectedVersion int
+	// Current is the metastore entry currently stored
+	Current MetastoreEntry
+}
+
+func (e *MetastoreEntryConflictError) Error() string {
+	return fmt.Sprintf("conflict on metastore entry of bucket %s: expected version %d, current version %d",
+		e.BucketName, e.ExpectedVersion, e.Current.Version)
+}
+
+// CompareAndSwapMetastoreEntry replaces the metastore entry for the
+// given bucket with newEntry, only if the stored entry has the
+// expected version. The version of the written entry is set to
+// expectedVersion+1, so that concurrent compare-and-swap operations
+// based on the same version detect the change.
+//
+// If the stored entry has a different version, nothing is written and
+// the returned error has status 409 Conflict and wraps a
+// *MetastoreEntryConflictError containing the current entry.
+//
+// Since bucketd does not support conditional writes of metastore
+// entries, the version is checked by reading the entry just before
+// writing it: this detects most races between clients using
+// compare-and-swap, but a concurrent write may still land between the
+// check and the write.
+func (client *BucketClient) CompareAndSwapMetastoreEntry(ctx context.Context,
+	bucketName string, expectedVersion int, newEntry MetastoreEntry) error {
+	currentEntry, err := client.GetMetastoreEntry(ctx, bucketName)
+	if err != nil {
+		return err
+	}
+	if currentEntry.Version != expectedVersion {
+		return &BucketClientError{
+			"CompareAndSwapMetastoreEntry", "POST", client.Endpoint,
+			fmt.Sprintf("/default/metastore/db/%s", bucketName),
+			http.StatusConflict, "Conflict",
+			&MetastoreEntryConflictError{
+				BucketName:      bucketName,
+				ExpectedVersion: expectedVersion,
+				Current:         currentEntry,
+			},
+		}
+	}
+	newEntry.Version = expectedVersion + 1
+	return client.CreateMetastoreEntry(ctx, bucketName, newEntry)
+}
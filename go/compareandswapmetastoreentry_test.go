@@ -0,0 +1,78 @@
+package bucketclient_test
+
+import (
+	"errors"
+	"io"
+	"net/http"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/jarcoal/httpmock"
+
+	"github.com/scality/bucketclient/go"
+)
+
+var _ = Describe("CompareAndSwapMetastoreEntry()", func() {
+	newEntry := bucketclient.MetastoreEntry{
+		Name:          "my-bucket",
+		Attributes:    "{}",
+		ID:            "1234",
+		RaftSessionID: 3,
+		RaftSession:   "3",
+	}
+
+	It("writes the new entry with an incremented version when the version matches", func(ctx SpecContext) {
+		httpmock.RegisterResponder(
+			"GET", "/default/metastore/db/my-bucket",
+			httpmock.NewStringResponder(200, `{"name":"my-bucket","raftSessionID":1,"version":4}`),
+		)
+		httpmock.RegisterResponder(
+			"POST", "/default/metastore/db/my-bucket",
+			func(req *http.Request) (*http.Response, error) {
+				defer req.Body.Close()
+				Expect(io.ReadAll(req.Body)).To(MatchJSON(`{
+  "name":"my-bucket","attributes":"{}","creating":false,"deleting":false,
+  "id":"1234","raftSessionID":3,"version":5,"raftSession":"3"
+}`))
+				return httpmock.NewStringResponse(200, ""), nil
+			},
+		)
+		Expect(client.CompareAndSwapMetastoreEntry(ctx, "my-bucket", 4, newEntry)).To(Succeed())
+	})
+
+	It("returns a conflict error with the current entry when the version moved", func(ctx SpecContext) {
+		httpmock.RegisterResponder(
+			"GET", "/default/metastore/db/my-bucket",
+			httpmock.NewStringResponder(200, `{"name":"my-bucket","raftSessionID":2,"version":5}`),
+		)
+		err := client.CompareAndSwapMetastoreEntry(ctx, "my-bucket", 4, newEntry)
+		Expect(err).To(MatchError(ContainSubstring("expected version 4, current version 5")))
+		bcErr, ok := err.(*bucketclient.BucketClientError)
+		Expect(ok).To(BeTrue())
+		Expect(bcErr.ApiMethod).To(Equal("CompareAndSwapMetastoreEntry"))
+		Expect(bcErr.StatusCode).To(Equal(http.StatusConflict))
+		Expect(bcErr.ErrorType).To(Equal("Conflict"))
+		var conflictErr *bucketclient.MetastoreEntryConflictError
+		Expect(errors.As(err, &conflictErr)).To(BeTrue())
+		Expect(conflictErr.Current).To(Equal(bucketclient.MetastoreEntry{
+			Name:          "my-bucket",
+			RaftSessionID: 2,
+			Version:       5,
+		}))
+		Expect(httpmock.GetCallCountInfo()).To(Equal(map[string]int{
+			"GET /default/metastore/db/my-bucket": 1,
+		}))
+	})
+
+	It("forwards a 404 error when the entry does not exist", func(ctx SpecContext) {
+		httpmock.RegisterResponder(
+			"GET", "/default/metastore/db/my-bucket",
+			httpmock.NewStringResponder(http.StatusNotFound, ""),
+		)
+		err := client.CompareAndSwapMetastoreEntry(ctx, "my-bucket", 4, newEntry)
+		bcErr, ok := err.(*bucketclient.BucketClientError)
+		Expect(ok).To(BeTrue())
+		Expect(bcErr.StatusCode).To(Equal(http.StatusNotFound))
+	})
+})
@@ -14,3 +14,7 @@ const (
 	DBMethodBatch         DBMethodType = 8
 	DBMethodNoop          DBMethodType = 9
 )
+
+// jsDateLayout formats dates the way Javascript's Date.toJSON() does,
+// which is how dates are stored in metadata
+const jsDateLayout = "2006-01-02T15:04:05.000Z07:00"
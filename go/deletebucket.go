@@ -2,13 +2,92 @@ package bucketclient
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"net/http"
 )
 
+type deleteBucketOptionSet struct {
+	makeIdempotent bool
+	expectedUID    string
+}
+
+type DeleteBucketOption func(*deleteBucketOptionSet)
+
+func DeleteBucketMakeIdempotent(options *deleteBucketOptionSet) {
+	options.makeIdempotent = true
+}
+
+func DeleteBucketExpectedUIDOption(uid string) DeleteBucketOption {
+	return func(options *deleteBucketOptionSet) {
+		options.expectedUID = uid
+	}
+}
+
 // DeleteBucket deletes a bucket entry from metadata.
-func (client *BucketClient) DeleteBucket(ctx context.Context, bucketName string) error {
+// opts is a set of options:
+//
+//	DeleteBucketMakeIdempotent makes the request return a success if the
+//	    bucket does not exist (otherwise returns 404 Not Found, as if the
+//	    option is not passed)
+//
+//	DeleteBucketExpectedUIDOption checks that the bucket UID matches the
+//	    given UID before deleting it, and otherwise returns 412 Precondition
+//	    Failed without deleting it, to avoid deleting a bucket that was
+//	    recreated under the same name
+func (client *BucketClient) DeleteBucket(ctx context.Context, bucketName string,
+	opts ...DeleteBucketOption) error {
+	parsedOpts := deleteBucketOptionSet{
+		makeIdempotent: false,
+		expectedUID:    "",
+	}
+	for _, opt := range opts {
+		opt(&parsedOpts)
+	}
 	resource := fmt.Sprintf("/default/bucket/%s", bucketName)
 
-	_, err := client.Request(ctx, "DeleteBucket", "DELETE", resource)
+	if parsedOpts.expectedUID != "" {
+		existingBucketAttributes, err := client.GetBucketAttributes(ctx, bucketName)
+		if err != nil {
+			if parsedOpts.makeIdempotent && isNotFoundError(err) {
+				return nil
+			}
+			return err
+		}
+		var bucketInfo BucketInfo
+		jsonErr := json.Unmarshal(existingBucketAttributes, &bucketInfo)
+		if jsonErr != nil {
+			return ErrorMalformedResponse("DeleteBucket", "GET", client.Endpoint,
+				fmt.Sprintf("/default/attributes/%s", bucketName), jsonErr)
+		}
+		if bucketInfo.UID != parsedOpts.expectedUID {
+			return &BucketClientError{
+				"DeleteBucket", "DELETE", client.Endpoint, resource,
+				http.StatusPreconditionFailed, "PreconditionFailed",
+				fmt.Errorf("bucket UID '%s' does not match expected UID '%s'",
+					bucketInfo.UID, parsedOpts.expectedUID),
+			}
+		}
+	}
+	var requestOptions []RequestOption
+	if parsedOpts.makeIdempotent {
+		// since we will make the request idempotent, it's okay
+		// to retry it (it may return 404 Not Found at the first
+		// retry if it initially succeeded, but it will then be
+		// considered a success)
+		requestOptions = append(requestOptions, RequestIdempotent)
+	}
+	_, err := client.Request(ctx, "DeleteBucket", "DELETE", resource, requestOptions...)
+	if err != nil && parsedOpts.makeIdempotent && isNotFoundError(err) {
+		return nil
+	}
 	return err
 }
+
+// isNotFoundError returns whether err is a bucketd error with status
+// 404 Not Found.
+func isNotFoundError(err error) bool {
+	var bcErr *BucketClientError
+	return errors.As(err, &bcErr) && bcErr.StatusCode == http.StatusNotFound
+}
@@ -29,4 +29,60 @@ var _ = Describe("DeleteBucket()", func() {
 		Expect(ok).To(BeTrue())
 		Expect(bcErr.StatusCode).To(Equal(http.StatusNotFound))
 	})
+
+	It("returns a success on 404 NotFound with MakeIdempotent option", func(ctx SpecContext) {
+		httpmock.RegisterResponder("DELETE", "/default/bucket/my-bucket",
+			func(req *http.Request) (*http.Response, error) {
+				_, hasHeader := req.Header["Idempotency-Key"]
+				Expect(hasHeader).To(BeTrue())
+				return httpmock.NewStringResponse(http.StatusNotFound, ""), nil
+			})
+
+		Expect(client.DeleteBucket(ctx, "my-bucket",
+			bucketclient.DeleteBucketMakeIdempotent)).To(Succeed())
+	})
+
+	It("forwards other errors with MakeIdempotent option", func(ctx SpecContext) {
+		httpmock.RegisterResponder("DELETE", "/default/bucket/my-bucket",
+			httpmock.NewStringResponder(http.StatusInternalServerError, ""))
+
+		err := client.DeleteBucket(ctx, "my-bucket", bucketclient.DeleteBucketMakeIdempotent)
+		bcErr, ok := err.(*bucketclient.BucketClientError)
+		Expect(ok).To(BeTrue())
+		Expect(bcErr.StatusCode).To(Equal(http.StatusInternalServerError))
+	})
+
+	It("deletes the bucket if its UID matches the expected UID", func(ctx SpecContext) {
+		httpmock.RegisterResponder("GET", "/default/attributes/my-bucket",
+			httpmock.NewStringResponder(200, `{"name":"my-bucket","uid":"4242"}`))
+		httpmock.RegisterResponder("DELETE", "/default/bucket/my-bucket",
+			httpmock.NewStringResponder(200, ""))
+
+		Expect(client.DeleteBucket(ctx, "my-bucket",
+			bucketclient.DeleteBucketExpectedUIDOption("4242"))).To(Succeed())
+	})
+
+	It("does not delete the bucket if its UID does not match the expected UID", func(ctx SpecContext) {
+		httpmock.RegisterResponder("GET", "/default/attributes/my-bucket",
+			httpmock.NewStringResponder(200, `{"name":"my-bucket","uid":"4343"}`))
+
+		err := client.DeleteBucket(ctx, "my-bucket",
+			bucketclient.DeleteBucketMakeIdempotent,
+			bucketclient.DeleteBucketExpectedUIDOption("4242"))
+		bcErr, ok := err.(*bucketclient.BucketClientError)
+		Expect(ok).To(BeTrue())
+		Expect(bcErr.StatusCode).To(Equal(http.StatusPreconditionFailed))
+		Expect(httpmock.GetCallCountInfo()).To(Equal(map[string]int{
+			"GET /default/attributes/my-bucket": 1,
+		}))
+	})
+
+	It("returns a success with an expected UID if the bucket is already gone and MakeIdempotent option", func(ctx SpecContext) {
+		httpmock.RegisterResponder("GET", "/default/attributes/my-bucket",
+			httpmock.NewStringResponder(http.StatusNotFound, ""))
+
+		Expect(client.DeleteBucket(ctx, "my-bucket",
+			bucketclient.DeleteBucketMakeIdempotent,
+			bucketclient.DeleteBucketExpectedUIDOption("4242"))).To(Succeed())
+	})
 })
@@ -3,11 +3,71 @@ package bucketclient
 import (
 	"context"
 	"fmt"
+	"net/http"
 )
 
+type deleteMetastoreEntryOptionSet struct {
+	makeIdempotent bool
+	expectedID     string
+}
+
+type DeleteMetastoreEntryOption func(*deleteMetastoreEntryOptionSet)
+
+func DeleteMetastoreEntryMakeIdempotent(options *deleteMetastoreEntryOptionSet) {
+	options.makeIdempotent = true
+}
+
+func DeleteMetastoreEntryExpectedIDOption(id string) DeleteMetastoreEntryOption {
+	return func(options *deleteMetastoreEntryOptionSet) {
+		options.expectedID = id
+	}
+}
+
 // DeleteMetastoreEntry deletes the metastore entry for the given bucket
-func (client *BucketClient) DeleteMetastoreEntry(ctx context.Context, bucketName string) error {
+// opts is a set of options:
+//
+//	DeleteMetastoreEntryMakeIdempotent makes the request return a success
+//	    if the entry does not exist (otherwise returns 404 Not Found, as if
+//	    the option is not passed)
+//
+//	DeleteMetastoreEntryExpectedIDOption checks that the ID of the entry
+//	    matches the given ID before deleting it, and otherwise returns 412
+//	    Precondition Failed without deleting it
+func (client *BucketClient) DeleteMetastoreEntry(ctx context.Context, bucketName string,
+	opts ...DeleteMetastoreEntryOption) error {
+	parsedOpts := deleteMetastoreEntryOptionSet{
+		makeIdempotent: false,
+		expectedID:     "",
+	}
+	for _, opt := range opts {
+		opt(&parsedOpts)
+	}
 	resource := fmt.Sprintf("/default/metastore/db/%s", bucketName)
-	_, err := client.Request(ctx, "DeleteMetastoreEntry", "DELETE", resource)
+
+	if parsedOpts.expectedID != "" {
+		existingEntry, err := client.GetMetastoreEntry(ctx, bucketName)
+		if err != nil {
+			if parsedOpts.makeIdempotent && isNotFoundError(err) {
+				return nil
+			}
+			return err
+		}
+		if existingEntry.ID != parsedOpts.expectedID {
+			return &BucketClientError{
+				"DeleteMetastoreEntry", "DELETE", client.Endpoint, resource,
+				http.StatusPreconditionFailed, "PreconditionFailed",
+				fmt.Errorf("metastore entry ID '%s' does not match expected ID '%s'",
+					existingEntry.ID, parsedOpts.expectedID),
+			}
+		}
+	}
+	var requestOptions []RequestOption
+	if parsedOpts.makeIdempotent {
+		requestOptions = append(requestOptions, RequestIdempotent)
+	}
+	_, err := client.Request(ctx, "DeleteMetastoreEntry", "DELETE", resource, requestOptions...)
+	if err != nil && parsedOpts.makeIdempotent && isNotFoundError(err) {
+		return nil
+	}
 	return err
 }
@@ -28,4 +28,38 @@ var _ = Describe("DeleteMetastoreEntry()", func() {
 		Expect(ok).To(BeTrue())
 		Expect(bcErr.StatusCode).To(Equal(404))
 	})
+
+	It("returns a success on 404 NotFound with MakeIdempotent option", func(ctx SpecContext) {
+		httpmock.RegisterResponder(
+			"DELETE", "/default/metastore/db/doesnotexist",
+			httpmock.NewStringResponder(404, ""),
+		)
+		Expect(client.DeleteMetastoreEntry(ctx, "doesnotexist",
+			bucketclient.DeleteMetastoreEntryMakeIdempotent)).To(Succeed())
+	})
+
+	It("deletes the entry if its ID matches the expected ID", func(ctx SpecContext) {
+		httpmock.RegisterResponder(
+			"GET", "/default/metastore/db/my-bucket",
+			httpmock.NewStringResponder(200, `{"name":"my-bucket","id":"1234"}`),
+		)
+		httpmock.RegisterResponder(
+			"DELETE", "/default/metastore/db/my-bucket",
+			httpmock.NewStringResponder(200, ""),
+		)
+		Expect(client.DeleteMetastoreEntry(ctx, "my-bucket",
+			bucketclient.DeleteMetastoreEntryExpectedIDOption("1234"))).To(Succeed())
+	})
+
+	It("does not delete the entry if its ID does not match the expected ID", func(ctx SpecContext) {
+		httpmock.RegisterResponder(
+			"GET", "/default/metastore/db/my-bucket",
+			httpmock.NewStringResponder(200, `{"name":"my-bucket","id":"5678"}`),
+		)
+		err := client.DeleteMetastoreEntry(ctx, "my-bucket",
+			bucketclient.DeleteMetastoreEntryExpectedIDOption("1234"))
+		bcErr, ok := err.(*bucketclient.BucketClientError)
+		Expect(ok).To(BeTrue())
+		Expect(bcErr.StatusCode).To(Equal(412))
+	})
 })
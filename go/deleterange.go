@@ -0,0 +1,327 @@
+package bucketclient
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+)
+
+type DeleteRangeOption func(*deleteRangeOptionSet) error
+
+// DeleteRangeGTOption only deletes keys greater than the given argument
+func DeleteRangeGTOption(gt string) DeleteRangeOption {
+	return func(opts *deleteRangeOptionSet) error {
+		opts.gt = &gt
+		return nil
+	}
+}
+
+// DeleteRangeGTEOption only deletes keys greater or equal to the given argument
+func DeleteRangeGTEOption(gte string) DeleteRangeOption {
+	return func(opts *deleteRangeOptionSet) error {
+		opts.gte = &gte
+		return nil
+	}
+}
+
+// DeleteRangeLTOption only deletes keys less than the given argument
+func DeleteRangeLTOption(lt string) DeleteRangeOption {
+	return func(opts *deleteRangeOptionSet) error {
+		opts.lt = &lt
+		return nil
+	}
+}
+
+// DeleteRangeLTEOption only deletes keys less or equal to the given argument
+func DeleteRangeLTEOption(lte string) DeleteRangeOption {
+	return func(opts *deleteRangeOptionSet) error {
+		opts.lte = &lte
+		return nil
+	}
+}
+
+// DeleteRangePrefixOption only deletes keys starting with the given prefix
+func DeleteRangePrefixOption(prefix string) DeleteRangeOption {
+	return func(opts *deleteRangeOptionSet) error {
+		if prefix == "" {
+			return fmt.Errorf("prefix must not be empty")
+		}
+		opts.prefix = &prefix
+		return nil
+	}
+}
+
+// DeleteRangeBatchSizeOption sets the number of keys listed then
+// deleted per request (default 1000, maximum 10000)
+func DeleteRangeBatchSizeOption(batchSize int) DeleteRangeOption {
+	return func(opts *deleteRangeOptionSet) error {
+		if batchSize < 1 || batchSize > 10000 {
+			return fmt.Errorf("batchSize=%d is out of the valid range [1, 10000]", batchSize)
+		}
+		opts.batchSize = batchSize
+		return nil
+	}
+}
+
+// DeleteRangeConcurrencyOption sets the maximum number of delete
+// batches in flight at the same time (default 1)
+func DeleteRangeConcurrencyOption(concurrency int) DeleteRangeOption {
+	return func(opts *deleteRangeOptionSet) error {
+		if concurrency < 1 {
+			return fmt.Errorf("concurrency=%d must be at least 1", concurrency)
+		}
+		opts.concurrency = concurrency
+		return nil
+	}
+}
+
+// DeleteRangeDryRunOption only lists and counts the keys in range,
+// without deleting them
+func DeleteRangeDryRunOption() DeleteRangeOption {
+	return func(opts *deleteRangeOptionSet) error {
+		opts.dryRun = true
+		return nil
+	}
+}
+
+// DeleteRangeProgressOption sets a function called after each batch of
+// keys has been deleted, or listed in dry-run mode. Calls are never
+// concurrent.
+func DeleteRangeProgressOption(progress func(DeleteRangeProgress)) DeleteRangeOption {
+	return func(opts *deleteRangeOptionSet) error {
+		opts.progress = progress
+		return nil
+	}
+}
+
+// DeleteRangeProgress reports the progress of a DeleteRange call
+type DeleteRangeProgress struct {
+	// Listed is the number of keys in range listed so far
+	Listed int
+	// Deleted is the number of keys deleted so far, always zero in
+	// dry-run mode
+	Deleted int
+}
+
+type deleteRangeOptionSet struct {
+	gt          *string
+	gte         *string
+	lt          *string
+	lte         *string
+	prefix      *string
+	batchSize   int
+	concurrency int
+	dryRun      bool
+	progress    func(DeleteRangeProgress)
+}
+
+func parseDeleteRangeOptions(opts []DeleteRangeOption) (deleteRangeOptionSet, error) {
+	parsedOpts := deleteRangeOptionSet{
+		batchSize:   1000,
+		concurrency: 1,
+	}
+	for _, opt := range opts {
+		err := opt(&parsedOpts)
+		if err != nil {
+			return parsedOpts, err
+		}
+	}
+	if parsedOpts.gt == nil && parsedOpts.gte == nil &&
+		parsedOpts.lt == nil && parsedOpts.lte == nil && parsedOpts.prefix == nil {
+		return parsedOpts, fmt.Errorf(
+			"at least one bound or prefix is required, use DeleteRangeGTEOption(\"\") to delete all keys")
+	}
+	return parsedOpts, nil
+}
+
+// listOptions combines the bounds and prefix into the tightest pair of
+// ListBasic bounds, returning the lower bound option separately so
+// that it can be moved forward after each page. It returns false if
+// the range is empty.
+func (opts *deleteRangeOptionSet) listOptions() (ListBasicOption, []ListBasicOption, bool) {
+	var lower, upper *string
+	lowerInclusive, upperInclusive := false, false
+
+	tightenLower := func(bound string, inclusive bool) {
+		if lower == nil || bound > *lower || (bound == *lower && !inclusive) {
+			lower, lowerInclusive = &bound, inclusive
+		}
+	}
+	tightenUpper := func(bound string, inclusive bool) {
+		if upper == nil || bound < *upper || (bound == *upper && !inclusive) {
+			upper, upperInclusive = &bound, inclusive
+		}
+	}
+	if opts.gt != nil {
+		tightenLower(*opts.gt, false)
+	}
+	if opts.gte != nil {
+		tightenLower(*opts.gte, true)
+	}
+	if opts.prefix != nil {
+		tightenLower(*opts.prefix, true)
+		if end, ok := prefixEnd(*opts.prefix); ok {
+			tightenUpper(end, false)
+		}
+	}
+	if opts.lt != nil {
+		tightenUpper(*opts.lt, false)
+	}
+	if opts.lte != nil {
+		tightenUpper(*opts.lte, true)
+	}
+
+	var lowerOpt ListBasicOption
+	if lower != nil {
+		if lowerInclusive {
+			lowerOpt = ListBasicGTEOption(*lower)
+		} else {
+			lowerOpt = ListBasicGTOption(*lower)
+		}
+	}
+	otherOpts := []ListBasicOption{
+		ListBasicMaxKeysOption(opts.batchSize),
+		ListBasicNoValuesOption(),
+	}
+	if upper != nil {
+		if upperInclusive {
+			otherOpts = append(otherOpts, ListBasicLTEOption(*upper))
+		} else {
+			otherOpts = append(otherOpts, ListBasicLTOption(*upper))
+		}
+	}
+	if lower != nil && upper != nil &&
+		(*lower > *upper || (*lower == *upper && !(lowerInclusive && upperInclusive))) {
+		return nil, nil, false
+	}
+	return lowerOpt, otherOpts, true
+}
+
+// DeleteRange deletes all keys of a bucket within the given bounds,
+// which behave like those of ListBasic, and returns the number of
+// deleted keys (or of keys in range in dry-run mode).
+//
+// At least one bound or prefix must be given, to avoid emptying a
+// bucket by mistake. When several bounds are given, only keys
+// satisfying all of them are deleted.
+//
+// Keys are listed page by page without their values, and each page is
+// deleted with a single PostBatch request, possibly concurrently with
+// listing the next pages. On error, DeleteRange stops listing, waits
+// for batches in flight and returns the number of keys deleted so far
+// along with the first error.
+func (client *BucketClient) DeleteRange(ctx context.Context, bucketName string,
+	opts ...DeleteRangeOption) (int, error) {
+	resource := fmt.Sprintf("/default/bucket/%s", bucketName)
+	options, err := parseDeleteRangeOptions(opts)
+	if err != nil {
+		return 0, &BucketClientError{
+			"DeleteRange", "GET", client.Endpoint, resource, 0, "", err,
+		}
+	}
+	lowerOpt, otherOpts, nonEmpty := options.listOptions()
+	if !nonEmpty {
+		return 0, nil
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var (
+		mutex    sync.Mutex
+		wg       sync.WaitGroup
+		progress DeleteRangeProgress
+		firstErr error
+	)
+	// failed records the first error and stops all work
+	failed := func(err error) {
+		mutex.Lock()
+		defer mutex.Unlock()
+		if firstErr == nil {
+			firstErr = err
+			cancel()
+		}
+	}
+	// update updates and reports progress, unless an error occurred
+	update := func(listed int, deleted int, notify bool) {
+		mutex.Lock()
+		defer mutex.Unlock()
+		progress.Listed += listed
+		progress.Deleted += deleted
+		if notify && firstErr == nil && options.progress != nil {
+			options.progress(progress)
+		}
+	}
+	slots := make(chan struct{}, options.concurrency)
+
+listing:
+	for {
+		listOpts := otherOpts[:len(otherOpts):len(otherOpts)]
+		if lowerOpt != nil {
+			listOpts = append(listOpts, lowerOpt)
+		}
+		page, err := client.ListBasic(ctx, bucketName, listOpts...)
+		if err != nil {
+			failed(err)
+			break
+		}
+		if len(*page) == 0 {
+			break
+		}
+		// never delete keys outside of the prefix, should bucketd order
+		// keys differently from prefixEnd, and stop listing past them
+		var batch []PostBatchEntry
+		pastPrefix := false
+		for _, entry := range *page {
+			if options.prefix != nil && !strings.HasPrefix(entry.Key, *options.prefix) {
+				if entry.Key > *options.prefix {
+					pastPrefix = true
+					break
+				}
+				continue
+			}
+			batch = append(batch, PostBatchEntry{Key: entry.Key, Type: "del"})
+		}
+		update(len(batch), 0, options.dryRun)
+		if !options.dryRun && len(batch) > 0 {
+			select {
+			case slots <- struct{}{}:
+			case <-ctx.Done():
+				failed(ctx.Err())
+				break listing
+			}
+			if ctx.Err() != nil {
+				// both cases may have been ready after a
+				// failed batch released its slot
+				<-slots
+				failed(ctx.Err())
+				break listing
+			}
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				defer func() { <-slots }()
+				err := client.PostBatch(ctx, bucketName, batch)
+				if err != nil {
+					failed(err)
+					return
+				}
+				update(0, len(batch), true)
+			}()
+		}
+		if pastPrefix {
+			break
+		}
+		// list the next page, starting after the last listed key
+		lowerOpt = ListBasicGTOption((*page)[len(*page)-1].Key)
+	}
+	wg.Wait()
+
+	mutex.Lock()
+	defer mutex.Unlock()
+	if options.dryRun {
+		return progress.Listed, firstErr
+	}
+	return progress.Deleted, firstErr
+}
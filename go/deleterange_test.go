@@ -0,0 +1,219 @@
+package bucketclient_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"slices"
+	"sync"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/jarcoal/httpmock"
+
+	"github.com/scality/bucketclient/go"
+)
+
+var _ = Describe("DeleteRange()", func() {
+	var (
+		mutex       sync.Mutex
+		keys        []string
+		listQueries []string
+		batchCount  int
+		failBatch   int
+		// ignoreLT lists keys past lt bounds, like a bucketd decoding
+		// them differently
+		ignoreLT bool
+	)
+
+	BeforeEach(func() {
+		keys = []string{}
+		for _, prefix := range []string{"a", "b", "c"} {
+			for i := 0; i < 5; i++ {
+				keys = append(keys, fmt.Sprintf("%s/%d", prefix, i))
+			}
+		}
+		listQueries = nil
+		batchCount = 0
+		failBatch = 0
+		ignoreLT = false
+
+		httpmock.RegisterResponder(
+			"GET", "/default/bucket/somebucket",
+			func(req *http.Request) (*http.Response, error) {
+				mutex.Lock()
+				defer mutex.Unlock()
+				query := req.URL.Query()
+				listQueries = append(listQueries, req.URL.RawQuery)
+				Expect(query.Get("listingType")).To(Equal("Basic"))
+				Expect(query.Get("values")).To(Equal("false"))
+				var maxKeys int
+				fmt.Sscan(query.Get("maxKeys"), &maxKeys)
+				page := []bucketclient.ListBasicEntry{}
+				for _, key := range keys {
+					if (query.Has("gt") && key <= query.Get("gt")) ||
+						(query.Has("gte") && key < query.Get("gte")) ||
+						(!ignoreLT && query.Has("lt") && key >= query.Get("lt")) ||
+						(query.Has("lte") && key > query.Get("lte")) {
+						continue
+					}
+					if len(page) == maxKeys {
+						break
+					}
+					page = append(page, bucketclient.ListBasicEntry{Key: key})
+				}
+				return httpmock.NewJsonResponse(200, page)
+			},
+		)
+		httpmock.RegisterResponder(
+			"POST", "/default/batch/somebucket",
+			func(req *http.Request) (*http.Response, error) {
+				mutex.Lock()
+				defer mutex.Unlock()
+				batchCount += 1
+				if batchCount == failBatch {
+					return httpmock.NewStringResponse(500, ""), nil
+				}
+				var body struct {
+					Batch []bucketclient.PostBatchEntry `json:"batch"`
+				}
+				Expect(json.NewDecoder(req.Body).Decode(&body)).To(Succeed())
+				for _, entry := range body.Batch {
+					Expect(entry.Type).To(Equal("del"))
+					keys = slices.DeleteFunc(keys, func(key string) bool {
+						return key == entry.Key
+					})
+				}
+				return httpmock.NewStringResponse(200, ""), nil
+			},
+		)
+	})
+
+	It("deletes all keys with a prefix in batches", func(ctx SpecContext) {
+		var progress []bucketclient.DeleteRangeProgress
+		deleted, err := client.DeleteRange(ctx, "somebucket",
+			bucketclient.DeleteRangePrefixOption("b/"),
+			bucketclient.DeleteRangeBatchSizeOption(2),
+			bucketclient.DeleteRangeProgressOption(func(p bucketclient.DeleteRangeProgress) {
+				progress = append(progress, p)
+			}))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(deleted).To(Equal(5))
+		Expect(keys).To(Equal([]string{
+			"a/0", "a/1", "a/2", "a/3", "a/4", "c/0", "c/1", "c/2", "c/3", "c/4",
+		}))
+		Expect(batchCount).To(Equal(3))
+		// listing may run ahead of deletion
+		Expect(progress).To(HaveLen(3))
+		for i, deleted := range []int{2, 4, 5} {
+			Expect(progress[i].Deleted).To(Equal(deleted))
+			Expect(progress[i].Listed).To(BeNumerically(">=", deleted))
+		}
+		Expect(progress[2].Listed).To(Equal(5))
+		Expect(listQueries[0]).To(Equal("gte=b%2F&listingType=Basic&lt=b0&maxKeys=2&values=false"))
+		Expect(listQueries[1]).To(Equal("gt=b%2F1&listingType=Basic&lt=b0&maxKeys=2&values=false"))
+	})
+
+	DescribeTable("deletes keys with a prefix ending with any character",
+		func(ctx SpecContext, prefix string, end string) {
+			keys = []string{prefix, prefix + "a", end, end + "a", "\U0010FFFF"}
+			deleted, err := client.DeleteRange(ctx, "somebucket",
+				bucketclient.DeleteRangePrefixOption(prefix))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(deleted).To(Equal(2))
+			Expect(keys).To(Equal([]string{end, end + "a", "\U0010FFFF"}))
+			Expect(listQueries).To(HaveLen(2))
+			query, err := url.ParseQuery(listQueries[0])
+			Expect(err).ToNot(HaveOccurred())
+			Expect(query.Get("lt")).To(Equal(end))
+		},
+		Entry("ASCII DEL", "x\x7f", "x\u0080"),
+		Entry("multi-byte character", "x\u00bf", "x\u00c0"),
+		Entry("U+FFFD", "a\uFFFD", "a\uFFFE"),
+		Entry("last character before surrogates", "a\uD7FF", "a\uE000"),
+	)
+
+	It("stops listing past the prefix", func(ctx SpecContext) {
+		ignoreLT = true
+		deleted, err := client.DeleteRange(ctx, "somebucket",
+			bucketclient.DeleteRangePrefixOption("b/"),
+			bucketclient.DeleteRangeBatchSizeOption(2))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(deleted).To(Equal(5))
+		Expect(keys).To(Equal([]string{
+			"a/0", "a/1", "a/2", "a/3", "a/4", "c/0", "c/1", "c/2", "c/3", "c/4",
+		}))
+		Expect(listQueries).To(HaveLen(3))
+	})
+
+	It("combines bounds with a prefix", func(ctx SpecContext) {
+		deleted, err := client.DeleteRange(ctx, "somebucket",
+			bucketclient.DeleteRangePrefixOption("a/"),
+			bucketclient.DeleteRangeGTOption("a/1"),
+			bucketclient.DeleteRangeLTEOption("b/3"))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(deleted).To(Equal(3))
+		Expect(keys).To(HaveLen(12))
+		Expect(keys).ToNot(ContainElements("a/2", "a/3", "a/4"))
+		Expect(listQueries[0]).To(Equal("gt=a%2F1&listingType=Basic&lt=a0&maxKeys=1000&values=false"))
+	})
+
+	It("deletes batches concurrently", func(ctx SpecContext) {
+		deleted, err := client.DeleteRange(ctx, "somebucket",
+			bucketclient.DeleteRangeGTEOption(""),
+			bucketclient.DeleteRangeBatchSizeOption(2),
+			bucketclient.DeleteRangeConcurrencyOption(4))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(deleted).To(Equal(15))
+		Expect(keys).To(BeEmpty())
+		Expect(batchCount).To(Equal(8))
+	})
+
+	It("only counts keys in dry-run mode", func(ctx SpecContext) {
+		var progress []bucketclient.DeleteRangeProgress
+		count, err := client.DeleteRange(ctx, "somebucket",
+			bucketclient.DeleteRangeLTOption("b/2"),
+			bucketclient.DeleteRangeBatchSizeOption(4),
+			bucketclient.DeleteRangeDryRunOption(),
+			bucketclient.DeleteRangeProgressOption(func(p bucketclient.DeleteRangeProgress) {
+				progress = append(progress, p)
+			}))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(count).To(Equal(7))
+		Expect(keys).To(HaveLen(15))
+		Expect(batchCount).To(Equal(0))
+		Expect(progress).To(Equal([]bucketclient.DeleteRangeProgress{
+			{Listed: 4}, {Listed: 7},
+		}))
+	})
+
+	It("does nothing on an empty range", func(ctx SpecContext) {
+		deleted, err := client.DeleteRange(ctx, "somebucket",
+			bucketclient.DeleteRangeGTOption("b"),
+			bucketclient.DeleteRangeLTEOption("a"))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(deleted).To(Equal(0))
+		Expect(listQueries).To(BeEmpty())
+	})
+
+	It("requires at least one bound", func(ctx SpecContext) {
+		_, err := client.DeleteRange(ctx, "somebucket")
+		Expect(err).To(MatchError(ContainSubstring("at least one bound")))
+		Expect(listQueries).To(BeEmpty())
+	})
+
+	It("stops on the first failed batch", func(ctx SpecContext) {
+		failBatch = 2
+		deleted, err := client.DeleteRange(ctx, "somebucket",
+			bucketclient.DeleteRangePrefixOption("a/"),
+			bucketclient.DeleteRangeBatchSizeOption(2))
+		Expect(err).To(HaveOccurred())
+		bcErr, ok := err.(*bucketclient.BucketClientError)
+		Expect(ok).To(BeTrue())
+		Expect(bcErr.StatusCode).To(Equal(500))
+		Expect(deleted).To(Equal(2))
+		Expect(keys).To(HaveLen(13))
+	})
+})
@@ -0,0 +1,253 @@
+package bucketclient
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"sync"
+	"time"
+)
+
+type HedgingOption func(*hedgingOptionSet)
+
+// HedgingDelayOption sets the delay after which a read request still
+// pending is sent to a secondary endpoint (default 100ms). When
+// HedgingPercentileOption is also given, the delay is only used until
+// enough latencies have been observed.
+func HedgingDelayOption(delay time.Duration) HedgingOption {
+	return func(opts *hedgingOptionSet) {
+		opts.delay = max(delay, 0)
+	}
+}
+
+// HedgingPercentileOption derives the hedging delay from the latency
+// of recent successful read requests: a request is hedged when it
+// takes longer than the given percentile of them, in the range
+// (0, 100], for example 95.
+func HedgingPercentileOption(percentile float64) HedgingOption {
+	return func(opts *hedgingOptionSet) {
+		opts.percentile = min(max(percentile, 0), 100)
+	}
+}
+
+type hedgingOptionSet struct {
+	delay      time.Duration
+	percentile float64
+}
+
+// BucketClientHedgingOption enables hedged reads: GET requests still
+// pending after a delay are sent again to one of the secondary
+// endpoints, in turn. The first successful response is used and the
+// other request is cancelled.
+//
+// A transport error or 5xx response from one endpoint does not end
+// the request as long as the other one may still succeed: if the
+// request was not hedged yet, it is hedged immediately.
+//
+// Requests modifying metadata are always sent to the primary
+// endpoint only.
+func BucketClientHedgingOption(secondaryEndpoints []string, opts ...HedgingOption) BucketClientOption {
+	options := hedgingOptionSet{
+		delay: 100 * time.Millisecond,
+	}
+	for _, opt := range opts {
+		opt(&options)
+	}
+	return func(client *BucketClient) {
+		if len(secondaryEndpoints) == 0 {
+			client.hedger = nil
+			return
+		}
+		client.hedger = &hedger{
+			secondaryEndpoints: slices.Clone(secondaryEndpoints),
+			delay:              options.delay,
+			percentile:         options.percentile,
+			latencies:          make([]time.Duration, 0, hedgingLatencySamples),
+			wins:               map[string]int64{},
+		}
+	}
+}
+
+// HedgingStats reports how often hedging triggered and which
+// endpoints won
+type HedgingStats struct {
+	// Requests is the number of read requests eligible for hedging
+	Requests int64
+	// Hedged is the number of read requests sent to a secondary
+	// endpoint
+	Hedged int64
+	// Wins is the number of read requests answered first by each
+	// endpoint
+	Wins map[string]int64
+}
+
+// HedgingStats returns statistics on hedged reads, or empty statistics
+// if hedging is not enabled.
+func (client *BucketClient) HedgingStats() HedgingStats {
+	if client.hedger == nil {
+		return HedgingStats{Wins: map[string]int64{}}
+	}
+	return client.hedger.stats()
+}
+
+const (
+	// number of latencies kept to compute the hedging percentile
+	hedgingLatencySamples = 1000
+	// minimum number of latencies required to use the percentile
+	hedgingMinLatencySamples = 20
+	// number of new latencies after which the percentile is
+	// computed again
+	hedgingPercentileRefresh = 50
+)
+
+type hedger struct {
+	secondaryEndpoints []string
+	delay              time.Duration
+	percentile         float64
+
+	mutex sync.Mutex
+	// next secondary endpoint to use
+	nextSecondary int
+	// ring buffer of latencies of recent successful requests
+	latencies       []time.Duration
+	nextLatency     int
+	newLatencies    int
+	percentileDelay time.Duration
+
+	requests int64
+	hedged   int64
+	wins     map[string]int64
+}
+
+type hedgedResult struct {
+	endpoint     string
+	responseBody []byte
+	err          error
+	latency      time.Duration
+}
+
+// do sends the request to the primary endpoint, then to a secondary
+// endpoint if needed, and returns the first successful response
+func (h *hedger) do(ctx context.Context, primaryEndpoint string,
+	send func(ctx context.Context, endpoint string) ([]byte, error)) ([]byte, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	// cancel the losing request
+	defer cancel()
+
+	results := make(chan hedgedResult, 2)
+	attempt := func(endpoint string, attemptNumber int) {
+		start := time.Now()
+		responseBody, err := send(contextWithRequestAttempt(ctx, attemptNumber), endpoint)
+		results <- hedgedResult{endpoint, responseBody, err, time.Since(start)}
+	}
+	delay, secondaryEndpoint := h.startRequest()
+	go attempt(primaryEndpoint, 1)
+	pending := 1
+	hedged := false
+	hedge := func() {
+		hedged = true
+		pending += 1
+		h.recordHedged()
+		go attempt(secondaryEndpoint, 2)
+	}
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-timer.C:
+			if !hedged {
+				hedge()
+			}
+		case result := <-results:
+			pending -= 1
+			h.recordResult(result)
+			if result.err == nil || !isRetryableError(result.err) {
+				return result.responseBody, result.err
+			}
+			if !hedged && ctx.Err() == nil {
+				hedge()
+			} else if pending == 0 {
+				return nil, result.err
+			}
+		}
+	}
+}
+
+// isRetryableError returns whether err may not happen when sending
+// the same request to another endpoint, i.e. a transport error or a
+// 5xx response
+func isRetryableError(err error) bool {
+	var bcErr *BucketClientError
+	if !errors.As(err, &bcErr) {
+		return false
+	}
+	return bcErr.StatusCode == 0 || bcErr.StatusCode/100 == 5
+}
+
+// startRequest counts a new request and returns the hedging delay and
+// secondary endpoint to use for it
+func (h *hedger) startRequest() (time.Duration, string) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	h.requests += 1
+	endpoint := h.secondaryEndpoints[h.nextSecondary]
+	h.nextSecondary = (h.nextSecondary + 1) % len(h.secondaryEndpoints)
+	if h.percentile > 0 && len(h.latencies) >= hedgingMinLatencySamples {
+		if h.percentileDelay == 0 || h.newLatencies >= hedgingPercentileRefresh {
+			h.percentileDelay = h.computePercentile()
+			h.newLatencies = 0
+		}
+		return h.percentileDelay, endpoint
+	}
+	return h.delay, endpoint
+}
+
+func (h *hedger) computePercentile() time.Duration {
+	sorted := slices.Clone(h.latencies)
+	slices.Sort(sorted)
+	index := int(float64(len(sorted))*h.percentile/100+0.5) - 1
+	return sorted[min(max(index, 0), len(sorted)-1)]
+}
+
+func (h *hedger) recordHedged() {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	h.hedged += 1
+}
+
+// recordResult counts the endpoint of a response ending the request
+// as the winner, and records its latency if successful. Retryable
+// errors let the other endpoint answer, so they win nothing.
+func (h *hedger) recordResult(result hedgedResult) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	if result.err != nil {
+		if !isRetryableError(result.err) {
+			h.wins[result.endpoint] += 1
+		}
+		return
+	}
+	h.wins[result.endpoint] += 1
+	if len(h.latencies) < hedgingLatencySamples {
+		h.latencies = append(h.latencies, result.latency)
+	} else {
+		h.latencies[h.nextLatency] = result.latency
+		h.nextLatency = (h.nextLatency + 1) % hedgingLatencySamples
+	}
+	h.newLatencies += 1
+}
+
+func (h *hedger) stats() HedgingStats {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	wins := make(map[string]int64, len(h.wins))
+	for endpoint, count := range h.wins {
+		wins[endpoint] = count
+	}
+	return HedgingStats{
+		Requests: h.requests,
+		Hedged:   h.hedged,
+		Wins:     wins,
+	}
+}
@@ -0,0 +1,185 @@
+package bucketclient_test
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/jarcoal/httpmock"
+
+	"github.com/scality/bucketclient/go"
+)
+
+var _ = Describe("BucketClientHedgingOption", func() {
+	var hedgingClient *bucketclient.BucketClient
+
+	// delayedResponder responds after the given delay, unless the
+	// request is cancelled first
+	delayedResponder := func(delay time.Duration, status int, body string,
+		canceled chan<- struct{}) httpmock.Responder {
+		return func(req *http.Request) (*http.Response, error) {
+			select {
+			case <-time.After(delay):
+				return httpmock.NewStringResponse(status, body), nil
+			case <-req.Context().Done():
+				if canceled != nil {
+					canceled <- struct{}{}
+				}
+				return nil, req.Context().Err()
+			}
+		}
+	}
+
+	BeforeEach(func() {
+		hedgingClient = bucketclient.New("http://localhost:9000",
+			bucketclient.BucketClientHedgingOption(
+				[]string{"http://localhost:9001"},
+				bucketclient.HedgingDelayOption(20*time.Millisecond)))
+	})
+
+	It("does not hedge fast requests", func(ctx SpecContext) {
+		httpmock.RegisterResponder(
+			"GET", "http://localhost:9000/default/attributes/somebucket",
+			httpmock.NewStringResponder(200, `{"primary":true}`),
+		)
+		Expect(hedgingClient.GetBucketAttributes(ctx, "somebucket")).To(
+			Equal([]byte(`{"primary":true}`)))
+		Expect(httpmock.GetTotalCallCount()).To(Equal(1))
+		Expect(hedgingClient.HedgingStats()).To(Equal(bucketclient.HedgingStats{
+			Requests: 1,
+			Hedged:   0,
+			Wins:     map[string]int64{"http://localhost:9000": 1},
+		}))
+	})
+
+	It("uses the secondary endpoint when the primary is slow", func(ctx SpecContext) {
+		primaryCanceled := make(chan struct{}, 1)
+		httpmock.RegisterResponder(
+			"GET", "http://localhost:9000/default/attributes/somebucket",
+			delayedResponder(time.Second, 200, `{"primary":true}`, primaryCanceled),
+		)
+		httpmock.RegisterResponder(
+			"GET", "http://localhost:9001/default/attributes/somebucket",
+			httpmock.NewStringResponder(200, `{"secondary":true}`),
+		)
+		start := time.Now()
+		Expect(hedgingClient.GetBucketAttributes(ctx, "somebucket")).To(
+			Equal([]byte(`{"secondary":true}`)))
+		Expect(time.Since(start)).To(BeNumerically("<", 500*time.Millisecond))
+		Eventually(primaryCanceled).Should(Receive())
+		Expect(hedgingClient.HedgingStats()).To(Equal(bucketclient.HedgingStats{
+			Requests: 1,
+			Hedged:   1,
+			Wins:     map[string]int64{"http://localhost:9001": 1},
+		}))
+	})
+
+	It("keeps the primary response if it arrives first after hedging", func(ctx SpecContext) {
+		secondaryCanceled := make(chan struct{}, 1)
+		httpmock.RegisterResponder(
+			"GET", "http://localhost:9000/default/attributes/somebucket",
+			delayedResponder(40*time.Millisecond, 200, `{"primary":true}`, nil),
+		)
+		httpmock.RegisterResponder(
+			"GET", "http://localhost:9001/default/attributes/somebucket",
+			delayedResponder(time.Second, 200, `{"secondary":true}`, secondaryCanceled),
+		)
+		Expect(hedgingClient.GetBucketAttributes(ctx, "somebucket")).To(
+			Equal([]byte(`{"primary":true}`)))
+		Eventually(secondaryCanceled).Should(Receive())
+		stats := hedgingClient.HedgingStats()
+		Expect(stats.Hedged).To(Equal(int64(1)))
+		Expect(stats.Wins).To(Equal(map[string]int64{"http://localhost:9000": 1}))
+	})
+
+	It("hedges immediately after a 5xx error of the primary", func(ctx SpecContext) {
+		hedgingClient = bucketclient.New("http://localhost:9000",
+			bucketclient.BucketClientHedgingOption(
+				[]string{"http://localhost:9001"},
+				bucketclient.HedgingDelayOption(time.Hour)))
+		httpmock.RegisterResponder(
+			"GET", "http://localhost:9000/default/attributes/somebucket",
+			httpmock.NewStringResponder(503, ""),
+		)
+		httpmock.RegisterResponder(
+			"GET", "http://localhost:9001/default/attributes/somebucket",
+			httpmock.NewStringResponder(200, `{"secondary":true}`),
+		)
+		Expect(hedgingClient.GetBucketAttributes(ctx, "somebucket")).To(
+			Equal([]byte(`{"secondary":true}`)))
+		Expect(hedgingClient.HedgingStats().Wins).To(Equal(
+			map[string]int64{"http://localhost:9001": 1}))
+	})
+
+	It("returns the error when both endpoints fail", func(ctx SpecContext) {
+		httpmock.RegisterResponder(
+			"GET", "http://localhost:9000/default/attributes/somebucket",
+			httpmock.NewStringResponder(503, ""),
+		)
+		httpmock.RegisterResponder(
+			"GET", "http://localhost:9001/default/attributes/somebucket",
+			httpmock.NewStringResponder(500, ""),
+		)
+		_, err := hedgingClient.GetBucketAttributes(ctx, "somebucket")
+		var bcErr *bucketclient.BucketClientError
+		Expect(errors.As(err, &bcErr)).To(BeTrue())
+		Expect(bcErr.StatusCode).To(Equal(500))
+		Expect(bcErr.Endpoint).To(Equal("http://localhost:9001"))
+		Expect(hedgingClient.HedgingStats().Wins).To(BeEmpty())
+	})
+
+	It("does not hedge after a 4xx response", func(ctx SpecContext) {
+		httpmock.RegisterResponder(
+			"GET", "http://localhost:9000/default/attributes/somebucket",
+			httpmock.NewStringResponder(404, ""),
+		)
+		_, err := hedgingClient.GetBucketAttributes(ctx, "somebucket")
+		var bcErr *bucketclient.BucketClientError
+		Expect(errors.As(err, &bcErr)).To(BeTrue())
+		Expect(bcErr.StatusCode).To(Equal(404))
+		Expect(httpmock.GetTotalCallCount()).To(Equal(1))
+		Expect(hedgingClient.HedgingStats().Wins).To(Equal(
+			map[string]int64{"http://localhost:9000": 1}))
+	})
+
+	It("never hedges write requests", func(ctx SpecContext) {
+		httpmock.RegisterResponder(
+			"POST", "http://localhost:9000/default/attributes/somebucket",
+			delayedResponder(50*time.Millisecond, 200, "", nil),
+		)
+		Expect(hedgingClient.PutBucketAttributes(ctx, "somebucket", []byte(`{}`))).To(Succeed())
+		Expect(httpmock.GetTotalCallCount()).To(Equal(1))
+		Expect(hedgingClient.HedgingStats().Requests).To(Equal(int64(0)))
+	})
+
+	It("derives the hedging delay from a latency percentile", func(ctx SpecContext) {
+		hedgingClient = bucketclient.New("http://localhost:9000",
+			bucketclient.BucketClientHedgingOption(
+				[]string{"http://localhost:9001"},
+				bucketclient.HedgingDelayOption(time.Hour),
+				bucketclient.HedgingPercentileOption(90)))
+		httpmock.RegisterResponder(
+			"GET", "http://localhost:9000/default/attributes/fastbucket",
+			httpmock.NewStringResponder(200, `{}`),
+		)
+		for i := 0; i < 20; i++ {
+			_, err := hedgingClient.GetBucketAttributes(ctx, "fastbucket")
+			Expect(err).ToNot(HaveOccurred())
+		}
+		httpmock.RegisterResponder(
+			"GET", "http://localhost:9000/default/attributes/somebucket",
+			delayedResponder(time.Second, 200, `{"primary":true}`, nil),
+		)
+		httpmock.RegisterResponder(
+			"GET", "http://localhost:9001/default/attributes/somebucket",
+			httpmock.NewStringResponder(200, `{"secondary":true}`),
+		)
+		// hedged after a tiny delay instead of one hour
+		Expect(hedgingClient.GetBucketAttributes(ctx, "somebucket")).To(
+			Equal([]byte(`{"secondary":true}`)))
+		Expect(hedgingClient.HedgingStats().Hedged).To(Equal(int64(1)))
+	})
+})
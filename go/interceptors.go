@@ -0,0 +1,97 @@
+package bucketclient
+
+import (
+	"context"
+	"net/http"
+)
+
+// RequestInfo describes a request made through BucketClient.Request,
+// as seen by interceptors. Interceptors may modify it before invoking
+// the rest of the chain.
+type RequestInfo struct {
+	// ApiMethod is the name of the API method making the request,
+	// e.g. "GetBucketAttributes"
+	ApiMethod  string
+	HttpMethod string
+	// Resource is the path and query of the request
+	Resource string
+	// Body is the request body, or nil if there is none
+	Body        []byte
+	ContentType string
+	// Idempotent is set when the request may safely be retried
+	Idempotent bool
+	// Header contains additional headers sent with the request
+	Header http.Header
+	// Coalesced is set by the client, once the invoker returns, when
+	// the request shared the in-flight request of a concurrent
+	// identical call instead of being sent (see BucketClientCoalesceReads)
+	Coalesced bool
+}
+
+// RequestInvoker sends a request described by info, through the rest
+// of the interceptor chain, and returns the response body or an error.
+type RequestInvoker func(ctx context.Context, info *RequestInfo) ([]byte, error)
+
+// RequestInterceptor intercepts requests made through
+// BucketClient.Request. It must call invoker to continue processing the
+// request, unless it wants to short-circuit it, and may observe or
+// modify the request before, and the response body or error after.
+//
+// Errors returned to the caller of Request which are not a
+// *BucketClientError are wrapped into one.
+type RequestInterceptor func(ctx context.Context, info *RequestInfo, invoker RequestInvoker) ([]byte, error)
+
+// BucketClientInterceptorOption registers interceptors called on each
+// request, in the order given: the first interceptor is the outermost
+// one, and sees the request first and the response last.
+//
+// Interceptors run before requests are shared with concurrent
+// identical requests, rate-limited or hedged, so they are called once
+// per call to Request.
+func BucketClientInterceptorOption(interceptors ...RequestInterceptor) BucketClientOption {
+	return func(client *BucketClient) {
+		client.interceptors = append(client.interceptors, interceptors...)
+	}
+}
+
+func newRequestInfo(apiMethod string, httpMethod string, resource string,
+	options requestOptionSet) *RequestInfo {
+	header := options.header.Clone()
+	if header == nil {
+		header = http.Header{}
+	}
+	return &RequestInfo{
+		ApiMethod:   apiMethod,
+		HttpMethod:  httpMethod,
+		Resource:    resource,
+		Body:        options.requestBody,
+		ContentType: options.requestBodyContentType,
+		Idempotent:  options.idempotent,
+		Header:      header,
+	}
+}
+
+func (info *RequestInfo) requestOptions() requestOptionSet {
+	return requestOptionSet{
+		requestBody:            info.Body,
+		requestBodyContentType: info.ContentType,
+		idempotent:             info.Idempotent,
+		header:                 info.Header,
+	}
+}
+
+// interceptedInvoker returns the invoker calling the interceptor at
+// the given index, or sending the request after the last one
+func (client *BucketClient) interceptedInvoker(index int) RequestInvoker {
+	if index == len(client.interceptors) {
+		return func(ctx context.Context, info *RequestInfo) ([]byte, error) {
+			responseBody, coalesced, err := client.invoke(ctx,
+				info.ApiMethod, info.HttpMethod, info.Resource, info.requestOptions())
+			info.Coalesced = coalesced
+			return responseBody, err
+		}
+	}
+	return func(ctx context.Context, info *RequestInfo) ([]byte, error) {
+		return client.interceptors[index](ctx, info, client.interceptedInvoker(index+1))
+	}
+}
@@ -0,0 +1,122 @@
+package bucketclient_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/jarcoal/httpmock"
+
+	"github.com/scality/bucketclient/go"
+)
+
+var _ = Describe("BucketClientInterceptorOption", func() {
+	It("calls interceptors in order around the request", func(ctx SpecContext) {
+		var calls []string
+		tracingInterceptor := func(name string) bucketclient.RequestInterceptor {
+			return func(ctx context.Context, info *bucketclient.RequestInfo,
+				invoker bucketclient.RequestInvoker) ([]byte, error) {
+				calls = append(calls, name+" before "+info.ApiMethod)
+				responseBody, err := invoker(ctx, info)
+				calls = append(calls, name+" after "+string(responseBody))
+				return responseBody, err
+			}
+		}
+		interceptedClient := bucketclient.New("http://localhost:9000",
+			bucketclient.BucketClientInterceptorOption(
+				tracingInterceptor("first"), tracingInterceptor("second")))
+		httpmock.RegisterResponder(
+			"GET", "/default/attributes/somebucket",
+			func(req *http.Request) (*http.Response, error) {
+				calls = append(calls, "request")
+				return httpmock.NewStringResponse(200, `{}`), nil
+			},
+		)
+		Expect(interceptedClient.GetBucketAttributes(ctx, "somebucket")).To(Equal([]byte(`{}`)))
+		Expect(calls).To(Equal([]string{
+			"first before GetBucketAttributes",
+			"second before GetBucketAttributes",
+			"request",
+			"second after {}",
+			"first after {}",
+		}))
+	})
+
+	It("exposes and lets interceptors modify the request", func(ctx SpecContext) {
+		var seen bucketclient.RequestInfo
+		interceptedClient := bucketclient.New("http://localhost:9000",
+			bucketclient.BucketClientInterceptorOption(
+				func(ctx context.Context, info *bucketclient.RequestInfo,
+					invoker bucketclient.RequestInvoker) ([]byte, error) {
+					seen = *info
+					info.Header.Set("Authorization", "Bearer token")
+					info.Body = []byte(`{"modified":true}`)
+					return invoker(ctx, info)
+				}))
+		httpmock.RegisterResponder(
+			"POST", "/default/batch/somebucket",
+			func(req *http.Request) (*http.Response, error) {
+				defer req.Body.Close()
+				Expect(io.ReadAll(req.Body)).To(Equal([]byte(`{"modified":true}`)))
+				Expect(req.Header.Get("Authorization")).To(Equal("Bearer token"))
+				Expect(req.Header.Get("Content-Type")).To(Equal("application/json"))
+				return httpmock.NewStringResponse(200, ""), nil
+			},
+		)
+		Expect(interceptedClient.PostBatch(ctx, "somebucket", []bucketclient.PostBatchEntry{
+			{Key: "foo", Type: "del"},
+		})).To(Succeed())
+		Expect(seen.ApiMethod).To(Equal("PostBatch"))
+		Expect(seen.HttpMethod).To(Equal("POST"))
+		Expect(seen.Resource).To(Equal("/default/batch/somebucket"))
+		Expect(seen.Body).To(Equal([]byte(`{"batch":[{"key":"foo","type":"del"}]}`)))
+		Expect(seen.ContentType).To(Equal("application/json"))
+		Expect(seen.Idempotent).To(BeTrue())
+	})
+
+	It("lets interceptors observe and modify errors", func(ctx SpecContext) {
+		var seenStatus int
+		interceptedClient := bucketclient.New("http://localhost:9000",
+			bucketclient.BucketClientInterceptorOption(
+				func(ctx context.Context, info *bucketclient.RequestInfo,
+					invoker bucketclient.RequestInvoker) ([]byte, error) {
+					responseBody, err := invoker(ctx, info)
+					var bcErr *bucketclient.BucketClientError
+					if errors.As(err, &bcErr) {
+						seenStatus = bcErr.StatusCode
+						if bcErr.StatusCode == http.StatusNotFound {
+							// turn missing attributes into empty ones
+							return []byte(`{}`), nil
+						}
+					}
+					return responseBody, err
+				}))
+		httpmock.RegisterResponder(
+			"GET", "/default/attributes/somebucket",
+			httpmock.NewStringResponder(404, ""),
+		)
+		Expect(interceptedClient.GetBucketAttributes(ctx, "somebucket")).To(Equal([]byte(`{}`)))
+		Expect(seenStatus).To(Equal(404))
+	})
+
+	It("lets interceptors short-circuit requests", func(ctx SpecContext) {
+		errDenied := errors.New("denied by policy")
+		interceptedClient := bucketclient.New("http://localhost:9000",
+			bucketclient.BucketClientInterceptorOption(
+				func(ctx context.Context, info *bucketclient.RequestInfo,
+					invoker bucketclient.RequestInvoker) ([]byte, error) {
+					return nil, errDenied
+				}))
+		_, err := interceptedClient.GetBucketAttributes(ctx, "somebucket")
+		Expect(errors.Is(err, errDenied)).To(BeTrue())
+		var bcErr *bucketclient.BucketClientError
+		Expect(errors.As(err, &bcErr)).To(BeTrue())
+		Expect(bcErr.ApiMethod).To(Equal("GetBucketAttributes"))
+		Expect(bcErr.Resource).To(Equal("/default/attributes/somebucket"))
+		Expect(httpmock.GetTotalCallCount()).To(Equal(0))
+	})
+})
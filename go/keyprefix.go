@@ -0,0 +1,27 @@
+package bucketclient
+
+import (
+	"unicode/utf8"
+)
+
+// prefixEnd returns the smallest key strictly higher than all keys
+// starting with prefix, or false if there is no such key. The last
+// character is incremented, so that the end is valid UTF-8 like the
+// keys bucketd decodes from requests.
+func prefixEnd(prefix string) (string, bool) {
+	for len(prefix) > 0 {
+		lastRune, size := utf8.DecodeLastRuneInString(prefix)
+		prefix = prefix[:len(prefix)-size]
+		if lastRune == utf8.MaxRune || (lastRune == utf8.RuneError && size == 1) {
+			// no higher character, or an invalid byte
+			continue
+		}
+		lastRune++
+		if lastRune >= 0xD800 && lastRune <= 0xDFFF {
+			// skip surrogates which are not valid runes
+			lastRune = 0xE000
+		}
+		return prefix + string(lastRune), true
+	}
+	return "", false
+}
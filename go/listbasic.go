@@ -80,6 +80,11 @@ type ListBasicEntry struct {
 	Value string `json:"value"`
 }
 
+// DecodeObjectMD decodes the value of the entry as object metadata.
+func (entry ListBasicEntry) DecodeObjectMD() (*ObjectMD, error) {
+	return decodeObjectMD(entry.Value)
+}
+
 type ListBasicResponse []ListBasicEntry
 
 type listBasicOptionSet struct {
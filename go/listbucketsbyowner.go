@@ -0,0 +1,116 @@
+package bucketclient
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// UsersBucket is the name of the special bucket where bucketd keeps
+// a per-owner index of buckets
+const UsersBucket = "users..bucket"
+
+// UsersBucketSplitter separates the owner canonical ID from the
+// bucket name in keys of the users bucket
+const UsersBucketSplitter = "..|.."
+
+type OwnerBucket struct {
+	Name         string
+	CreationDate time.Time
+}
+
+// usersBucketPageSize is the number of users bucket entries listed per
+// request by ListBucketsByOwner
+const usersBucketPageSize = 1000
+
+// usersBucketEntryValue is the JSON value attached to each key of the
+// users bucket
+type usersBucketEntryValue struct {
+	CreationDate string `json:"creationDate"`
+}
+
+// UsersBucketKey returns the key of the users bucket entry indexing
+// bucketName under the owner canonicalID.
+func UsersBucketKey(canonicalID string, bucketName string) string {
+	return canonicalID + UsersBucketSplitter + bucketName
+}
+
+// ListBucketsByOwner lists all buckets owned by the given canonical
+// ID, as indexed in the users bucket, in lexicographic order of bucket
+// names.
+func (client *BucketClient) ListBucketsByOwner(ctx context.Context,
+	canonicalID string) ([]OwnerBucket, error) {
+	err := validateCanonicalID(canonicalID)
+	if err != nil {
+		return nil, &BucketClientError{
+			"ListBucketsByOwner", "GET", client.Endpoint, "", 0, "", err,
+		}
+	}
+	prefix := UsersBucketKey(canonicalID, "")
+	// the prefix ends with the splitter, so it has an end
+	end, _ := prefixEnd(prefix)
+	listOpts := []ListBasicOption{
+		ListBasicGTEOption(prefix),
+		ListBasicLTOption(end),
+		ListBasicMaxKeysOption(usersBucketPageSize),
+	}
+	buckets := []OwnerBucket{}
+	for {
+		page, err := client.ListBasic(ctx, UsersBucket, listOpts...)
+		if err != nil {
+			return nil, err
+		}
+		for _, entry := range *page {
+			bucket, err := parseUsersBucketEntry(prefix, entry)
+			if err != nil {
+				return nil, ErrorMalformedResponse("ListBucketsByOwner", "GET",
+					client.Endpoint, fmt.Sprintf("/default/bucket/%s", UsersBucket), err)
+			}
+			buckets = append(buckets, bucket)
+		}
+		if len(*page) < usersBucketPageSize {
+			// bucketd fills Basic listing pages up to maxKeys unless
+			// it reaches the end of the requested range, so a short
+			// page is the last one
+			return buckets, nil
+		}
+		// fetch the next page, starting after the last returned key
+		lastKey := (*page)[len(*page)-1].Key
+		listOpts = []ListBasicOption{
+			ListBasicGTOption(lastKey),
+			ListBasicLTOption(end),
+			ListBasicMaxKeysOption(usersBucketPageSize),
+		}
+	}
+}
+
+func validateCanonicalID(canonicalID string) error {
+	if canonicalID == "" || strings.Contains(canonicalID, UsersBucketSplitter) {
+		return fmt.Errorf("invalid canonical ID: '%s'", canonicalID)
+	}
+	return nil
+}
+
+func parseUsersBucketEntry(prefix string, entry ListBasicEntry) (OwnerBucket, error) {
+	if !strings.HasPrefix(entry.Key, prefix) {
+		return OwnerBucket{}, fmt.Errorf("unexpected users bucket key '%s'", entry.Key)
+	}
+	bucket := OwnerBucket{Name: entry.Key[len(prefix):]}
+	if entry.Value == "" {
+		return bucket, nil
+	}
+	var value usersBucketEntryValue
+	err := json.Unmarshal([]byte(entry.Value), &value)
+	if err != nil {
+		return OwnerBucket{}, err
+	}
+	if value.CreationDate != "" {
+		bucket.CreationDate, err = time.Parse(time.RFC3339Nano, value.CreationDate)
+		if err != nil {
+			return OwnerBucket{}, err
+		}
+	}
+	return bucket, nil
+}
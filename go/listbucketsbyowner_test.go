@@ -0,0 +1,82 @@
+package bucketclient_test
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/jarcoal/httpmock"
+
+	"github.com/scality/bucketclient/go"
+)
+
+var _ = Describe("ListBucketsByOwner()", func() {
+	It("returns an empty list if the owner has no bucket", func(ctx SpecContext) {
+		httpmock.RegisterResponder(
+			"GET", "/default/bucket/users..bucket?gte=owner1..%7C..&listingType=Basic&lt=owner1..%7C.%2F&maxKeys=1000",
+			httpmock.NewStringResponder(200, "[]"))
+
+		Expect(client.ListBucketsByOwner(ctx, "owner1")).To(BeEmpty())
+	})
+
+	It("returns the buckets of an owner with their creation dates across pages", func(ctx SpecContext) {
+		// a full page of 1000 entries is followed by a short last page
+		firstPage := []bucketclient.ListBasicEntry{
+			{Key: "owner1..|..bucket-a", Value: `{"creationDate":"2024-03-01T10:20:30.456Z"}`},
+			{Key: "owner1..|..bucket-b", Value: `{"creationDate":"2024-03-02T00:00:00.000Z"}`},
+		}
+		expectedBuckets := []bucketclient.OwnerBucket{
+			{Name: "bucket-a", CreationDate: time.Date(2024, 3, 1, 10, 20, 30, 456000000, time.UTC)},
+			{Name: "bucket-b", CreationDate: time.Date(2024, 3, 2, 0, 0, 0, 0, time.UTC)},
+		}
+		for i := len(firstPage); i < 1000; i++ {
+			name := fmt.Sprintf("bucket-b%04d", i)
+			firstPage = append(firstPage, bucketclient.ListBasicEntry{Key: "owner1..|.." + name})
+			expectedBuckets = append(expectedBuckets, bucketclient.OwnerBucket{Name: name})
+		}
+		firstPageResponder, err := httpmock.NewJsonResponder(200, firstPage)
+		Expect(err).ToNot(HaveOccurred())
+		httpmock.RegisterResponder(
+			"GET", "/default/bucket/users..bucket?gte=owner1..%7C..&listingType=Basic&lt=owner1..%7C.%2F&maxKeys=1000",
+			firstPageResponder)
+		httpmock.RegisterResponder(
+			"GET", "/default/bucket/users..bucket?gt=owner1..%7C..bucket-b0999&listingType=Basic&lt=owner1..%7C.%2F&maxKeys=1000",
+			httpmock.NewStringResponder(200, `[
+        {"key": "owner1..|..bucket-c", "value": "{}"}
+]`))
+
+		Expect(client.ListBucketsByOwner(ctx, "owner1")).To(Equal(
+			append(expectedBuckets, bucketclient.OwnerBucket{Name: "bucket-c"})))
+		// the short page ends the listing without another request
+		Expect(httpmock.GetTotalCallCount()).To(Equal(2))
+	})
+
+	It("returns an error with an invalid canonical ID", func(ctx SpecContext) {
+		_, err := client.ListBucketsByOwner(ctx, "owner1..|..foo")
+		Expect(err).To(MatchError(ContainSubstring("invalid canonical ID")))
+	})
+
+	It("returns an error with a malformed entry value", func(ctx SpecContext) {
+		httpmock.RegisterResponder(
+			"GET", "/default/bucket/users..bucket?gte=owner1..%7C..&listingType=Basic&lt=owner1..%7C.%2F&maxKeys=1000",
+			httpmock.NewStringResponder(200, `[{"key": "owner1..|..bucket-a", "value": "OOPS"}]`))
+
+		_, err := client.ListBucketsByOwner(ctx, "owner1")
+		Expect(err).To(MatchError(ContainSubstring("malformed response body")))
+	})
+
+	It("forwards a listing error", func(ctx SpecContext) {
+		httpmock.RegisterResponder(
+			"GET", "/default/bucket/users..bucket?gte=owner1..%7C..&listingType=Basic&lt=owner1..%7C.%2F&maxKeys=1000",
+			httpmock.NewStringResponder(http.StatusInternalServerError, ""))
+
+		_, err := client.ListBucketsByOwner(ctx, "owner1")
+		Expect(err).To(HaveOccurred())
+		bcErr, ok := err.(*bucketclient.BucketClientError)
+		Expect(ok).To(BeTrue())
+		Expect(bcErr.StatusCode).To(Equal(http.StatusInternalServerError))
+	})
+})
@@ -0,0 +1,285 @@
+package bucketclient
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"iter"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+type ListLifecycleOption func(*listLifecycleOptionSet) error
+
+// ListLifecyclePrefixOption only lists keys beginning with the given prefix
+func ListLifecyclePrefixOption(prefix string) ListLifecycleOption {
+	return func(opts *listLifecycleOptionSet) error {
+		opts.prefix = &prefix
+		return nil
+	}
+}
+
+// ListLifecycleBeforeDateOption only lists entries which last
+// modification date (or stale date for non-current versions) is
+// strictly before the given date
+func ListLifecycleBeforeDateOption(beforeDate time.Time) ListLifecycleOption {
+	return func(opts *listLifecycleOptionSet) error {
+		formatted := beforeDate.UTC().Format(jsDateLayout)
+		opts.beforeDate = &formatted
+		return nil
+	}
+}
+
+// ListLifecycleExcludedDataStoreNameOption skips entries which data
+// is stored in the given location
+func ListLifecycleExcludedDataStoreNameOption(dataStoreName string) ListLifecycleOption {
+	return func(opts *listLifecycleOptionSet) error {
+		opts.excludedDataStoreName = &dataStoreName
+		return nil
+	}
+}
+
+// ListLifecycleMaxScannedEntriesOption limits the number of entries
+// scanned by bucketd for one listing call. The listing may then be
+// truncated while returning fewer entries than requested, or none.
+func ListLifecycleMaxScannedEntriesOption(maxScannedEntries int) ListLifecycleOption {
+	return func(opts *listLifecycleOptionSet) error {
+		if maxScannedEntries <= 0 {
+			return fmt.Errorf("maxScannedEntries=%d must be strictly positive", maxScannedEntries)
+		}
+		opts.maxScannedEntries = &maxScannedEntries
+		return nil
+	}
+}
+
+// ListLifecycleMaxKeysOption limits the number of returned entries
+// (default and maximum is 1000)
+func ListLifecycleMaxKeysOption(maxKeys int) ListLifecycleOption {
+	return func(opts *listLifecycleOptionSet) error {
+		if maxKeys < 0 || maxKeys > 1000 {
+			return fmt.Errorf("maxKeys=%d is out of the valid range [0, 1000]", maxKeys)
+		}
+		opts.maxKeys = &maxKeys
+		return nil
+	}
+}
+
+// ListLifecycleMarkerOption starts the listing of current versions or
+// orphan delete markers after the given key
+func ListLifecycleMarkerOption(marker string) ListLifecycleOption {
+	return func(opts *listLifecycleOptionSet) error {
+		opts.marker = &marker
+		return nil
+	}
+}
+
+// ListLifecycleVersionMarkerOption starts the listing of non-current
+// versions after the given key/versionId pair
+func ListLifecycleVersionMarkerOption(keyMarker string, versionIdMarker string) ListLifecycleOption {
+	return func(opts *listLifecycleOptionSet) error {
+		opts.keyMarker = &keyMarker
+		opts.versionIdMarker = &versionIdMarker
+		return nil
+	}
+}
+
+type ListLifecycleEntry struct {
+	Key   string `json:"key"`
+	Value string `json:"value"`
+}
+
+// DecodeObjectMD decodes the value of the entry as object metadata.
+func (entry ListLifecycleEntry) DecodeObjectMD() (*ObjectMD, error) {
+	return decodeObjectMD(entry.Value)
+}
+
+type ListLifecycleResponse struct {
+	Contents            []ListLifecycleEntry
+	IsTruncated         bool
+	NextMarker          string `json:",omitempty"`
+	NextKeyMarker       string `json:",omitempty"`
+	NextVersionIdMarker string `json:",omitempty"`
+}
+
+type listLifecycleOptionSet struct {
+	prefix                *string
+	beforeDate            *string
+	excludedDataStoreName *string
+	maxScannedEntries     *int
+	maxKeys               *int
+	marker                *string
+	keyMarker             *string
+	versionIdMarker       *string
+}
+
+func parseListLifecycleOptions(opts []ListLifecycleOption) (listLifecycleOptionSet, error) {
+	parsedOpts := listLifecycleOptionSet{}
+	for _, opt := range opts {
+		err := opt(&parsedOpts)
+		if err != nil {
+			return parsedOpts, err
+		}
+	}
+	return parsedOpts, nil
+}
+
+// ListLifecycleCurrent lists current versions of objects, for
+// lifecycle expiration or transition of current versions.
+//
+// Use ListLifecycleMarkerOption to continue a truncated listing from
+// the returned NextMarker.
+func (client *BucketClient) ListLifecycleCurrent(ctx context.Context,
+	bucketName string, opts ...ListLifecycleOption) (*ListLifecycleResponse, error) {
+	return client.listLifecycle(ctx, "ListLifecycleCurrent", "DelimiterCurrent",
+		bucketName, opts)
+}
+
+// ListLifecycleNonCurrent lists non-current versions of objects, for
+// lifecycle expiration or transition of non-current versions. Each
+// returned value contains the "versionId" and the "staleDate" of the
+// version, i.e. the date when it became non-current.
+//
+// Use ListLifecycleVersionMarkerOption to continue a truncated listing
+// from the returned NextKeyMarker and NextVersionIdMarker.
+func (client *BucketClient) ListLifecycleNonCurrent(ctx context.Context,
+	bucketName string, opts ...ListLifecycleOption) (*ListLifecycleResponse, error) {
+	return client.listLifecycle(ctx, "ListLifecycleNonCurrent", "DelimiterNonCurrent",
+		bucketName, opts)
+}
+
+// ListLifecycleOrphanDeleteMarkers lists delete markers that are the
+// only remaining version of their object, for lifecycle cleanup of
+// expired object delete markers.
+//
+// Use ListLifecycleMarkerOption to continue a truncated listing from
+// the returned NextMarker.
+func (client *BucketClient) ListLifecycleOrphanDeleteMarkers(ctx context.Context,
+	bucketName string, opts ...ListLifecycleOption) (*ListLifecycleResponse, error) {
+	return client.listLifecycle(ctx, "ListLifecycleOrphanDeleteMarkers",
+		"DelimiterOrphanDeleteMarker", bucketName, opts)
+}
+
+func (client *BucketClient) listLifecycle(ctx context.Context,
+	apiMethod string, listingType string, bucketName string,
+	opts []ListLifecycleOption) (*ListLifecycleResponse, error) {
+	resource := fmt.Sprintf("/default/bucket/%s", bucketName)
+	query := url.Values{}
+	query.Set("listingType", listingType)
+
+	options, err := parseListLifecycleOptions(opts)
+	if err == nil {
+		if listingType == "DelimiterNonCurrent" && options.marker != nil {
+			err = fmt.Errorf("%s does not support a marker, use a key/versionId marker", apiMethod)
+		} else if listingType != "DelimiterNonCurrent" && options.keyMarker != nil {
+			err = fmt.Errorf("%s does not support a key/versionId marker, use a marker", apiMethod)
+		}
+	}
+	if err != nil {
+		return nil, &BucketClientError{
+			apiMethod, "GET", client.Endpoint, resource, 0, "", err,
+		}
+	}
+	if options.prefix != nil {
+		query.Set("prefix", *options.prefix)
+	}
+	if options.beforeDate != nil {
+		query.Set("beforeDate", *options.beforeDate)
+	}
+	if options.excludedDataStoreName != nil {
+		query.Set("excludedDataStoreName", *options.excludedDataStoreName)
+	}
+	if options.maxScannedEntries != nil {
+		query.Set("maxScannedLifecycleListingEntries", strconv.Itoa(*options.maxScannedEntries))
+	}
+	if options.maxKeys != nil {
+		query.Set("maxKeys", strconv.Itoa(*options.maxKeys))
+	}
+	if options.marker != nil {
+		query.Set("marker", *options.marker)
+	}
+	if options.keyMarker != nil {
+		query.Set("keyMarker", *options.keyMarker)
+		query.Set("versionIdMarker", *options.versionIdMarker)
+	}
+	u, _ := url.Parse(resource)
+	u.RawQuery = query.Encode()
+	resource = u.String()
+	responseBody, err := client.Request(ctx, apiMethod, "GET", resource)
+	if err != nil {
+		return nil, err
+	}
+	var parsedResponse ListLifecycleResponse
+	jsonErr := json.Unmarshal(responseBody, &parsedResponse)
+	if jsonErr != nil {
+		return nil, ErrorMalformedResponse(apiMethod, "GET",
+			client.Endpoint, resource, jsonErr)
+	}
+	return &parsedResponse, nil
+}
+
+// IterateLifecycleCurrent returns an iterator over all entries
+// returned by ListLifecycleCurrent, fetching pages as needed. The
+// iteration stops after yielding the first error.
+func (client *BucketClient) IterateLifecycleCurrent(ctx context.Context,
+	bucketName string, opts ...ListLifecycleOption) iter.Seq2[ListLifecycleEntry, error] {
+	return client.iterateLifecycle(ctx, "ListLifecycleCurrent", "DelimiterCurrent",
+		bucketName, opts)
+}
+
+// IterateLifecycleNonCurrent returns an iterator over all entries
+// returned by ListLifecycleNonCurrent, fetching pages as needed. The
+// iteration stops after yielding the first error.
+func (client *BucketClient) IterateLifecycleNonCurrent(ctx context.Context,
+	bucketName string, opts ...ListLifecycleOption) iter.Seq2[ListLifecycleEntry, error] {
+	return client.iterateLifecycle(ctx, "ListLifecycleNonCurrent", "DelimiterNonCurrent",
+		bucketName, opts)
+}
+
+// IterateLifecycleOrphanDeleteMarkers returns an iterator over all
+// entries returned by ListLifecycleOrphanDeleteMarkers, fetching pages
+// as needed. The iteration stops after yielding the first error.
+func (client *BucketClient) IterateLifecycleOrphanDeleteMarkers(ctx context.Context,
+	bucketName string, opts ...ListLifecycleOption) iter.Seq2[ListLifecycleEntry, error] {
+	return client.iterateLifecycle(ctx, "ListLifecycleOrphanDeleteMarkers",
+		"DelimiterOrphanDeleteMarker", bucketName, opts)
+}
+
+func (client *BucketClient) iterateLifecycle(ctx context.Context,
+	apiMethod string, listingType string, bucketName string,
+	opts []ListLifecycleOption) iter.Seq2[ListLifecycleEntry, error] {
+	return func(yield func(ListLifecycleEntry, error) bool) {
+		pageOpts := opts
+		for {
+			response, err := client.listLifecycle(ctx, apiMethod, listingType,
+				bucketName, pageOpts)
+			if err != nil {
+				yield(ListLifecycleEntry{}, err)
+				return
+			}
+			for _, entry := range response.Contents {
+				if !yield(entry, nil) {
+					return
+				}
+			}
+			if !response.IsTruncated {
+				return
+			}
+			// the marker of the next page is appended last to
+			// override any marker passed in the original options
+			var nextMarker ListLifecycleOption
+			if response.NextKeyMarker != "" {
+				nextMarker = ListLifecycleVersionMarkerOption(
+					response.NextKeyMarker, response.NextVersionIdMarker)
+			} else if response.NextMarker != "" {
+				nextMarker = ListLifecycleMarkerOption(response.NextMarker)
+			} else {
+				yield(ListLifecycleEntry{}, ErrorMalformedResponse(apiMethod, "GET",
+					client.Endpoint, fmt.Sprintf("/default/bucket/%s", bucketName),
+					fmt.Errorf("truncated listing without a next marker")))
+				return
+			}
+			pageOpts = append(opts[:len(opts):len(opts)], nextMarker)
+		}
+	}
+}
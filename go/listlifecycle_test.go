@@ -0,0 +1,139 @@
+package bucketclient_test
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/jarcoal/httpmock"
+
+	"github.com/scality/bucketclient/go"
+)
+
+var _ = Describe("Lifecycle listings", func() {
+	Describe("ListLifecycleCurrent()", func() {
+		It("returns current versions with all filters", func(ctx SpecContext) {
+			httpmock.RegisterResponder(
+				"GET", "/default/bucket/my-bucket?beforeDate=2024-03-01T00%3A00%3A00.000Z&excludedDataStoreName=cold&listingType=DelimiterCurrent&marker=bar&maxKeys=2&maxScannedLifecycleListingEntries=100&prefix=foo",
+				httpmock.NewStringResponder(200, `{
+  "Contents": [{"key": "foo1", "value": "{}"}, {"key": "foo2", "value": "{}"}],
+  "IsTruncated": true,
+  "NextMarker": "foo2"
+}`))
+
+			Expect(client.ListLifecycleCurrent(ctx, "my-bucket",
+				bucketclient.ListLifecyclePrefixOption("foo"),
+				bucketclient.ListLifecycleBeforeDateOption(time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)),
+				bucketclient.ListLifecycleExcludedDataStoreNameOption("cold"),
+				bucketclient.ListLifecycleMaxScannedEntriesOption(100),
+				bucketclient.ListLifecycleMaxKeysOption(2),
+				bucketclient.ListLifecycleMarkerOption("bar"),
+			)).To(Equal(&bucketclient.ListLifecycleResponse{
+				Contents: []bucketclient.ListLifecycleEntry{
+					{Key: "foo1", Value: "{}"},
+					{Key: "foo2", Value: "{}"},
+				},
+				IsTruncated: true,
+				NextMarker:  "foo2",
+			}))
+		})
+
+		It("rejects a key/versionId marker", func(ctx SpecContext) {
+			_, err := client.ListLifecycleCurrent(ctx, "my-bucket",
+				bucketclient.ListLifecycleVersionMarkerOption("foo", "123"))
+			Expect(err).To(MatchError(ContainSubstring("does not support a key/versionId marker")))
+		})
+	})
+
+	Describe("ListLifecycleNonCurrent()", func() {
+		It("returns non-current versions after a key/versionId marker", func(ctx SpecContext) {
+			httpmock.RegisterResponder(
+				"GET", "/default/bucket/my-bucket?keyMarker=foo&listingType=DelimiterNonCurrent&versionIdMarker=123",
+				httpmock.NewStringResponder(200, `{
+  "Contents": [{"key": "foo", "value": "{\"versionId\":\"456\",\"staleDate\":\"2024-03-01T00:00:00.000Z\"}"}],
+  "IsTruncated": false
+}`))
+
+			Expect(client.ListLifecycleNonCurrent(ctx, "my-bucket",
+				bucketclient.ListLifecycleVersionMarkerOption("foo", "123"),
+			)).To(Equal(&bucketclient.ListLifecycleResponse{
+				Contents: []bucketclient.ListLifecycleEntry{
+					{Key: "foo", Value: `{"versionId":"456","staleDate":"2024-03-01T00:00:00.000Z"}`},
+				},
+			}))
+		})
+
+		It("rejects a single key marker", func(ctx SpecContext) {
+			_, err := client.ListLifecycleNonCurrent(ctx, "my-bucket",
+				bucketclient.ListLifecycleMarkerOption("foo"))
+			Expect(err).To(MatchError(ContainSubstring("does not support a marker")))
+		})
+	})
+
+	Describe("ListLifecycleOrphanDeleteMarkers()", func() {
+		It("returns an error with malformed response", func(ctx SpecContext) {
+			httpmock.RegisterResponder(
+				"GET", "/default/bucket/my-bucket?listingType=DelimiterOrphanDeleteMarker",
+				httpmock.NewStringResponder(200, "{OOPS"))
+
+			_, err := client.ListLifecycleOrphanDeleteMarkers(ctx, "my-bucket")
+			Expect(err).To(MatchError(ContainSubstring("malformed response body")))
+		})
+	})
+
+	Describe("IterateLifecycleOrphanDeleteMarkers()", func() {
+		It("iterates over all pages", func(ctx SpecContext) {
+			httpmock.RegisterResponder(
+				"GET", "/default/bucket/my-bucket?listingType=DelimiterOrphanDeleteMarker&maxKeys=1&prefix=foo",
+				httpmock.NewStringResponder(200, `{
+  "Contents": [{"key": "foo1", "value": "{}"}], "IsTruncated": true, "NextMarker": "foo1"
+}`))
+			httpmock.RegisterResponder(
+				"GET", "/default/bucket/my-bucket?listingType=DelimiterOrphanDeleteMarker&marker=foo1&maxKeys=1&prefix=foo",
+				httpmock.NewStringResponder(200, `{
+  "Contents": [], "IsTruncated": true, "NextMarker": "foo5"
+}`))
+			httpmock.RegisterResponder(
+				"GET", "/default/bucket/my-bucket?listingType=DelimiterOrphanDeleteMarker&marker=foo5&maxKeys=1&prefix=foo",
+				httpmock.NewStringResponder(200, `{
+  "Contents": [{"key": "foo6", "value": "{}"}], "IsTruncated": false
+}`))
+
+			keys := []string{}
+			for entry, err := range client.IterateLifecycleOrphanDeleteMarkers(ctx, "my-bucket",
+				bucketclient.ListLifecyclePrefixOption("foo"),
+				bucketclient.ListLifecycleMaxKeysOption(1)) {
+				Expect(err).ToNot(HaveOccurred())
+				keys = append(keys, entry.Key)
+			}
+			Expect(keys).To(Equal([]string{"foo1", "foo6"}))
+		})
+	})
+
+	Describe("IterateLifecycleNonCurrent()", func() {
+		It("iterates with key/versionId markers and stops at the first error", func(ctx SpecContext) {
+			httpmock.RegisterResponder(
+				"GET", "/default/bucket/my-bucket?listingType=DelimiterNonCurrent",
+				httpmock.NewStringResponder(200, `{
+  "Contents": [{"key": "foo", "value": "{}"}],
+  "IsTruncated": true, "NextKeyMarker": "foo", "NextVersionIdMarker": "123"
+}`))
+			httpmock.RegisterResponder(
+				"GET", "/default/bucket/my-bucket?keyMarker=foo&listingType=DelimiterNonCurrent&versionIdMarker=123",
+				httpmock.NewStringResponder(500, ""))
+
+			keys := []string{}
+			var lastErr error
+			for entry, err := range client.IterateLifecycleNonCurrent(ctx, "my-bucket") {
+				if err != nil {
+					lastErr = err
+					continue
+				}
+				keys = append(keys, entry.Key)
+			}
+			Expect(keys).To(Equal([]string{"foo"}))
+			Expect(lastErr).To(MatchError(ContainSubstring("500")))
+		})
+	})
+})
@@ -0,0 +1,192 @@
+package bucketclient
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strconv"
+)
+
+// MPUShadowBucketPrefix is prepended to the name of a bucket to get
+// the name of its companion bucket storing multipart uploads
+const MPUShadowBucketPrefix = "mpuShadowBucket"
+
+// MPUSplitter separates the components of keys in MPU shadow buckets
+const MPUSplitter = "..|.."
+
+// MPUShadowBucketName returns the name of the shadow bucket storing
+// multipart uploads of bucketName.
+func MPUShadowBucketName(bucketName string) string {
+	return MPUShadowBucketPrefix + bucketName
+}
+
+type ListMultipartUploadsOption func(*listMultipartUploadsOptionSet) error
+
+// ListMultipartUploadsPrefixOption only lists uploads which object
+// key begins with the given prefix
+func ListMultipartUploadsPrefixOption(prefix string) ListMultipartUploadsOption {
+	return func(opts *listMultipartUploadsOptionSet) error {
+		opts.prefix = prefix
+		return nil
+	}
+}
+
+// ListMultipartUploadsDelimiterOption groups object keys containing
+// the delimiter after the prefix into common prefixes
+func ListMultipartUploadsDelimiterOption(delimiter string) ListMultipartUploadsOption {
+	return func(opts *listMultipartUploadsOptionSet) error {
+		opts.delimiter = &delimiter
+		return nil
+	}
+}
+
+// ListMultipartUploadsKeyMarkerOption starts the listing after the
+// given object key, or after the given key/upload ID pair if
+// uploadIdMarker is not empty
+func ListMultipartUploadsKeyMarkerOption(keyMarker string, uploadIdMarker string) ListMultipartUploadsOption {
+	return func(opts *listMultipartUploadsOptionSet) error {
+		if keyMarker == "" && uploadIdMarker != "" {
+			return fmt.Errorf("uploadIdMarker requires a non-empty keyMarker")
+		}
+		opts.keyMarker = &keyMarker
+		opts.uploadIdMarker = &uploadIdMarker
+		return nil
+	}
+}
+
+// ListMultipartUploadsMaxUploadsOption limits the number of returned
+// uploads and common prefixes (default and maximum is 1000)
+func ListMultipartUploadsMaxUploadsOption(maxUploads int) ListMultipartUploadsOption {
+	return func(opts *listMultipartUploadsOptionSet) error {
+		if maxUploads < 0 || maxUploads > 1000 {
+			return fmt.Errorf("maxUploads=%d is out of the valid range [0, 1000]", maxUploads)
+		}
+		opts.maxUploads = &maxUploads
+		return nil
+	}
+}
+
+type MultipartUploadUser struct {
+	ID          string `json:"ID"`
+	DisplayName string `json:"DisplayName"`
+}
+
+type MultipartUpload struct {
+	Key          string
+	UploadId     string
+	Initiator    MultipartUploadUser
+	Owner        MultipartUploadUser
+	StorageClass string
+	Initiated    string
+}
+
+type ListMultipartUploadsResponse struct {
+	Uploads            []MultipartUpload
+	CommonPrefixes     []string
+	IsTruncated        bool
+	NextKeyMarker      string
+	NextUploadIdMarker string
+}
+
+// listMultipartUploadsBucketdResponse is the raw response of bucketd
+// for the MPU listing type
+type listMultipartUploadsBucketdResponse struct {
+	Uploads []struct {
+		Key   string `json:"key"`
+		Value struct {
+			UploadId     string              `json:"UploadId"`
+			Initiator    MultipartUploadUser `json:"Initiator"`
+			Owner        MultipartUploadUser `json:"Owner"`
+			StorageClass string              `json:"StorageClass"`
+			Initiated    string              `json:"Initiated"`
+		} `json:"value"`
+	} `json:"Uploads"`
+	CommonPrefixes     []string `json:"CommonPrefixes"`
+	IsTruncated        bool     `json:"IsTruncated"`
+	NextKeyMarker      string   `json:"NextKeyMarker"`
+	NextUploadIdMarker string   `json:"NextUploadIdMarker"`
+}
+
+type listMultipartUploadsOptionSet struct {
+	prefix         string
+	delimiter      *string
+	keyMarker      *string
+	uploadIdMarker *string
+	maxUploads     *int
+}
+
+func parseListMultipartUploadsOptions(opts []ListMultipartUploadsOption) (listMultipartUploadsOptionSet, error) {
+	parsedOpts := listMultipartUploadsOptionSet{}
+	for _, opt := range opts {
+		err := opt(&parsedOpts)
+		if err != nil {
+			return parsedOpts, err
+		}
+	}
+	return parsedOpts, nil
+}
+
+// ListMultipartUploads lists the in-progress multipart uploads of a
+// bucket, as stored in its MPU shadow bucket.
+func (client *BucketClient) ListMultipartUploads(ctx context.Context,
+	bucketName string, opts ...ListMultipartUploadsOption) (*ListMultipartUploadsResponse, error) {
+	resource := fmt.Sprintf("/default/bucket/%s", MPUShadowBucketName(bucketName))
+	query := url.Values{}
+	query.Set("listingType", "MPU")
+	query.Set("splitter", MPUSplitter)
+
+	options, err := parseListMultipartUploadsOptions(opts)
+	if err != nil {
+		return nil, &BucketClientError{
+			"ListMultipartUploads", "GET", client.Endpoint, resource, 0, "", err,
+		}
+	}
+	// overview keys of uploads are formatted as
+	// "overview<splitter><objectKey><splitter><uploadId>"
+	query.Set("prefix", fmt.Sprintf("overview%s%s", MPUSplitter, options.prefix))
+	query.Set("queryPrefixLength", strconv.Itoa(len(options.prefix)))
+	if options.delimiter != nil {
+		query.Set("delimiter", *options.delimiter)
+	}
+	if options.keyMarker != nil {
+		query.Set("keyMarker", *options.keyMarker)
+		if *options.uploadIdMarker != "" {
+			query.Set("uploadIdMarker", *options.uploadIdMarker)
+		}
+	}
+	if options.maxUploads != nil {
+		query.Set("maxKeys", strconv.Itoa(*options.maxUploads))
+	}
+	u, _ := url.Parse(resource)
+	u.RawQuery = query.Encode()
+	resource = u.String()
+	responseBody, err := client.Request(ctx, "ListMultipartUploads", "GET", resource)
+	if err != nil {
+		return nil, err
+	}
+	var bucketdResponse listMultipartUploadsBucketdResponse
+	jsonErr := json.Unmarshal(responseBody, &bucketdResponse)
+	if jsonErr != nil {
+		return nil, ErrorMalformedResponse("ListMultipartUploads", "GET",
+			client.Endpoint, resource, jsonErr)
+	}
+	parsedResponse := ListMultipartUploadsResponse{
+		Uploads:            make([]MultipartUpload, 0, len(bucketdResponse.Uploads)),
+		CommonPrefixes:     bucketdResponse.CommonPrefixes,
+		IsTruncated:        bucketdResponse.IsTruncated,
+		NextKeyMarker:      bucketdResponse.NextKeyMarker,
+		NextUploadIdMarker: bucketdResponse.NextUploadIdMarker,
+	}
+	for _, upload := range bucketdResponse.Uploads {
+		parsedResponse.Uploads = append(parsedResponse.Uploads, MultipartUpload{
+			Key:          upload.Key,
+			UploadId:     upload.Value.UploadId,
+			Initiator:    upload.Value.Initiator,
+			Owner:        upload.Value.Owner,
+			StorageClass: upload.Value.StorageClass,
+			Initiated:    upload.Value.Initiated,
+		})
+	}
+	return &parsedResponse, nil
+}
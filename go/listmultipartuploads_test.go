@@ -0,0 +1,85 @@
+package bucketclient_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/jarcoal/httpmock"
+
+	"github.com/scality/bucketclient/go"
+)
+
+var _ = Describe("ListMultipartUploads()", func() {
+	It("returns an empty listing result", func(ctx SpecContext) {
+		httpmock.RegisterResponder(
+			"GET", "/default/bucket/mpuShadowBucketmy-bucket?listingType=MPU&prefix=overview..%7C..&queryPrefixLength=0&splitter=..%7C..",
+			httpmock.NewStringResponder(200, `{"CommonPrefixes":[],"Uploads":[],"IsTruncated":false,"MaxKeys":1000}`))
+
+		Expect(client.ListMultipartUploads(ctx, "my-bucket")).To(Equal(
+			&bucketclient.ListMultipartUploadsResponse{
+				Uploads:        []bucketclient.MultipartUpload{},
+				CommonPrefixes: []string{},
+			}))
+	})
+
+	It("returns typed uploads and common prefixes with all options", func(ctx SpecContext) {
+		httpmock.RegisterResponder(
+			"GET", "/default/bucket/mpuShadowBucketmy-bucket?delimiter=%2F&keyMarker=foo%2Fbar&listingType=MPU&maxKeys=2&prefix=overview..%7C..foo%2F&queryPrefixLength=4&splitter=..%7C..&uploadIdMarker=abc",
+			httpmock.NewStringResponder(200, `{
+  "CommonPrefixes": ["foo/dir/"],
+  "Uploads": [
+    {
+      "key": "foo/qux",
+      "value": {
+        "UploadId": "def",
+        "Initiator": {"ID": "initiator-id", "DisplayName": "initiator"},
+        "Owner": {"ID": "owner-id", "DisplayName": "owner"},
+        "StorageClass": "STANDARD",
+        "Initiated": "2024-03-01T10:20:30.456Z"
+      }
+    }
+  ],
+  "IsTruncated": true,
+  "NextKeyMarker": "foo/qux",
+  "NextUploadIdMarker": "def",
+  "MaxKeys": 2,
+  "Prefix": "overview..|..foo/",
+  "Delimiter": "/"
+}`))
+
+		Expect(client.ListMultipartUploads(ctx, "my-bucket",
+			bucketclient.ListMultipartUploadsPrefixOption("foo/"),
+			bucketclient.ListMultipartUploadsDelimiterOption("/"),
+			bucketclient.ListMultipartUploadsKeyMarkerOption("foo/bar", "abc"),
+			bucketclient.ListMultipartUploadsMaxUploadsOption(2),
+		)).To(Equal(&bucketclient.ListMultipartUploadsResponse{
+			Uploads: []bucketclient.MultipartUpload{{
+				Key:          "foo/qux",
+				UploadId:     "def",
+				Initiator:    bucketclient.MultipartUploadUser{ID: "initiator-id", DisplayName: "initiator"},
+				Owner:        bucketclient.MultipartUploadUser{ID: "owner-id", DisplayName: "owner"},
+				StorageClass: "STANDARD",
+				Initiated:    "2024-03-01T10:20:30.456Z",
+			}},
+			CommonPrefixes:     []string{"foo/dir/"},
+			IsTruncated:        true,
+			NextKeyMarker:      "foo/qux",
+			NextUploadIdMarker: "def",
+		}))
+	})
+
+	It("returns an error with an upload ID marker without key marker", func(ctx SpecContext) {
+		_, err := client.ListMultipartUploads(ctx, "my-bucket",
+			bucketclient.ListMultipartUploadsKeyMarkerOption("", "abc"))
+		Expect(err).To(MatchError(ContainSubstring("uploadIdMarker requires a non-empty keyMarker")))
+	})
+
+	It("returns an error with malformed response", func(ctx SpecContext) {
+		httpmock.RegisterResponder(
+			"GET", "/default/bucket/mpuShadowBucketmy-bucket?listingType=MPU&prefix=overview..%7C..&queryPrefixLength=0&splitter=..%7C..",
+			httpmock.NewStringResponder(200, "{OOPS"))
+
+		_, err := client.ListMultipartUploads(ctx, "my-bucket")
+		Expect(err).To(MatchError(ContainSubstring("malformed response body")))
+	})
+})
@@ -50,6 +50,11 @@ type ListObjectVersionsEntry struct {
 	Value     string `json:"value"`
 }
 
+// DecodeObjectMD decodes the value of the entry as object metadata.
+func (entry ListObjectVersionsEntry) DecodeObjectMD() (*ObjectMD, error) {
+	return decodeObjectMD(entry.Value)
+}
+
 type ListObjectVersionsResponse struct {
 	Versions            []ListObjectVersionsEntry
 	CommonPrefixes      []string
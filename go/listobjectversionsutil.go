@@ -6,6 +6,9 @@ import ()
 // 0, or 1 if the pair keyMarker1/versionIdMarker1 is
 // lexicographically, respectively strictly lower, equal, or strictly
 // higher than the pair keyMarker2/versionIdMarker2.
+//
+// Since version IDs embed a reversed timestamp (see ParseVersionId),
+// for a same key, newer versions compare lower than older ones.
 func CompareVersionsListingMarkers(keyMarker1 string, versionIdMarker1 string,
 	keyMarker2 string, versionIdMarker2 string) int {
 	// if key markers are different, versionId markers are ignored
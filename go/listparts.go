@@ -0,0 +1,157 @@
+package bucketclient
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+type ListPartsOption func(*listPartsOptionSet) error
+
+// ListPartsPartNumberMarkerOption only lists parts which part number
+// is strictly greater than the given argument
+func ListPartsPartNumberMarkerOption(partNumberMarker int) ListPartsOption {
+	return func(opts *listPartsOptionSet) error {
+		if partNumberMarker < 0 || partNumberMarker > 10000 {
+			return fmt.Errorf("partNumberMarker=%d is out of the valid range [0, 10000]",
+				partNumberMarker)
+		}
+		opts.partNumberMarker = partNumberMarker
+		return nil
+	}
+}
+
+// ListPartsMaxPartsOption limits the number of returned parts
+// (default and maximum is 1000)
+func ListPartsMaxPartsOption(maxParts int) ListPartsOption {
+	return func(opts *listPartsOptionSet) error {
+		if maxParts < 0 || maxParts > 1000 {
+			return fmt.Errorf("maxParts=%d is out of the valid range [0, 1000]", maxParts)
+		}
+		opts.maxParts = maxParts
+		return nil
+	}
+}
+
+type MultipartUploadPart struct {
+	PartNumber   int
+	ETag         string
+	Size         int64
+	LastModified string
+	// Value is the raw JSON metadata of the part
+	Value string
+}
+
+type ListPartsResponse struct {
+	Parts                []MultipartUploadPart
+	IsTruncated          bool
+	NextPartNumberMarker int `json:",omitempty"`
+}
+
+type listPartsOptionSet struct {
+	partNumberMarker int
+	maxParts         int
+}
+
+// partMetadata contains the attributes of interest in the JSON
+// metadata of a part
+type partMetadata struct {
+	ContentMD5    string `json:"content-md5"`
+	ContentLength int64  `json:"content-length"`
+	LastModified  string `json:"last-modified"`
+}
+
+func parseListPartsOptions(opts []ListPartsOption) (listPartsOptionSet, error) {
+	parsedOpts := listPartsOptionSet{
+		partNumberMarker: 0,
+		maxParts:         1000,
+	}
+	for _, opt := range opts {
+		err := opt(&parsedOpts)
+		if err != nil {
+			return parsedOpts, err
+		}
+	}
+	return parsedOpts, nil
+}
+
+// MPUPartKey returns the key of a part of a multipart upload in the
+// MPU shadow bucket.
+func MPUPartKey(uploadId string, partNumber int) string {
+	return fmt.Sprintf("%s%s%05d", uploadId, MPUSplitter, partNumber)
+}
+
+// ListParts lists the parts already uploaded for a multipart upload
+// of a bucket, in increasing order of part numbers.
+func (client *BucketClient) ListParts(ctx context.Context,
+	bucketName string, uploadId string, opts ...ListPartsOption) (*ListPartsResponse, error) {
+	shadowBucketName := MPUShadowBucketName(bucketName)
+	resource := fmt.Sprintf("/default/bucket/%s", shadowBucketName)
+	options, err := parseListPartsOptions(opts)
+	if err == nil && uploadId == "" {
+		err = fmt.Errorf("empty upload ID")
+	}
+	if err != nil {
+		return nil, &BucketClientError{
+			"ListParts", "GET", client.Endpoint, resource, 0, "", err,
+		}
+	}
+	parsedResponse := ListPartsResponse{
+		Parts: []MultipartUploadPart{},
+	}
+	if options.maxParts == 0 {
+		return &parsedResponse, nil
+	}
+	// part keys are formatted as "<uploadId><splitter><partNumber>"
+	// with a 5-digit part number: list them in a range, fetching one
+	// more key than requested to know if the listing is truncated
+	keyPrefix := uploadId + MPUSplitter
+	// the prefix ends with the splitter, so it has an end
+	keyPrefixEnd, _ := prefixEnd(keyPrefix)
+	listResponse, err := client.ListBasic(ctx, shadowBucketName,
+		ListBasicGTOption(MPUPartKey(uploadId, options.partNumberMarker)),
+		ListBasicLTOption(keyPrefixEnd),
+		ListBasicMaxKeysOption(options.maxParts+1))
+	if err != nil {
+		return nil, err
+	}
+	for _, entry := range *listResponse {
+		if len(parsedResponse.Parts) == options.maxParts {
+			parsedResponse.IsTruncated = true
+			parsedResponse.NextPartNumberMarker =
+				parsedResponse.Parts[len(parsedResponse.Parts)-1].PartNumber
+			break
+		}
+		part, err := parsePartEntry(keyPrefix, entry)
+		if err != nil {
+			return nil, ErrorMalformedResponse("ListParts", "GET",
+				client.Endpoint, resource, err)
+		}
+		parsedResponse.Parts = append(parsedResponse.Parts, part)
+	}
+	return &parsedResponse, nil
+}
+
+func parsePartEntry(keyPrefix string, entry ListBasicEntry) (MultipartUploadPart, error) {
+	if !strings.HasPrefix(entry.Key, keyPrefix) {
+		return MultipartUploadPart{}, fmt.Errorf("unexpected part key '%s'", entry.Key)
+	}
+	partNumber, err := strconv.Atoi(entry.Key[len(keyPrefix):])
+	if err != nil {
+		return MultipartUploadPart{}, fmt.Errorf("unexpected part key '%s'", entry.Key)
+	}
+	var md partMetadata
+	err = json.Unmarshal([]byte(entry.Value), &md)
+	if err != nil {
+		return MultipartUploadPart{}, err
+	}
+	return MultipartUploadPart{
+		PartNumber:   partNumber,
+		ETag:         md.ContentMD5,
+		Size:         md.ContentLength,
+		LastModified: md.LastModified,
+		Value:        entry.Value,
+	}, nil
+}
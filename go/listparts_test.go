@@ -0,0 +1,87 @@
+package bucketclient_test
+
+import (
+	"encoding/json"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/jarcoal/httpmock"
+
+	"github.com/scality/bucketclient/go"
+)
+
+var _ = Describe("ListParts()", func() {
+	part1Value := `{"key":"obj","content-md5":"etag1","content-length":5242880,"last-modified":"2024-03-01T10:20:30.456Z"}`
+	part2Value := `{"key":"obj","content-md5":"etag2","content-length":1024,"last-modified":"2024-03-01T10:21:00.000Z"}`
+
+	It("returns an empty listing result", func(ctx SpecContext) {
+		httpmock.RegisterResponder(
+			"GET", "/default/bucket/mpuShadowBucketmy-bucket?gt=upload1..%7C..00000&listingType=Basic&lt=upload1..%7C.%2F&maxKeys=1001",
+			httpmock.NewStringResponder(200, "[]"))
+
+		Expect(client.ListParts(ctx, "my-bucket", "upload1")).To(Equal(
+			&bucketclient.ListPartsResponse{Parts: []bucketclient.MultipartUploadPart{}}))
+	})
+
+	It("returns all parts of an upload", func(ctx SpecContext) {
+		httpmock.RegisterResponder(
+			"GET", "/default/bucket/mpuShadowBucketmy-bucket?gt=upload1..%7C..00000&listingType=Basic&lt=upload1..%7C.%2F&maxKeys=1001",
+			httpmock.NewStringResponder(200, `[
+        {"key": "upload1..|..00001", "value": `+quoteJSON(part1Value)+`},
+        {"key": "upload1..|..00002", "value": `+quoteJSON(part2Value)+`}
+]`))
+
+		Expect(client.ListParts(ctx, "my-bucket", "upload1")).To(Equal(
+			&bucketclient.ListPartsResponse{Parts: []bucketclient.MultipartUploadPart{{
+				PartNumber:   1,
+				ETag:         "etag1",
+				Size:         5242880,
+				LastModified: "2024-03-01T10:20:30.456Z",
+				Value:        part1Value,
+			}, {
+				PartNumber:   2,
+				ETag:         "etag2",
+				Size:         1024,
+				LastModified: "2024-03-01T10:21:00.000Z",
+				Value:        part2Value,
+			}}}))
+	})
+
+	It("returns a truncated result with a part number marker and max parts", func(ctx SpecContext) {
+		httpmock.RegisterResponder(
+			"GET", "/default/bucket/mpuShadowBucketmy-bucket?gt=upload1..%7C..00003&listingType=Basic&lt=upload1..%7C.%2F&maxKeys=2",
+			httpmock.NewStringResponder(200, `[
+        {"key": "upload1..|..00004", "value": `+quoteJSON(part1Value)+`},
+        {"key": "upload1..|..00005", "value": `+quoteJSON(part2Value)+`}
+]`))
+
+		response, err := client.ListParts(ctx, "my-bucket", "upload1",
+			bucketclient.ListPartsPartNumberMarkerOption(3),
+			bucketclient.ListPartsMaxPartsOption(1))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(response.Parts).To(HaveLen(1))
+		Expect(response.Parts[0].PartNumber).To(Equal(4))
+		Expect(response.IsTruncated).To(BeTrue())
+		Expect(response.NextPartNumberMarker).To(Equal(4))
+	})
+
+	It("returns an error with a malformed part key", func(ctx SpecContext) {
+		httpmock.RegisterResponder(
+			"GET", "/default/bucket/mpuShadowBucketmy-bucket?gt=upload1..%7C..00000&listingType=Basic&lt=upload1..%7C.%2F&maxKeys=1001",
+			httpmock.NewStringResponder(200, `[{"key": "upload1..|..OOPS", "value": "{}"}]`))
+
+		_, err := client.ListParts(ctx, "my-bucket", "upload1")
+		Expect(err).To(MatchError(ContainSubstring("malformed response body")))
+	})
+
+	It("returns an error with an empty upload ID", func(ctx SpecContext) {
+		_, err := client.ListParts(ctx, "my-bucket", "")
+		Expect(err).To(MatchError(ContainSubstring("empty upload ID")))
+	})
+})
+
+func quoteJSON(s string) string {
+	quoted, _ := json.Marshal(s)
+	return string(quoted)
+}
@@ -0,0 +1,152 @@
+package bucketclient
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+)
+
+// RequestUIDsHeader is the header carrying request UIDs to bucketd,
+// which logs them to correlate its logs with those of its clients
+const RequestUIDsHeader = "x-scal-request-uids"
+
+type requestUIDsKey struct{}
+
+// ContextWithRequestUIDs returns a context carrying request UIDs, sent
+// to bucketd with each request made with the context and included in
+// the client logs.
+func ContextWithRequestUIDs(ctx context.Context, requestUIDs string) context.Context {
+	return context.WithValue(ctx, requestUIDsKey{}, requestUIDs)
+}
+
+// RequestUIDsFromContext returns the request UIDs carried by ctx, or
+// an empty string if there are none.
+func RequestUIDsFromContext(ctx context.Context) string {
+	requestUIDs, _ := ctx.Value(requestUIDsKey{}).(string)
+	return requestUIDs
+}
+
+type LoggingOption func(*loggingOptionSet)
+
+// LoggingStartLevelOption sets the level of logs of request starts
+// (default slog.LevelDebug)
+func LoggingStartLevelOption(level slog.Level) LoggingOption {
+	return func(opts *loggingOptionSet) {
+		opts.startLevel = level
+	}
+}
+
+// LoggingEndLevelOption sets the level of logs of request ends,
+// including those with a 4xx status (default slog.LevelDebug)
+func LoggingEndLevelOption(level slog.Level) LoggingOption {
+	return func(opts *loggingOptionSet) {
+		opts.endLevel = level
+	}
+}
+
+// LoggingErrorLevelOption sets the level of logs of requests ending
+// with a transport error or a 5xx status (default slog.LevelWarn)
+func LoggingErrorLevelOption(level slog.Level) LoggingOption {
+	return func(opts *loggingOptionSet) {
+		opts.errorLevel = level
+	}
+}
+
+// LoggingBodiesOption logs request and response bodies up to maxBytes
+// bytes each, instead of only their size. Bodies may contain sensitive
+// metadata, so this is meant for debugging.
+func LoggingBodiesOption(maxBytes int) LoggingOption {
+	return func(opts *loggingOptionSet) {
+		opts.bodyMaxBytes = max(maxBytes, 0)
+		opts.logBodies = true
+	}
+}
+
+type loggingOptionSet struct {
+	startLevel   slog.Level
+	endLevel     slog.Level
+	errorLevel   slog.Level
+	logBodies    bool
+	bodyMaxBytes int
+}
+
+// BucketClientLoggerOption logs the start and end of each HTTP request
+// sent to bucketd with the given logger. Each attempt of a request,
+// like hedged ones, is logged separately with its attempt number.
+func BucketClientLoggerOption(logger *slog.Logger, opts ...LoggingOption) BucketClientOption {
+	options := loggingOptionSet{
+		startLevel: slog.LevelDebug,
+		endLevel:   slog.LevelDebug,
+		errorLevel: slog.LevelWarn,
+	}
+	for _, opt := range opts {
+		opt(&options)
+	}
+	return func(client *BucketClient) {
+		if logger == nil {
+			client.logger = nil
+			return
+		}
+		client.logger = &requestLogger{logger: logger, options: options}
+	}
+}
+
+type requestLogger struct {
+	logger  *slog.Logger
+	options loggingOptionSet
+}
+
+// logStart logs the start of an attempt of a request, and returns the
+// attributes common to its start and end logs
+func (l *requestLogger) logStart(ctx context.Context, endpoint string,
+	apiMethod string, httpMethod string, resource string, options requestOptionSet) []slog.Attr {
+	attrs := []slog.Attr{
+		slog.String("apiMethod", apiMethod),
+		slog.String("httpMethod", httpMethod),
+		slog.String("endpoint", endpoint),
+		slog.String("resource", resource),
+		slog.Int("attempt", RequestAttemptFromContext(ctx)),
+	}
+	if requestUIDs := RequestUIDsFromContext(ctx); requestUIDs != "" {
+		attrs = append(attrs, slog.String("requestUIDs", requestUIDs))
+	}
+	startAttrs := attrs
+	if options.requestBody != nil {
+		startAttrs = append(startAttrs[:len(startAttrs):len(startAttrs)],
+			l.bodyAttr("requestBody", options.requestBody))
+	}
+	l.logger.LogAttrs(ctx, l.options.startLevel, "bucketd request start", startAttrs...)
+	return attrs
+}
+
+// logEnd logs the end of an attempt of a request
+func (l *requestLogger) logEnd(ctx context.Context, attrs []slog.Attr,
+	statusCode int, duration time.Duration, responseBody []byte, err error) {
+	level := l.options.endLevel
+	attrs = append(attrs,
+		slog.Int("status", statusCode),
+		slog.Duration("duration", duration))
+	if err != nil {
+		if isRetryableError(err) {
+			level = l.options.errorLevel
+		}
+		attrs = append(attrs, slog.String("error", err.Error()))
+	} else {
+		attrs = append(attrs, l.bodyAttr("responseBody", responseBody))
+	}
+	l.logger.LogAttrs(ctx, level, "bucketd request end", attrs...)
+}
+
+// bodyAttr returns the attribute logging a body, redacted unless
+// bodies are logged
+func (l *requestLogger) bodyAttr(key string, body []byte) slog.Attr {
+	if !l.options.logBodies {
+		return slog.String(key, fmt.Sprintf("<redacted %d bytes>", len(body)))
+	}
+	if len(body) > l.options.bodyMaxBytes {
+		return slog.String(key, fmt.Sprintf("%s... <truncated %d bytes>",
+			body[:l.options.bodyMaxBytes], len(body)-l.options.bodyMaxBytes))
+	}
+	return slog.String(key, string(body))
+}
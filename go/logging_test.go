@@ -0,0 +1,186 @@
+package bucketclient_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/jarcoal/httpmock"
+
+	"github.com/scality/bucketclient/go"
+)
+
+// syncBuffer is a buffer safe for concurrent writes by log handlers
+type syncBuffer struct {
+	mutex  sync.Mutex
+	buffer bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	return b.buffer.Write(p)
+}
+
+// records parses JSON log records written to the buffer
+func (b *syncBuffer) records() []map[string]any {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	records := []map[string]any{}
+	for _, line := range strings.Split(strings.TrimSpace(b.buffer.String()), "\n") {
+		if line == "" {
+			continue
+		}
+		var record map[string]any
+		Expect(json.Unmarshal([]byte(line), &record)).To(Succeed())
+		delete(record, "time")
+		delete(record, "duration")
+		records = append(records, record)
+	}
+	return records
+}
+
+var _ = Describe("BucketClientLoggerOption", func() {
+	var (
+		logs   *syncBuffer
+		logger *slog.Logger
+	)
+
+	BeforeEach(func() {
+		logs = &syncBuffer{}
+		logger = slog.New(slog.NewJSONHandler(logs, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	})
+
+	It("logs request start and end with redacted bodies", func(ctx SpecContext) {
+		loggingClient := bucketclient.New("http://localhost:9000",
+			bucketclient.BucketClientLoggerOption(logger))
+		httpmock.RegisterResponder(
+			"POST", "/default/attributes/somebucket",
+			func(req *http.Request) (*http.Response, error) {
+				Expect(req.Header.Get("x-scal-request-uids")).To(Equal("uid1:uid2"))
+				return httpmock.NewStringResponse(200, "ok"), nil
+			},
+		)
+		ctx2 := bucketclient.ContextWithRequestUIDs(ctx, "uid1:uid2")
+		Expect(loggingClient.PutBucketAttributes(ctx2, "somebucket",
+			[]byte(`{"secret":"value"}`))).To(Succeed())
+		Expect(logs.records()).To(Equal([]map[string]any{
+			{
+				"level":       "DEBUG",
+				"msg":         "bucketd request start",
+				"apiMethod":   "PutBucketAttributes",
+				"httpMethod":  "POST",
+				"endpoint":    "http://localhost:9000",
+				"resource":    "/default/attributes/somebucket",
+				"attempt":     1.0,
+				"requestUIDs": "uid1:uid2",
+				"requestBody": "<redacted 18 bytes>",
+			},
+			{
+				"level":        "DEBUG",
+				"msg":          "bucketd request end",
+				"apiMethod":    "PutBucketAttributes",
+				"httpMethod":   "POST",
+				"endpoint":     "http://localhost:9000",
+				"resource":     "/default/attributes/somebucket",
+				"attempt":      1.0,
+				"requestUIDs":  "uid1:uid2",
+				"status":       200.0,
+				"responseBody": "<redacted 2 bytes>",
+			},
+		}))
+	})
+
+	It("logs bodies when enabled, truncated to a maximum size", func(ctx SpecContext) {
+		loggingClient := bucketclient.New("http://localhost:9000",
+			bucketclient.BucketClientLoggerOption(logger,
+				bucketclient.LoggingBodiesOption(10)))
+		httpmock.RegisterResponder(
+			"GET", "/default/attributes/somebucket",
+			httpmock.NewStringResponder(200, `{"name":"somebucket"}`),
+		)
+		_, err := loggingClient.GetBucketAttributes(ctx, "somebucket")
+		Expect(err).ToNot(HaveOccurred())
+		records := logs.records()
+		Expect(records).To(HaveLen(2))
+		Expect(records[0]).ToNot(HaveKey("requestBody"))
+		Expect(records[1]["responseBody"]).To(Equal(`{"name":"s... <truncated 11 bytes>`))
+	})
+
+	It("logs errors at configurable levels", func(ctx SpecContext) {
+		loggingClient := bucketclient.New("http://localhost:9000",
+			bucketclient.BucketClientLoggerOption(logger,
+				bucketclient.LoggingStartLevelOption(slog.LevelInfo),
+				bucketclient.LoggingEndLevelOption(slog.LevelInfo),
+				bucketclient.LoggingErrorLevelOption(slog.LevelError)))
+		httpmock.RegisterResponder(
+			"GET", "/default/attributes/missing",
+			httpmock.NewStringResponder(404, ""),
+		)
+		httpmock.RegisterResponder(
+			"GET", "/default/attributes/broken",
+			httpmock.NewStringResponder(500, ""),
+		)
+		_, err := loggingClient.GetBucketAttributes(ctx, "missing")
+		Expect(err).To(HaveOccurred())
+		_, err = loggingClient.GetBucketAttributes(ctx, "broken")
+		Expect(err).To(HaveOccurred())
+		records := logs.records()
+		Expect(records).To(HaveLen(4))
+		Expect(records[0]["level"]).To(Equal("INFO"))
+		Expect(records[1]["level"]).To(Equal("INFO"))
+		Expect(records[1]["status"]).To(Equal(404.0))
+		Expect(records[1]["error"]).To(ContainSubstring("404"))
+		Expect(records[3]["level"]).To(Equal("ERROR"))
+		Expect(records[3]["status"]).To(Equal(500.0))
+	})
+
+	It("logs each attempt of hedged requests", func(ctx SpecContext) {
+		loggingClient := bucketclient.New("http://localhost:9000",
+			bucketclient.BucketClientLoggerOption(logger),
+			bucketclient.BucketClientHedgingOption([]string{"http://localhost:9001"},
+				bucketclient.HedgingDelayOption(time.Hour)))
+		httpmock.RegisterResponder(
+			"GET", "http://localhost:9000/default/attributes/somebucket",
+			httpmock.NewStringResponder(503, ""),
+		)
+		httpmock.RegisterResponder(
+			"GET", "http://localhost:9001/default/attributes/somebucket",
+			httpmock.NewStringResponder(200, `{}`),
+		)
+		_, err := loggingClient.GetBucketAttributes(ctx, "somebucket")
+		Expect(err).ToNot(HaveOccurred())
+		records := logs.records()
+		Expect(records).To(HaveLen(4))
+		Expect(records[1]).To(And(
+			HaveKeyWithValue("msg", "bucketd request end"),
+			HaveKeyWithValue("level", "WARN"),
+			HaveKeyWithValue("attempt", 1.0),
+			HaveKeyWithValue("endpoint", "http://localhost:9000")))
+		Expect(records[3]).To(And(
+			HaveKeyWithValue("msg", "bucketd request end"),
+			HaveKeyWithValue("attempt", 2.0),
+			HaveKeyWithValue("endpoint", "http://localhost:9001"),
+			HaveKeyWithValue("status", 200.0)))
+	})
+
+	It("sends request UIDs without logging enabled", func(ctx SpecContext) {
+		httpmock.RegisterResponder(
+			"GET", "/default/attributes/somebucket",
+			func(req *http.Request) (*http.Response, error) {
+				Expect(req.Header.Get("x-scal-request-uids")).To(Equal("uid3"))
+				return httpmock.NewStringResponse(200, `{}`), nil
+			},
+		)
+		_, err := client.GetBucketAttributes(
+			bucketclient.ContextWithRequestUIDs(ctx, "uid3"), "somebucket")
+		Expect(err).ToNot(HaveOccurred())
+	})
+})
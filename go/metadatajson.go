@@ -0,0 +1,107 @@
+package bucketclient
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// rawAttributes keeps the raw JSON attributes of a decoded metadata
+// blob. Encoding the blob back preserves the attributes unknown to the
+// Go model, as well as the original encoding of known attributes that
+// were left unchanged.
+type rawAttributes map[string]json.RawMessage
+
+// unmarshalMetadata decodes a JSON object into fields, which must be
+// a pointer to a struct type without custom JSON methods, and returns
+// the raw attributes of the object.
+func unmarshalMetadata(data []byte, fields any) (rawAttributes, error) {
+	var raw rawAttributes
+	err := json.Unmarshal(data, &raw)
+	if err != nil {
+		return nil, err
+	}
+	err = json.Unmarshal(data, fields)
+	if err != nil {
+		return nil, err
+	}
+	return raw, nil
+}
+
+// marshalMetadata encodes fields, which must be a pointer to a struct
+// type without custom JSON methods, merged with the raw attributes
+// returned by unmarshalMetadata when the blob was decoded.
+func marshalMetadata(fields any, raw rawAttributes) ([]byte, error) {
+	value := reflect.ValueOf(fields).Elem()
+	merged := make(map[string]json.RawMessage, len(raw)+value.NumField())
+	for name, rawValue := range raw {
+		merged[name] = rawValue
+	}
+	for i := 0; i < value.NumField(); i++ {
+		field := value.Type().Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		name, omitEmpty := parseJSONFieldTag(field)
+		if name == "-" {
+			continue
+		}
+		fieldValue := value.Field(i)
+		if rawValue, found := raw[name]; found {
+			// keep the original encoding if the value did not change
+			originalValue := reflect.New(field.Type)
+			err := json.Unmarshal(rawValue, originalValue.Interface())
+			if err == nil && reflect.DeepEqual(originalValue.Elem().Interface(), fieldValue.Interface()) {
+				continue
+			}
+			if fieldValue.IsZero() && omitEmpty {
+				// the attribute was cleared: remove it like
+				// encoding/json would
+				delete(merged, name)
+				continue
+			}
+		} else if fieldValue.IsZero() && (omitEmpty || raw != nil) {
+			// do not add attributes missing from the decoded
+			// blob unless they are set
+			continue
+		}
+		encoded, err := json.Marshal(fieldValue.Interface())
+		if err != nil {
+			return nil, fmt.Errorf("error marshaling attribute \"%s\": %w", name, err)
+		}
+		merged[name] = encoded
+	}
+	return json.Marshal(merged)
+}
+
+func parseJSONFieldTag(field reflect.StructField) (string, bool) {
+	tagParts := strings.Split(field.Tag.Get("json"), ",")
+	name := tagParts[0]
+	if name == "" {
+		name = field.Name
+	}
+	omitEmpty := false
+	for _, option := range tagParts[1:] {
+		if option == "omitempty" {
+			omitEmpty = true
+		}
+	}
+	return name, omitEmpty
+}
+
+// Flag is a boolean metadata attribute, which may also be stored as
+// an empty string or null when unset
+type Flag bool
+
+func (f *Flag) UnmarshalJSON(data []byte) error {
+	switch string(data) {
+	case "true", `"true"`:
+		*f = true
+	case "false", `"false"`, `""`, "null":
+		*f = false
+	default:
+		return fmt.Errorf("invalid boolean attribute value: %s", string(data))
+	}
+	return nil
+}
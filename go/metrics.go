@@ -0,0 +1,47 @@
+package bucketclient
+
+import (
+	"time"
+)
+
+// RequestMetrics receives measurements of the HTTP requests sent to
+// bucketd, to be exported to a metrics system. The
+// bucketclientprometheus package provides an implementation exporting
+// them as Prometheus metrics.
+//
+// Its methods are called concurrently, and for each attempt of a
+// request: a hedged request is measured once per endpoint it was sent
+// to.
+type RequestMetrics interface {
+	// RequestStarted is called before sending an HTTP request
+	RequestStarted(apiMethod string, endpoint string)
+	// RequestEnded is called once a request started ended
+	RequestEnded(measurement RequestMeasurement)
+	// RequestRetried is called before sending an HTTP request which
+	// is another attempt of a request already sent, e.g. a hedged
+	// one
+	RequestRetried(apiMethod string, endpoint string)
+}
+
+// RequestMeasurement describes an HTTP request sent to bucketd, once
+// it ended
+type RequestMeasurement struct {
+	ApiMethod string
+	Endpoint  string
+	// StatusCode is the HTTP status of the response, or zero if no
+	// response was received
+	StatusCode int
+	Duration   time.Duration
+	// ResponseBodySize is the size of the response body, in bytes
+	ResponseBodySize int
+	// Err is the error returned for the request, if any
+	Err error
+}
+
+// BucketClientMetricsOption reports measurements of all HTTP requests
+// sent to bucketd to metrics
+func BucketClientMetricsOption(metrics RequestMetrics) BucketClientOption {
+	return func(client *BucketClient) {
+		client.metrics = metrics
+	}
+}
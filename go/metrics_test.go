@@ -0,0 +1,88 @@
+package bucketclient_test
+
+import (
+	"errors"
+	"net/http"
+	"sync"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/jarcoal/httpmock"
+
+	"github.com/scality/bucketclient/go"
+)
+
+// recordingMetrics records calls to RequestMetrics methods
+type recordingMetrics struct {
+	mutex        sync.Mutex
+	started      []string
+	retried      []string
+	measurements []bucketclient.RequestMeasurement
+}
+
+func (m *recordingMetrics) RequestStarted(apiMethod string, endpoint string) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	m.started = append(m.started, apiMethod+" "+endpoint)
+}
+
+func (m *recordingMetrics) RequestEnded(measurement bucketclient.RequestMeasurement) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	m.measurements = append(m.measurements, measurement)
+}
+
+func (m *recordingMetrics) RequestRetried(apiMethod string, endpoint string) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	m.retried = append(m.retried, apiMethod+" "+endpoint)
+}
+
+var _ = Describe("BucketClientMetricsOption", func() {
+	It("measures successful requests", func(ctx SpecContext) {
+		metrics := &recordingMetrics{}
+		measuredClient := bucketclient.New("http://localhost:9000",
+			bucketclient.BucketClientMetricsOption(metrics))
+		httpmock.RegisterResponder(
+			"GET", "/default/attributes/somebucket",
+			httpmock.NewStringResponder(200, `{"name":"somebucket"}`),
+		)
+		_, err := measuredClient.GetBucketAttributes(ctx, "somebucket")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(metrics.started).To(Equal([]string{
+			"GetBucketAttributes http://localhost:9000",
+		}))
+		Expect(metrics.retried).To(BeEmpty())
+		Expect(metrics.measurements).To(HaveLen(1))
+		Expect(metrics.measurements[0].ApiMethod).To(Equal("GetBucketAttributes"))
+		Expect(metrics.measurements[0].Endpoint).To(Equal("http://localhost:9000"))
+		Expect(metrics.measurements[0].StatusCode).To(Equal(200))
+		Expect(metrics.measurements[0].ResponseBodySize).To(Equal(21))
+		Expect(metrics.measurements[0].Duration).To(BeNumerically(">", 0))
+		Expect(metrics.measurements[0].Err).ToNot(HaveOccurred())
+	})
+
+	It("measures failed requests", func(ctx SpecContext) {
+		metrics := &recordingMetrics{}
+		measuredClient := bucketclient.New("http://localhost:9000",
+			bucketclient.BucketClientMetricsOption(metrics))
+		httpmock.RegisterResponder(
+			"GET", "/default/attributes/somebucket",
+			httpmock.NewErrorResponder(errors.New("connection reset")),
+		)
+		httpmock.RegisterResponder(
+			"GET", "/default/attributes/missing",
+			httpmock.NewStringResponder(http.StatusNotFound, ""),
+		)
+		_, err := measuredClient.GetBucketAttributes(ctx, "somebucket")
+		Expect(err).To(HaveOccurred())
+		_, err = measuredClient.GetBucketAttributes(ctx, "missing")
+		Expect(err).To(HaveOccurred())
+		Expect(metrics.measurements).To(HaveLen(2))
+		Expect(metrics.measurements[0].StatusCode).To(Equal(0))
+		Expect(metrics.measurements[0].Err).To(MatchError(ContainSubstring("connection reset")))
+		Expect(metrics.measurements[1].StatusCode).To(Equal(404))
+		Expect(metrics.measurements[1].Err).To(HaveOccurred())
+	})
+})
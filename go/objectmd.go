@@ -0,0 +1,153 @@
+package bucketclient
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// ObjectMD is the metadata of an object or object version, mirroring
+// the ObjectMD model of Arsenal.
+//
+// Attributes not modeled here are preserved when decoding then
+// encoding the metadata, as are the original encodings of modeled
+// attributes left unchanged, so that read-modify-write cycles do not
+// lose information.
+type ObjectMD struct {
+	Key                  string            `json:"key"`
+	OwnerDisplayName     string            `json:"owner-display-name"`
+	OwnerID              string            `json:"owner-id"`
+	ContentLength        int64             `json:"content-length"`
+	ContentType          string            `json:"content-type"`
+	ContentMD5           string            `json:"content-md5"`
+	LastModified         string            `json:"last-modified"`
+	StorageClass         string            `json:"x-amz-storage-class"`
+	ServerSideEncryption string            `json:"x-amz-server-side-encryption"`
+	VersionId            string            `json:"versionId,omitempty"`
+	IsNull               Flag              `json:"isNull"`
+	NullVersionId        string            `json:"nullVersionId"`
+	IsDeleteMarker       Flag              `json:"isDeleteMarker"`
+	UploadId             string            `json:"uploadId,omitempty"`
+	DataStoreName        string            `json:"dataStoreName"`
+	Location             []ObjectLocation  `json:"location"`
+	ACL                  ObjectACL         `json:"acl"`
+	Tags                 map[string]string `json:"tags"`
+	ReplicationInfo      ReplicationInfo   `json:"replicationInfo"`
+
+	raw rawAttributes
+}
+
+type objectMDFields ObjectMD
+
+func (md *ObjectMD) UnmarshalJSON(data []byte) error {
+	*md = ObjectMD{}
+	raw, err := unmarshalMetadata(data, (*objectMDFields)(md))
+	md.raw = raw
+	return err
+}
+
+func (md ObjectMD) MarshalJSON() ([]byte, error) {
+	return marshalMetadata((*objectMDFields)(&md), md.raw)
+}
+
+// LastModifiedTime parses the last modification date of the object.
+func (md *ObjectMD) LastModifiedTime() (time.Time, error) {
+	return time.Parse(time.RFC3339Nano, md.LastModified)
+}
+
+// ObjectLocation is the location of a piece of object data in a data
+// backend
+type ObjectLocation struct {
+	Key                string `json:"key"`
+	Size               int64  `json:"size"`
+	Start              int64  `json:"start"`
+	DataStoreName      string `json:"dataStoreName"`
+	DataStoreType      string `json:"dataStoreType,omitempty"`
+	DataStoreETag      string `json:"dataStoreETag"`
+	DataStoreVersionId string `json:"dataStoreVersionId,omitempty"`
+
+	raw rawAttributes
+}
+
+type objectLocationFields ObjectLocation
+
+func (loc *ObjectLocation) UnmarshalJSON(data []byte) error {
+	*loc = ObjectLocation{}
+	raw, err := unmarshalMetadata(data, (*objectLocationFields)(loc))
+	loc.raw = raw
+	return err
+}
+
+func (loc ObjectLocation) MarshalJSON() ([]byte, error) {
+	return marshalMetadata((*objectLocationFields)(&loc), loc.raw)
+}
+
+// ObjectACL is the access control list of an object, where each
+// permission lists the canonical IDs of grantees
+type ObjectACL struct {
+	Canned      string   `json:"Canned"`
+	FullControl []string `json:"FULL_CONTROL"`
+	WriteACP    []string `json:"WRITE_ACP"`
+	Read        []string `json:"READ"`
+	ReadACP     []string `json:"READ_ACP"`
+}
+
+// ReplicationInfo is the replication state of an object version
+type ReplicationInfo struct {
+	Status             string               `json:"status"`
+	Backends           []ReplicationBackend `json:"backends"`
+	Content            []string             `json:"content"`
+	Destination        string               `json:"destination"`
+	StorageClass       string               `json:"storageClass"`
+	Role               string               `json:"role"`
+	StorageType        string               `json:"storageType"`
+	DataStoreVersionId string               `json:"dataStoreVersionId"`
+
+	raw rawAttributes
+}
+
+type replicationInfoFields ReplicationInfo
+
+func (info *ReplicationInfo) UnmarshalJSON(data []byte) error {
+	*info = ReplicationInfo{}
+	raw, err := unmarshalMetadata(data, (*replicationInfoFields)(info))
+	info.raw = raw
+	return err
+}
+
+func (info ReplicationInfo) MarshalJSON() ([]byte, error) {
+	return marshalMetadata((*replicationInfoFields)(&info), info.raw)
+}
+
+// ReplicationBackend is the replication state of an object version
+// to one replication site
+type ReplicationBackend struct {
+	Site               string `json:"site"`
+	Status             string `json:"status"`
+	DataStoreVersionId string `json:"dataStoreVersionId"`
+
+	raw rawAttributes
+}
+
+type replicationBackendFields ReplicationBackend
+
+func (backend *ReplicationBackend) UnmarshalJSON(data []byte) error {
+	*backend = ReplicationBackend{}
+	raw, err := unmarshalMetadata(data, (*replicationBackendFields)(backend))
+	backend.raw = raw
+	return err
+}
+
+func (backend ReplicationBackend) MarshalJSON() ([]byte, error) {
+	return marshalMetadata((*replicationBackendFields)(&backend), backend.raw)
+}
+
+// decodeObjectMD decodes the JSON value of a listing entry as object
+// metadata.
+func decodeObjectMD(value string) (*ObjectMD, error) {
+	var md ObjectMD
+	err := json.Unmarshal([]byte(value), &md)
+	if err != nil {
+		return nil, err
+	}
+	return &md, nil
+}
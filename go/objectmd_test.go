@@ -0,0 +1,144 @@
+package bucketclient_test
+
+import (
+	"encoding/json"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/scality/bucketclient/go"
+)
+
+var testObjectMD = `{
+  "owner-display-name": "owner",
+  "owner-id": "79a59df900b949e55d96a1e698fbacedfd6e09d98eacf8f8d5218e7cd47ef2be",
+  "content-length": 1024,
+  "content-type": "application/octet-stream",
+  "content-md5": "d41d8cd98f00b204e9800998ecf8427e",
+  "last-modified": "2024-03-01T10:20:30.456Z",
+  "x-amz-storage-class": "STANDARD",
+  "x-amz-server-side-encryption": "",
+  "x-amz-meta-color": "blue",
+  "key": "foo/bar",
+  "versionId": "98290711563187999999RG001  ",
+  "isNull": "",
+  "nullVersionId": "",
+  "isDeleteMarker": "",
+  "dataStoreName": "us-east-1",
+  "location": [{
+    "key": "4b8a1e0d3c",
+    "size": 1024,
+    "start": 0,
+    "dataStoreName": "us-east-1",
+    "dataStoreETag": "1:d41d8cd98f00b204e9800998ecf8427e",
+    "cryptoScheme": 1
+  }],
+  "acl": {"Canned": "private", "FULL_CONTROL": [], "WRITE_ACP": [], "READ": [], "READ_ACP": []},
+  "tags": {"project": "alpha"},
+  "replicationInfo": {
+    "status": "", "backends": [], "content": [], "destination": "",
+    "storageClass": "", "role": "", "storageType": "", "dataStoreVersionId": "",
+    "isNFS": null
+  },
+  "archive": {"archiveInfo": {"archiveId": "123"}}
+}`
+
+var _ = Describe("ObjectMD", func() {
+	It("decodes known attributes", func() {
+		var md bucketclient.ObjectMD
+		Expect(json.Unmarshal([]byte(testObjectMD), &md)).To(Succeed())
+		Expect(md.Key).To(Equal("foo/bar"))
+		Expect(md.ContentLength).To(Equal(int64(1024)))
+		Expect(bool(md.IsNull)).To(BeFalse())
+		Expect(md.Location).To(HaveLen(1))
+		Expect(md.Location[0].DataStoreETag).To(Equal("1:d41d8cd98f00b204e9800998ecf8427e"))
+		Expect(md.Tags).To(Equal(map[string]string{"project": "alpha"}))
+		Expect(md.LastModifiedTime()).To(Equal(time.Date(2024, 3, 1, 10, 20, 30, 456000000, time.UTC)))
+	})
+
+	It("encodes back unchanged metadata losslessly", func() {
+		var md bucketclient.ObjectMD
+		Expect(json.Unmarshal([]byte(testObjectMD), &md)).To(Succeed())
+		Expect(json.Marshal(md)).To(MatchJSON(testObjectMD))
+	})
+
+	It("encodes modified attributes and preserves unknown ones", func() {
+		var md bucketclient.ObjectMD
+		Expect(json.Unmarshal([]byte(testObjectMD), &md)).To(Succeed())
+		md.Tags["owner"] = "team-b"
+		md.Location[0].Size = 2048
+		md.IsDeleteMarker = true
+		md.ReplicationInfo.Status = "PENDING"
+
+		encoded, err := json.Marshal(&md)
+		Expect(err).ToNot(HaveOccurred())
+		var decoded map[string]any
+		Expect(json.Unmarshal(encoded, &decoded)).To(Succeed())
+		Expect(decoded["tags"]).To(Equal(map[string]any{"project": "alpha", "owner": "team-b"}))
+		Expect(decoded["isDeleteMarker"]).To(BeTrue())
+		Expect(decoded["isNull"]).To(Equal(""))
+		Expect(decoded["x-amz-meta-color"]).To(Equal("blue"))
+		Expect(decoded["archive"]).To(Equal(map[string]any{
+			"archiveInfo": map[string]any{"archiveId": "123"}}))
+		location := decoded["location"].([]any)[0].(map[string]any)
+		Expect(location["size"]).To(BeEquivalentTo(2048))
+		Expect(location["cryptoScheme"]).To(BeEquivalentTo(1))
+		replicationInfo := decoded["replicationInfo"].(map[string]any)
+		Expect(replicationInfo["status"]).To(Equal("PENDING"))
+		Expect(replicationInfo).To(HaveKeyWithValue("isNFS", BeNil()))
+	})
+
+	It("removes cleared attributes omitted when empty", func() {
+		var md bucketclient.ObjectMD
+		Expect(json.Unmarshal([]byte(testObjectMD), &md)).To(Succeed())
+		md.VersionId = ""
+
+		encoded, err := json.Marshal(&md)
+		Expect(err).ToNot(HaveOccurred())
+		var decoded map[string]any
+		Expect(json.Unmarshal(encoded, &decoded)).To(Succeed())
+		Expect(decoded).ToNot(HaveKey("versionId"))
+		Expect(decoded).To(HaveKeyWithValue("nullVersionId", ""))
+
+		var roundTripped bucketclient.ObjectMD
+		Expect(json.Unmarshal(encoded, &roundTripped)).To(Succeed())
+		Expect(roundTripped.VersionId).To(BeEmpty())
+		Expect(json.Marshal(&roundTripped)).To(MatchJSON(encoded))
+	})
+
+	It("encodes new metadata with all known attributes", func() {
+		md := bucketclient.ObjectMD{Key: "foo", ContentLength: 3}
+		encoded, err := json.Marshal(md)
+		Expect(err).ToNot(HaveOccurred())
+		var decoded map[string]any
+		Expect(json.Unmarshal(encoded, &decoded)).To(Succeed())
+		Expect(decoded).To(HaveKeyWithValue("key", "foo"))
+		Expect(decoded).To(HaveKeyWithValue("content-length", BeEquivalentTo(3)))
+		Expect(decoded).To(HaveKeyWithValue("isNull", false))
+		Expect(decoded).ToNot(HaveKey("versionId"))
+	})
+
+	It("returns an error with an invalid boolean attribute", func() {
+		var md bucketclient.ObjectMD
+		Expect(json.Unmarshal([]byte(`{"isNull": 42}`), &md)).To(
+			MatchError(ContainSubstring("invalid boolean attribute value")))
+	})
+
+	It("is decoded from listing entries", func() {
+		entry := bucketclient.ListBasicEntry{Key: "foo/bar", Value: testObjectMD}
+		md, err := entry.DecodeObjectMD()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(md.Key).To(Equal("foo/bar"))
+
+		versionEntry := bucketclient.ListObjectVersionsEntry{
+			Key: "foo/bar", VersionId: "98290711563187999999RG001  ", Value: testObjectMD,
+		}
+		md, err = versionEntry.DecodeObjectMD()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(md.VersionId).To(Equal(versionEntry.VersionId))
+
+		_, err = bucketclient.ListBasicEntry{Key: "foo", Value: "OOPS"}.DecodeObjectMD()
+		Expect(err).To(HaveOccurred())
+	})
+})
@@ -0,0 +1,130 @@
+package bucketclient
+
+import (
+	"context"
+	"strings"
+	"sync"
+)
+
+// BucketClientCoalesceReads makes concurrent identical GET requests
+// share a single in-flight request to bucketd and its result, instead
+// of sending one request each. Requests are identical when they have
+// the same API method, HTTP method, resource and headers, including
+// those set by interceptors, and their contexts carry the same request
+// UIDs (see ContextWithRequestUIDs).
+//
+// Other values of the contexts of callers joining an in-flight
+// request are not seen by the shared request, which keeps those of the
+// initiating caller: for example, its HTTP requests are traced on the
+// span of the initiating caller only.
+//
+// The shared request runs until it completes or until all callers
+// waiting for it gave up because their context ended: a caller
+// leaving early, including the one that initiated the request, gets a
+// context error without affecting the other callers.
+func BucketClientCoalesceReads(client *BucketClient) {
+	client.coalescer = &requestCoalescer{calls: map[coalescingKey]*coalescedCall{}}
+}
+
+type requestCoalescer struct {
+	mutex sync.Mutex
+	calls map[coalescingKey]*coalescedCall
+}
+
+// coalescingKey identifies the requests which may share a single
+// in-flight request
+type coalescingKey struct {
+	apiMethod   string
+	httpMethod  string
+	resource    string
+	requestUIDs string
+	// header is the wire format of the additional request headers
+	header string
+}
+
+func newCoalescingKey(ctx context.Context, apiMethod string, httpMethod string,
+	resource string, options requestOptionSet) coalescingKey {
+	var header strings.Builder
+	// written with keys sorted, so that equal headers give equal keys
+	_ = options.header.Write(&header)
+	return coalescingKey{
+		apiMethod:   apiMethod,
+		httpMethod:  httpMethod,
+		resource:    resource,
+		requestUIDs: RequestUIDsFromContext(ctx),
+		header:      header.String(),
+	}
+}
+
+type coalescedCall struct {
+	done    chan struct{}
+	cancel  context.CancelFunc
+	waiters int
+
+	// set before done is closed
+	responseBody []byte
+	err          error
+}
+
+// do calls send once for all concurrent callers using the same key,
+// and returns its result to each caller still waiting for it, and
+// whether the caller joined a request initiated by another one
+func (c *requestCoalescer) do(ctx context.Context, key coalescingKey,
+	send func(ctx context.Context) ([]byte, error)) ([]byte, bool, error) {
+	c.mutex.Lock()
+	call, found := c.calls[key]
+	if !found {
+		// the shared request keeps the values of the initiating
+		// context but must survive its cancellation, it is
+		// only cancelled when no caller is waiting anymore
+		callCtx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+		call = &coalescedCall{
+			done:   make(chan struct{}),
+			cancel: cancel,
+		}
+		c.calls[key] = call
+		go func() {
+			defer cancel()
+			responseBody, err := send(callCtx)
+			c.mutex.Lock()
+			if c.calls[key] == call {
+				delete(c.calls, key)
+			}
+			c.mutex.Unlock()
+			call.responseBody, call.err = responseBody, err
+			close(call.done)
+		}()
+	}
+	call.waiters += 1
+	c.mutex.Unlock()
+
+	select {
+	case <-call.done:
+		responseBody, err := copyCoalescedResult(call.responseBody, call.err)
+		return responseBody, found, err
+	case <-ctx.Done():
+		c.mutex.Lock()
+		call.waiters -= 1
+		if call.waiters == 0 {
+			call.cancel()
+			if c.calls[key] == call {
+				delete(c.calls, key)
+			}
+		}
+		c.mutex.Unlock()
+		return nil, found, ctx.Err()
+	}
+}
+
+// copyCoalescedResult copies the shared result, so that callers can
+// modify what they get without affecting each other
+func copyCoalescedResult(responseBody []byte, err error) ([]byte, error) {
+	if err != nil {
+		if bcErr, ok := err.(*BucketClientError); ok {
+			errCopy := *bcErr
+			return nil, &errCopy
+		}
+		return nil, err
+	}
+	return append([]byte(nil), responseBody...), nil
+}
@@ -0,0 +1,221 @@
+package bucketclient_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/jarcoal/httpmock"
+
+	"github.com/scality/bucketclient/go"
+)
+
+var _ = Describe("BucketClientCoalesceReads", func() {
+	var (
+		coalescingClient *bucketclient.BucketClient
+		release          chan struct{}
+		requestCanceled  chan struct{}
+	)
+
+	BeforeEach(func() {
+		coalescingClient = bucketclient.New("http://localhost:9000",
+			bucketclient.BucketClientCoalesceReads)
+		release = make(chan struct{})
+		requestCanceled = make(chan struct{}, 1)
+		httpmock.RegisterResponder(
+			"GET", "/default/attributes/somebucket",
+			func(req *http.Request) (*http.Response, error) {
+				select {
+				case <-release:
+					return httpmock.NewStringResponse(200, `{"name":"somebucket"}`), nil
+				case <-req.Context().Done():
+					requestCanceled <- struct{}{}
+					return nil, req.Context().Err()
+				}
+			},
+		)
+	})
+
+	// waitForCall waits until the blocking responder has been called
+	waitForCall := func() {
+		Eventually(httpmock.GetTotalCallCount).Should(Equal(1))
+	}
+
+	It("shares one request between concurrent identical GETs", func(ctx SpecContext) {
+		var wg sync.WaitGroup
+		results := make([][]byte, 10)
+		for i := range results {
+			wg.Add(1)
+			go func() {
+				defer GinkgoRecover()
+				defer wg.Done()
+				attributes, err := coalescingClient.GetBucketAttributes(ctx, "somebucket")
+				Expect(err).ToNot(HaveOccurred())
+				results[i] = attributes
+			}()
+		}
+		waitForCall()
+		// let all goroutines join the in-flight request
+		time.Sleep(10 * time.Millisecond)
+		close(release)
+		wg.Wait()
+		for _, attributes := range results {
+			Expect(attributes).To(Equal([]byte(`{"name":"somebucket"}`)))
+		}
+		Expect(httpmock.GetTotalCallCount()).To(Equal(1))
+		// each caller gets its own copy of the response
+		results[0][0] = 'X'
+		Expect(results[1][0]).To(Equal(byte('{')))
+	})
+
+	It("does not coalesce requests of other API methods or request UIDs", func(ctx SpecContext) {
+		var wg sync.WaitGroup
+		for _, call := range []struct {
+			apiMethod   string
+			requestUIDs string
+		}{
+			{"GetBucketAttributes", "uid1"},
+			{"GetBucketAttributes", "uid1"},
+			{"GetBucketAttributes", "uid2"},
+			{"OtherMethod", "uid1"},
+		} {
+			wg.Add(1)
+			go func() {
+				defer GinkgoRecover()
+				defer wg.Done()
+				_, err := coalescingClient.Request(
+					bucketclient.ContextWithRequestUIDs(ctx, call.requestUIDs),
+					call.apiMethod, "GET", "/default/attributes/somebucket")
+				Expect(err).ToNot(HaveOccurred())
+			}()
+		}
+		Eventually(httpmock.GetTotalCallCount).Should(Equal(3))
+		time.Sleep(10 * time.Millisecond)
+		close(release)
+		wg.Wait()
+		Expect(httpmock.GetTotalCallCount()).To(Equal(3))
+	})
+
+	It("does not coalesce requests with other headers", func(ctx SpecContext) {
+		type tenantKey struct{}
+		coalescingClient = bucketclient.New("http://localhost:9000",
+			bucketclient.BucketClientCoalesceReads,
+			bucketclient.BucketClientInterceptorOption(func(ctx context.Context,
+				info *bucketclient.RequestInfo, invoker bucketclient.RequestInvoker) ([]byte, error) {
+				info.Header.Set("X-Tenant", ctx.Value(tenantKey{}).(string))
+				return invoker(ctx, info)
+			}))
+		var wg sync.WaitGroup
+		for _, call := range []struct {
+			tenant        string
+			authorization string
+		}{
+			{"tenant1", "token1"},
+			{"tenant1", "token1"},
+			{"tenant1", "token2"},
+			{"tenant2", "token1"},
+		} {
+			wg.Add(1)
+			go func() {
+				defer GinkgoRecover()
+				defer wg.Done()
+				_, err := coalescingClient.Request(
+					context.WithValue(ctx, tenantKey{}, call.tenant),
+					"GetBucketAttributes", "GET", "/default/attributes/somebucket",
+					bucketclient.RequestHeaderOption("Authorization", call.authorization))
+				Expect(err).ToNot(HaveOccurred())
+			}()
+		}
+		Eventually(httpmock.GetTotalCallCount).Should(Equal(3))
+		time.Sleep(10 * time.Millisecond)
+		close(release)
+		wg.Wait()
+		Expect(httpmock.GetTotalCallCount()).To(Equal(3))
+	})
+
+	It("does not coalesce sequential requests", func(ctx SpecContext) {
+		close(release)
+		for i := 0; i < 2; i++ {
+			_, err := coalescingClient.GetBucketAttributes(ctx, "somebucket")
+			Expect(err).ToNot(HaveOccurred())
+		}
+		Expect(httpmock.GetTotalCallCount()).To(Equal(2))
+	})
+
+	It("does not coalesce requests other than GET", func(ctx SpecContext) {
+		httpmock.RegisterResponder(
+			"POST", "/default/attributes/somebucket",
+			func(req *http.Request) (*http.Response, error) {
+				<-release
+				return httpmock.NewStringResponse(200, ""), nil
+			},
+		)
+		var wg sync.WaitGroup
+		for i := 0; i < 2; i++ {
+			wg.Add(1)
+			go func() {
+				defer GinkgoRecover()
+				defer wg.Done()
+				Expect(coalescingClient.PutBucketAttributes(ctx, "somebucket",
+					[]byte(`{}`))).To(Succeed())
+			}()
+		}
+		Eventually(httpmock.GetTotalCallCount).Should(Equal(2))
+		close(release)
+		wg.Wait()
+	})
+
+	It("keeps the request alive when the initiating caller gives up", func(ctx SpecContext) {
+		initiatorCtx, cancelInitiator := context.WithCancel(ctx)
+		initiatorDone := make(chan error)
+		go func() {
+			_, err := coalescingClient.GetBucketAttributes(initiatorCtx, "somebucket")
+			initiatorDone <- err
+		}()
+		waitForCall()
+		otherDone := make(chan []byte)
+		go func() {
+			defer GinkgoRecover()
+			attributes, err := coalescingClient.GetBucketAttributes(ctx, "somebucket")
+			Expect(err).ToNot(HaveOccurred())
+			otherDone <- attributes
+		}()
+		time.Sleep(10 * time.Millisecond)
+
+		cancelInitiator()
+		err := <-initiatorDone
+		Expect(errors.Is(err, context.Canceled)).To(BeTrue())
+		var bcErr *bucketclient.BucketClientError
+		Expect(errors.As(err, &bcErr)).To(BeTrue())
+		Expect(bcErr.ApiMethod).To(Equal("GetBucketAttributes"))
+
+		close(release)
+		Eventually(otherDone).Should(Receive(Equal([]byte(`{"name":"somebucket"}`))))
+		Expect(httpmock.GetTotalCallCount()).To(Equal(1))
+		Expect(requestCanceled).ToNot(Receive())
+	})
+
+	It("cancels the request when all callers give up", func(ctx SpecContext) {
+		callerCtx, cancelCallers := context.WithCancel(ctx)
+		var wg sync.WaitGroup
+		for i := 0; i < 3; i++ {
+			wg.Add(1)
+			go func() {
+				defer GinkgoRecover()
+				defer wg.Done()
+				_, err := coalescingClient.GetBucketAttributes(callerCtx, "somebucket")
+				Expect(errors.Is(err, context.Canceled)).To(BeTrue())
+			}()
+		}
+		waitForCall()
+		time.Sleep(10 * time.Millisecond)
+		cancelCallers()
+		wg.Wait()
+		Eventually(requestCanceled).Should(Receive())
+	})
+})
@@ -0,0 +1,290 @@
+package bucketclient
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// BucketClientRateLimitOption limits the rate of requests of the given
+// API method, as passed to Request, to ratePerSecond on average, with
+// bursts of up to burst requests. Callers exceeding the limit wait
+// for their turn, or until their context ends.
+func BucketClientRateLimitOption(apiMethod string, ratePerSecond float64, burst int) BucketClientOption {
+	return func(client *BucketClient) {
+		client.requestLimits().limiter(apiMethod).setRate(ratePerSecond, burst)
+	}
+}
+
+// BucketClientGlobalRateLimitOption limits the rate of all requests
+// to ratePerSecond on average, with bursts of up to burst requests.
+func BucketClientGlobalRateLimitOption(ratePerSecond float64, burst int) BucketClientOption {
+	return func(client *BucketClient) {
+		client.requestLimits().global.setRate(ratePerSecond, burst)
+	}
+}
+
+// BucketClientMaxInFlightOption limits the number of requests of the
+// given API method in flight at the same time. Callers exceeding the
+// limit wait for a request to complete, or until their context ends.
+func BucketClientMaxInFlightOption(apiMethod string, maxInFlight int) BucketClientOption {
+	return func(client *BucketClient) {
+		client.requestLimits().limiter(apiMethod).setMaxInFlight(maxInFlight)
+	}
+}
+
+// BucketClientGlobalMaxInFlightOption limits the number of requests
+// in flight at the same time.
+func BucketClientGlobalMaxInFlightOption(maxInFlight int) BucketClientOption {
+	return func(client *BucketClient) {
+		client.requestLimits().global.setMaxInFlight(maxInFlight)
+	}
+}
+
+// RequestLimiterStats reports the activity of a request limiter
+type RequestLimiterStats struct {
+	// InFlight is the number of requests currently in flight
+	InFlight int64
+	// Waiting is the number of callers currently waiting
+	Waiting int64
+	// Waited is the number of requests that had to wait
+	Waited int64
+	// WaitTime is the total time spent waiting
+	WaitTime time.Duration
+	// Canceled is the number of callers that gave up waiting
+	// because their context ended
+	Canceled int64
+}
+
+// RequestLimitsStats reports the activity of the global request
+// limiter and of the limiters per API method
+type RequestLimitsStats struct {
+	Global    RequestLimiterStats
+	PerMethod map[string]RequestLimiterStats
+}
+
+// RequestLimitsStats returns statistics on requests delayed by rate
+// limits and concurrency limits.
+func (client *BucketClient) RequestLimitsStats() RequestLimitsStats {
+	stats := RequestLimitsStats{PerMethod: map[string]RequestLimiterStats{}}
+	if client.limits == nil {
+		return stats
+	}
+	stats.Global = client.limits.global.stats()
+	for apiMethod, limiter := range client.limits.perMethod {
+		stats.PerMethod[apiMethod] = limiter.stats()
+	}
+	return stats
+}
+
+// requestLimits returns the request limits of the client, creating
+// them if needed at construction
+func (client *BucketClient) requestLimits() *requestLimitSet {
+	if client.limits == nil {
+		client.limits = &requestLimitSet{
+			global:    &requestLimiter{},
+			perMethod: map[string]*requestLimiter{},
+		}
+	}
+	return client.limits
+}
+
+type requestLimitSet struct {
+	global *requestLimiter
+	// only modified at construction
+	perMethod map[string]*requestLimiter
+}
+
+func (set *requestLimitSet) limiter(apiMethod string) *requestLimiter {
+	limiter, found := set.perMethod[apiMethod]
+	if !found {
+		limiter = &requestLimiter{}
+		set.perMethod[apiMethod] = limiter
+	}
+	return limiter
+}
+
+// acquire waits until the request is allowed by all limits, and
+// returns a function to call when the request completes
+func (set *requestLimitSet) acquire(ctx context.Context, apiMethod string) (func(), error) {
+	// wait for the most specific limits first, to avoid holding
+	// global in-flight slots while waiting for a method limit
+	limiters := []*requestLimiter{}
+	if limiter, found := set.perMethod[apiMethod]; found {
+		limiters = append(limiters, limiter)
+	}
+	limiters = append(limiters, set.global)
+
+	releases := make([]func(), 0, len(limiters))
+	release := func() {
+		for _, release := range releases {
+			release()
+		}
+	}
+	for i, limiter := range limiters {
+		limiterRelease, err := limiter.acquire(ctx)
+		if err != nil {
+			release()
+			// the request is not sent, give back the tokens it took
+			for _, acquired := range limiters[:i] {
+				acquired.cancelReservation()
+			}
+			return nil, err
+		}
+		releases = append(releases, limiterRelease)
+	}
+	return release, nil
+}
+
+// requestLimiter combines a token bucket rate limit and a limit of
+// requests in flight, both optional
+type requestLimiter struct {
+	mutex sync.Mutex
+
+	// token bucket, disabled if ratePerSecond is zero
+	ratePerSecond float64
+	burst         float64
+	tokens        float64
+	lastRefill    time.Time
+
+	// in-flight slots, disabled if nil
+	slots chan struct{}
+
+	inFlight int64
+	waiting  int64
+	waited   int64
+	waitTime time.Duration
+	canceled int64
+}
+
+func (l *requestLimiter) setRate(ratePerSecond float64, burst int) {
+	l.ratePerSecond = max(ratePerSecond, 0)
+	l.burst = float64(max(burst, 1))
+	l.tokens = l.burst
+	l.lastRefill = time.Now()
+}
+
+func (l *requestLimiter) setMaxInFlight(maxInFlight int) {
+	if maxInFlight <= 0 {
+		l.slots = nil
+		return
+	}
+	l.slots = make(chan struct{}, maxInFlight)
+}
+
+func (l *requestLimiter) acquire(ctx context.Context) (func(), error) {
+	start := time.Now()
+	waited := false
+
+	tokenDelay := l.reserveToken(start)
+	if tokenDelay > 0 {
+		waited = true
+		l.startWaiting()
+		timer := time.NewTimer(tokenDelay)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			l.cancelReservation()
+			l.stopWaiting(start, true)
+			return nil, ctx.Err()
+		}
+	}
+	if l.slots != nil {
+		select {
+		case l.slots <- struct{}{}:
+		default:
+			if !waited {
+				waited = true
+				l.startWaiting()
+			}
+			select {
+			case l.slots <- struct{}{}:
+			case <-ctx.Done():
+				// the request is not sent, its token is not used
+				l.cancelReservation()
+				l.stopWaiting(start, true)
+				return nil, ctx.Err()
+			}
+		}
+	}
+	if waited {
+		l.stopWaiting(start, false)
+	}
+
+	l.mutex.Lock()
+	l.inFlight += 1
+	l.mutex.Unlock()
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			l.mutex.Lock()
+			l.inFlight -= 1
+			l.mutex.Unlock()
+			if l.slots != nil {
+				<-l.slots
+			}
+		})
+	}, nil
+}
+
+// reserveToken takes a token from the bucket and returns how long to
+// wait until it is actually available
+func (l *requestLimiter) reserveToken(now time.Time) time.Duration {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	if l.ratePerSecond == 0 {
+		return 0
+	}
+	elapsed := now.Sub(l.lastRefill).Seconds()
+	if elapsed > 0 {
+		l.tokens = min(l.burst, l.tokens+elapsed*l.ratePerSecond)
+		l.lastRefill = now
+	}
+	l.tokens -= 1
+	if l.tokens >= 0 {
+		return 0
+	}
+	return time.Duration(-l.tokens / l.ratePerSecond * float64(time.Second))
+}
+
+// cancelReservation gives back a token reserved by a caller which
+// gave up waiting for it or for an in-flight slot
+func (l *requestLimiter) cancelReservation() {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	if l.ratePerSecond == 0 {
+		return
+	}
+	l.tokens = min(l.burst, l.tokens+1)
+}
+
+func (l *requestLimiter) startWaiting() {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	l.waiting += 1
+}
+
+func (l *requestLimiter) stopWaiting(start time.Time, canceled bool) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	l.waiting -= 1
+	l.waitTime += time.Since(start)
+	if canceled {
+		l.canceled += 1
+	} else {
+		l.waited += 1
+	}
+}
+
+func (l *requestLimiter) stats() RequestLimiterStats {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	return RequestLimiterStats{
+		InFlight: l.inFlight,
+		Waiting:  l.waiting,
+		Waited:   l.waited,
+		WaitTime: l.waitTime,
+		Canceled: l.canceled,
+	}
+}
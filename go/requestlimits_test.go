@@ -0,0 +1,224 @@
+package bucketclient_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/jarcoal/httpmock"
+
+	"github.com/scality/bucketclient/go"
+)
+
+var _ = Describe("Request limits", func() {
+	BeforeEach(func() {
+		httpmock.RegisterResponder(
+			"GET", "/default/attributes/somebucket",
+			httpmock.NewStringResponder(200, `{}`),
+		)
+		httpmock.RegisterResponder(
+			"GET", "/_/buckets/somebucket/id",
+			httpmock.NewStringResponder(200, `1`),
+		)
+	})
+
+	It("delays requests exceeding the rate limit of their API method", func(ctx SpecContext) {
+		limitedClient := bucketclient.New("http://localhost:9000",
+			bucketclient.BucketClientRateLimitOption("GetBucketAttributes", 20, 2))
+		start := time.Now()
+		for i := 0; i < 4; i++ {
+			_, err := limitedClient.GetBucketAttributes(ctx, "somebucket")
+			Expect(err).ToNot(HaveOccurred())
+		}
+		// 2 requests in the initial burst, then 2 more at 20/s
+		Expect(time.Since(start)).To(BeNumerically(">=", 90*time.Millisecond))
+
+		// other API methods are not limited
+		start = time.Now()
+		for i := 0; i < 4; i++ {
+			_, err := limitedClient.AdminGetBucketSessionID(ctx, "somebucket")
+			Expect(err).ToNot(HaveOccurred())
+		}
+		Expect(time.Since(start)).To(BeNumerically("<", 50*time.Millisecond))
+
+		stats := limitedClient.RequestLimitsStats()
+		Expect(stats.PerMethod).To(HaveKey("GetBucketAttributes"))
+		Expect(stats.PerMethod["GetBucketAttributes"].Waited).To(Equal(int64(2)))
+		Expect(stats.PerMethod["GetBucketAttributes"].WaitTime).To(
+			BeNumerically(">=", 90*time.Millisecond))
+		Expect(stats.Global.Waited).To(Equal(int64(0)))
+	})
+
+	It("applies the global rate limit to all API methods", func(ctx SpecContext) {
+		limitedClient := bucketclient.New("http://localhost:9000",
+			bucketclient.BucketClientGlobalRateLimitOption(20, 1))
+		start := time.Now()
+		_, err := limitedClient.GetBucketAttributes(ctx, "somebucket")
+		Expect(err).ToNot(HaveOccurred())
+		_, err = limitedClient.AdminGetBucketSessionID(ctx, "somebucket")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(time.Since(start)).To(BeNumerically(">=", 45*time.Millisecond))
+		Expect(limitedClient.RequestLimitsStats().Global.Waited).To(Equal(int64(1)))
+	})
+
+	It("honours context cancellation while waiting", func(ctx SpecContext) {
+		limitedClient := bucketclient.New("http://localhost:9000",
+			bucketclient.BucketClientRateLimitOption("GetBucketAttributes", 0.1, 1))
+		_, err := limitedClient.GetBucketAttributes(ctx, "somebucket")
+		Expect(err).ToNot(HaveOccurred())
+
+		timeoutCtx, cancel := context.WithTimeout(ctx, 20*time.Millisecond)
+		defer cancel()
+		_, err = limitedClient.GetBucketAttributes(timeoutCtx, "somebucket")
+		Expect(errors.Is(err, context.DeadlineExceeded)).To(BeTrue())
+		var bcErr *bucketclient.BucketClientError
+		Expect(errors.As(err, &bcErr)).To(BeTrue())
+		Expect(bcErr.ApiMethod).To(Equal("GetBucketAttributes"))
+		Expect(httpmock.GetTotalCallCount()).To(Equal(1))
+		Expect(limitedClient.RequestLimitsStats().PerMethod["GetBucketAttributes"].Canceled).To(
+			Equal(int64(1)))
+	})
+
+	It("limits the number of requests in flight", func(ctx SpecContext) {
+		limitedClient := bucketclient.New("http://localhost:9000",
+			bucketclient.BucketClientMaxInFlightOption("GetBucketAttributes", 2))
+		var (
+			mutex       sync.Mutex
+			inFlight    int
+			maxInFlight int
+		)
+		release := make(chan struct{})
+		httpmock.RegisterResponder(
+			"GET", "/default/attributes/somebucket",
+			func(req *http.Request) (*http.Response, error) {
+				mutex.Lock()
+				inFlight += 1
+				maxInFlight = max(maxInFlight, inFlight)
+				mutex.Unlock()
+				<-release
+				mutex.Lock()
+				inFlight -= 1
+				mutex.Unlock()
+				return httpmock.NewStringResponse(200, `{}`), nil
+			},
+		)
+		var wg sync.WaitGroup
+		for i := 0; i < 5; i++ {
+			wg.Add(1)
+			go func() {
+				defer GinkgoRecover()
+				defer wg.Done()
+				_, err := limitedClient.GetBucketAttributes(ctx, "somebucket")
+				Expect(err).ToNot(HaveOccurred())
+			}()
+		}
+		Eventually(func() bucketclient.RequestLimiterStats {
+			return limitedClient.RequestLimitsStats().PerMethod["GetBucketAttributes"]
+		}).Should(And(
+			HaveField("InFlight", int64(2)),
+			HaveField("Waiting", int64(3))))
+		close(release)
+		wg.Wait()
+		Expect(maxInFlight).To(Equal(2))
+		stats := limitedClient.RequestLimitsStats().PerMethod["GetBucketAttributes"]
+		Expect(stats.InFlight).To(Equal(int64(0)))
+		Expect(stats.Waiting).To(Equal(int64(0)))
+		Expect(stats.Waited).To(Equal(int64(3)))
+	})
+
+	It("releases in-flight slots of failed requests", func(ctx SpecContext) {
+		limitedClient := bucketclient.New("http://localhost:9000",
+			bucketclient.BucketClientGlobalMaxInFlightOption(1))
+		httpmock.RegisterResponder(
+			"GET", "/default/attributes/somebucket",
+			httpmock.NewStringResponder(500, ``),
+		)
+		for i := 0; i < 3; i++ {
+			_, err := limitedClient.GetBucketAttributes(ctx, "somebucket")
+			var bcErr *bucketclient.BucketClientError
+			Expect(errors.As(err, &bcErr)).To(BeTrue())
+			Expect(bcErr.StatusCode).To(Equal(500))
+		}
+		Expect(limitedClient.RequestLimitsStats().Global.InFlight).To(Equal(int64(0)))
+	})
+
+	It("gives back the rate token of requests canceled while waiting for a slot", func(ctx SpecContext) {
+		limitedClient := bucketclient.New("http://localhost:9000",
+			bucketclient.BucketClientRateLimitOption("GetBucketAttributes", 0.1, 2),
+			bucketclient.BucketClientMaxInFlightOption("GetBucketAttributes", 1))
+		release := make(chan struct{})
+		httpmock.RegisterResponder(
+			"GET", "/default/attributes/somebucket",
+			func(req *http.Request) (*http.Response, error) {
+				<-release
+				return httpmock.NewStringResponse(200, `{}`), nil
+			},
+		)
+		done := make(chan struct{})
+		go func() {
+			defer GinkgoRecover()
+			defer close(done)
+			_, err := limitedClient.GetBucketAttributes(ctx, "somebucket")
+			Expect(err).ToNot(HaveOccurred())
+		}()
+		Eventually(func() int64 {
+			return limitedClient.RequestLimitsStats().PerMethod["GetBucketAttributes"].InFlight
+		}).Should(Equal(int64(1)))
+
+		timeoutCtx, cancel := context.WithTimeout(ctx, 20*time.Millisecond)
+		defer cancel()
+		_, err := limitedClient.GetBucketAttributes(timeoutCtx, "somebucket")
+		Expect(errors.Is(err, context.DeadlineExceeded)).To(BeTrue())
+		close(release)
+		<-done
+
+		// the second token of the burst is still available
+		start := time.Now()
+		_, err = limitedClient.GetBucketAttributes(ctx, "somebucket")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(time.Since(start)).To(BeNumerically("<", time.Second))
+		stats := limitedClient.RequestLimitsStats().PerMethod["GetBucketAttributes"]
+		Expect(stats.Canceled).To(Equal(int64(1)))
+	})
+
+	It("gives back the rate token of a method when the global slot wait is canceled", func(ctx SpecContext) {
+		limitedClient := bucketclient.New("http://localhost:9000",
+			bucketclient.BucketClientRateLimitOption("GetBucketAttributes", 0.1, 1),
+			bucketclient.BucketClientGlobalMaxInFlightOption(1))
+		release := make(chan struct{})
+		httpmock.RegisterResponder(
+			"GET", "/_/buckets/somebucket/id",
+			func(req *http.Request) (*http.Response, error) {
+				<-release
+				return httpmock.NewStringResponse(200, `1`), nil
+			},
+		)
+		done := make(chan struct{})
+		go func() {
+			defer GinkgoRecover()
+			defer close(done)
+			_, err := limitedClient.AdminGetBucketSessionID(ctx, "somebucket")
+			Expect(err).ToNot(HaveOccurred())
+		}()
+		Eventually(func() int64 {
+			return limitedClient.RequestLimitsStats().Global.InFlight
+		}).Should(Equal(int64(1)))
+
+		timeoutCtx, cancel := context.WithTimeout(ctx, 20*time.Millisecond)
+		defer cancel()
+		_, err := limitedClient.GetBucketAttributes(timeoutCtx, "somebucket")
+		Expect(errors.Is(err, context.DeadlineExceeded)).To(BeTrue())
+		close(release)
+		<-done
+
+		start := time.Now()
+		_, err = limitedClient.GetBucketAttributes(ctx, "somebucket")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(time.Since(start)).To(BeNumerically("<", time.Second))
+	})
+})
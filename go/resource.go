@@ -0,0 +1,31 @@
+package bucketclient
+
+import (
+	"net/url"
+	"strings"
+)
+
+// BucketNameFromResource returns the name of the bucket targeted by a
+// bucketd resource, like "/default/bucket/{bucketName}?listingType=Basic",
+// or an empty string if the resource does not target a bucket.
+func BucketNameFromResource(resource string) string {
+	path, _, _ := strings.Cut(resource, "?")
+	var escapedName string
+	for _, prefix := range []string{
+		"/default/bucket/",
+		"/default/attributes/",
+		"/default/batch/",
+		"/default/metastore/db/",
+		"/_/buckets/",
+	} {
+		if rest, found := strings.CutPrefix(path, prefix); found {
+			escapedName, _, _ = strings.Cut(rest, "/")
+			break
+		}
+	}
+	bucketName, err := url.PathUnescape(escapedName)
+	if err != nil {
+		return escapedName
+	}
+	return bucketName
+}
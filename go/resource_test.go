@@ -0,0 +1,28 @@
+package bucketclient_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/scality/bucketclient/go"
+)
+
+var _ = Describe("BucketNameFromResource", func() {
+	It("returns the bucket targeted by a resource", func() {
+		Expect(bucketclient.BucketNameFromResource(
+			"/default/bucket/somebucket?listingType=Basic")).To(Equal("somebucket"))
+		Expect(bucketclient.BucketNameFromResource(
+			"/default/bucket/somebucket/some%2Fkey")).To(Equal("somebucket"))
+		Expect(bucketclient.BucketNameFromResource(
+			"/default/attributes/some%20bucket")).To(Equal("some bucket"))
+		Expect(bucketclient.BucketNameFromResource(
+			"/default/metastore/db/somebucket")).To(Equal("somebucket"))
+		Expect(bucketclient.BucketNameFromResource(
+			"/_/buckets/somebucket/accessMode?mode=read-only")).To(Equal("somebucket"))
+	})
+
+	It("returns an empty string for resources not targeting a bucket", func() {
+		Expect(bucketclient.BucketNameFromResource("/_/raft_sessions/1/leader")).To(Equal(""))
+		Expect(bucketclient.BucketNameFromResource("/default/informations")).To(Equal(""))
+	})
+})
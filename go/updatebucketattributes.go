@@ -0,0 +1,168 @@
+package bucketclient
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"net/http"
+	"time"
+)
+
+// ErrConcurrentUpdate is wrapped in the error returned when an update
+// kept conflicting with concurrent updates of the same bucket
+var ErrConcurrentUpdate = errors.New("concurrent update detected")
+
+type UpdateBucketAttributesOption func(*updateBucketAttributesOptionSet) error
+
+// UpdateBucketAttributesMaxAttemptsOption sets the maximum number of
+// read-modify-write attempts before giving up on conflicts (default 5)
+func UpdateBucketAttributesMaxAttemptsOption(maxAttempts int) UpdateBucketAttributesOption {
+	return func(opts *updateBucketAttributesOptionSet) error {
+		if maxAttempts < 1 {
+			return fmt.Errorf("maxAttempts=%d must be at least 1", maxAttempts)
+		}
+		opts.maxAttempts = maxAttempts
+		return nil
+	}
+}
+
+// UpdateBucketAttributesRetryDelayOption sets the delay before the
+// first retry after a conflict, doubled at each subsequent retry and
+// randomized by up to 50% (default 50ms)
+func UpdateBucketAttributesRetryDelayOption(retryDelay time.Duration) UpdateBucketAttributesOption {
+	return func(opts *updateBucketAttributesOptionSet) error {
+		if retryDelay < 0 {
+			return fmt.Errorf("retryDelay=%s must not be negative", retryDelay)
+		}
+		opts.retryDelay = retryDelay
+		return nil
+	}
+}
+
+type updateBucketAttributesOptionSet struct {
+	maxAttempts int
+	retryDelay  time.Duration
+}
+
+func parseUpdateBucketAttributesOptions(opts []UpdateBucketAttributesOption) (updateBucketAttributesOptionSet, error) {
+	parsedOpts := updateBucketAttributesOptionSet{
+		maxAttempts: 5,
+		retryDelay:  50 * time.Millisecond,
+	}
+	for _, opt := range opts {
+		err := opt(&parsedOpts)
+		if err != nil {
+			return parsedOpts, err
+		}
+	}
+	return parsedOpts, nil
+}
+
+// UpdateBucketAttributes applies a read-modify-write update to the
+// attributes of a bucket.
+//
+// The current attributes are fetched and decoded, then passed to the
+// mutate function which modifies them in place. If mutate returns an
+// error, the update is aborted and the error is returned as is.
+//
+// Since bucketd does not version bucket attributes, concurrent updates
+// are detected by reading the attributes again just before writing
+// them back: if they changed in-between, the update is retried from
+// the start with the new attributes, up to a maximum number of
+// attempts. This narrows the window where a concurrent update can be
+// lost, but does not close it entirely.
+//
+// When all attempts conflicted, the returned error has status 409
+// Conflict and wraps ErrConcurrentUpdate.
+func (client *BucketClient) UpdateBucketAttributes(ctx context.Context, bucketName string,
+	mutate func(bucketInfo *BucketInfo) error, opts ...UpdateBucketAttributesOption) error {
+	resource := fmt.Sprintf("/default/attributes/%s", bucketName)
+	options, err := parseUpdateBucketAttributesOptions(opts)
+	if err != nil {
+		return &BucketClientError{
+			"UpdateBucketAttributes", "POST", client.Endpoint, resource, 0, "", err,
+		}
+	}
+	retryDelay := options.retryDelay
+	for attempt := 1; attempt <= options.maxAttempts; attempt++ {
+		if attempt > 1 {
+			jitter := time.Duration(rand.Int64N(int64(retryDelay)/2 + 1))
+			select {
+			case <-ctx.Done():
+				return &BucketClientError{
+					"UpdateBucketAttributes", "POST", client.Endpoint, resource, 0, "",
+					ctx.Err(),
+				}
+			case <-time.After(retryDelay + jitter):
+			}
+			retryDelay *= 2
+		}
+		updated, err := client.tryUpdateBucketAttributes(ctx, bucketName, mutate)
+		if updated || err != nil {
+			return err
+		}
+	}
+	return &BucketClientError{
+		"UpdateBucketAttributes", "POST", client.Endpoint, resource,
+		http.StatusConflict, "Conflict",
+		fmt.Errorf("%w on bucket attributes after %d attempts",
+			ErrConcurrentUpdate, options.maxAttempts),
+	}
+}
+
+// tryUpdateBucketAttributes makes one read-modify-write attempt,
+// returning false without error if a concurrent update was detected.
+func (client *BucketClient) tryUpdateBucketAttributes(ctx context.Context, bucketName string,
+	mutate func(bucketInfo *BucketInfo) error) (bool, error) {
+	attributes, err := client.GetBucketAttributes(ctx, bucketName)
+	if err != nil {
+		return false, err
+	}
+	var bucketInfo BucketInfo
+	jsonErr := json.Unmarshal(attributes, &bucketInfo)
+	if jsonErr != nil {
+		return false, ErrorMalformedResponse("UpdateBucketAttributes", "GET", client.Endpoint,
+			fmt.Sprintf("/default/attributes/%s", bucketName), jsonErr)
+	}
+	// encode the attributes before mutation as well, to compare
+	// both encodings regardless of attribute order
+	originalAttributes, err := json.Marshal(&bucketInfo)
+	if err != nil {
+		return false, errorMarshalingBucketAttributes(client, bucketName, err)
+	}
+	err = mutate(&bucketInfo)
+	if err != nil {
+		return false, err
+	}
+	newAttributes, err := json.Marshal(&bucketInfo)
+	if err != nil {
+		return false, errorMarshalingBucketAttributes(client, bucketName, err)
+	}
+	if bytes.Equal(originalAttributes, newAttributes) {
+		// nothing to update
+		return true, nil
+	}
+	currentAttributes, err := client.GetBucketAttributes(ctx, bucketName)
+	if err != nil {
+		return false, err
+	}
+	if !bytes.Equal(attributes, currentAttributes) {
+		return false, nil
+	}
+	err = client.PutBucketAttributes(ctx, bucketName, newAttributes)
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+func errorMarshalingBucketAttributes(client *BucketClient, bucketName string, err error) error {
+	return &BucketClientError{
+		"UpdateBucketAttributes", "POST", client.Endpoint,
+		fmt.Sprintf("/default/attributes/%s", bucketName), 0, "",
+		fmt.Errorf("error marshaling bucket attributes: %w", err),
+	}
+}
@@ -0,0 +1,137 @@
+package bucketclient_test
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/jarcoal/httpmock"
+
+	"github.com/scality/bucketclient/go"
+)
+
+var _ = Describe("UpdateBucketAttributes()", func() {
+	enableVersioning := func(bucketInfo *bucketclient.BucketInfo) error {
+		bucketInfo.VersioningConfiguration = &bucketclient.VersioningConfiguration{
+			Status: "Enabled",
+		}
+		return nil
+	}
+
+	It("updates the bucket attributes and preserves unknown ones", func(ctx SpecContext) {
+		httpmock.RegisterResponder(
+			"GET", "/default/attributes/my-bucket",
+			httpmock.NewStringResponder(200, `{"name":"my-bucket","foo":"bar"}`),
+		)
+		httpmock.RegisterResponder(
+			"POST", "/default/attributes/my-bucket",
+			func(req *http.Request) (*http.Response, error) {
+				defer req.Body.Close()
+				Expect(io.ReadAll(req.Body)).To(MatchJSON(
+					`{"name":"my-bucket","foo":"bar","versioningConfiguration":{"Status":"Enabled"}}`))
+				return httpmock.NewStringResponse(200, ""), nil
+			},
+		)
+		Expect(client.UpdateBucketAttributes(ctx, "my-bucket", enableVersioning)).To(Succeed())
+		Expect(httpmock.GetCallCountInfo()).To(Equal(map[string]int{
+			"GET /default/attributes/my-bucket":  2,
+			"POST /default/attributes/my-bucket": 1,
+		}))
+	})
+
+	It("does not write the attributes when unchanged", func(ctx SpecContext) {
+		httpmock.RegisterResponder(
+			"GET", "/default/attributes/my-bucket",
+			httpmock.NewStringResponder(200, `{"name":"my-bucket"}`),
+		)
+		Expect(client.UpdateBucketAttributes(ctx, "my-bucket",
+			func(bucketInfo *bucketclient.BucketInfo) error {
+				bucketInfo.Name = "my-bucket"
+				return nil
+			})).To(Succeed())
+		Expect(httpmock.GetCallCountInfo()).To(Equal(map[string]int{
+			"GET /default/attributes/my-bucket": 1,
+		}))
+	})
+
+	It("retries after detecting a concurrent update", func(ctx SpecContext) {
+		responses := []string{
+			`{"name":"my-bucket"}`,
+			`{"name":"my-bucket","replicationConfiguration":{"role":"r"}}`,
+			`{"name":"my-bucket","replicationConfiguration":{"role":"r"}}`,
+			`{"name":"my-bucket","replicationConfiguration":{"role":"r"}}`,
+		}
+		getCount := 0
+		httpmock.RegisterResponder(
+			"GET", "/default/attributes/my-bucket",
+			func(req *http.Request) (*http.Response, error) {
+				getCount += 1
+				return httpmock.NewStringResponse(200, responses[getCount-1]), nil
+			},
+		)
+		httpmock.RegisterResponder(
+			"POST", "/default/attributes/my-bucket",
+			func(req *http.Request) (*http.Response, error) {
+				defer req.Body.Close()
+				Expect(io.ReadAll(req.Body)).To(MatchJSON(`{"name":"my-bucket",` +
+					`"replicationConfiguration":{"role":"r"},` +
+					`"versioningConfiguration":{"Status":"Enabled"}}`))
+				return httpmock.NewStringResponse(200, ""), nil
+			},
+		)
+		Expect(client.UpdateBucketAttributes(ctx, "my-bucket", enableVersioning,
+			bucketclient.UpdateBucketAttributesRetryDelayOption(time.Millisecond),
+		)).To(Succeed())
+		Expect(getCount).To(Equal(4))
+	})
+
+	It("returns a conflict error after too many concurrent updates", func(ctx SpecContext) {
+		getCount := 0
+		httpmock.RegisterResponder(
+			"GET", "/default/attributes/my-bucket",
+			func(req *http.Request) (*http.Response, error) {
+				getCount += 1
+				return httpmock.NewStringResponse(200,
+					`{"name":"my-bucket","counter":`+strconv.Itoa(getCount)+`}`), nil
+			},
+		)
+		err := client.UpdateBucketAttributes(ctx, "my-bucket", enableVersioning,
+			bucketclient.UpdateBucketAttributesMaxAttemptsOption(3),
+			bucketclient.UpdateBucketAttributesRetryDelayOption(time.Millisecond))
+		Expect(errors.Is(err, bucketclient.ErrConcurrentUpdate)).To(BeTrue())
+		bcErr, ok := err.(*bucketclient.BucketClientError)
+		Expect(ok).To(BeTrue())
+		Expect(bcErr.StatusCode).To(Equal(http.StatusConflict))
+		Expect(err).To(MatchError(HaveSuffix("HTTP status 409 Conflict: " +
+			"concurrent update detected on bucket attributes after 3 attempts")))
+		Expect(getCount).To(Equal(6))
+	})
+
+	It("aborts the update when the mutation fails", func(ctx SpecContext) {
+		httpmock.RegisterResponder(
+			"GET", "/default/attributes/my-bucket",
+			httpmock.NewStringResponder(200, `{"name":"my-bucket"}`),
+		)
+		mutateErr := errors.New("cannot mutate")
+		Expect(client.UpdateBucketAttributes(ctx, "my-bucket",
+			func(bucketInfo *bucketclient.BucketInfo) error {
+				return mutateErr
+			})).To(MatchError(mutateErr))
+	})
+
+	It("forwards a 404 error when the bucket does not exist", func(ctx SpecContext) {
+		httpmock.RegisterResponder(
+			"GET", "/default/attributes/my-bucket",
+			httpmock.NewStringResponder(404, ""),
+		)
+		err := client.UpdateBucketAttributes(ctx, "my-bucket", enableVersioning)
+		bcErr, ok := err.(*bucketclient.BucketClientError)
+		Expect(ok).To(BeTrue())
+		Expect(bcErr.StatusCode).To(Equal(http.StatusNotFound))
+	})
+})
@@ -0,0 +1,59 @@
+package bucketclient
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// PutUsersBucketEntry adds or updates the entry indexing bucketName
+// under the owner canonicalID in the users bucket, with the given
+// creation date.
+func (client *BucketClient) PutUsersBucketEntry(ctx context.Context,
+	canonicalID string, bucketName string, creationDate time.Time) error {
+	err := validateUsersBucketEntry(canonicalID, bucketName)
+	if err != nil {
+		return &BucketClientError{
+			"PutUsersBucketEntry", "POST", client.Endpoint, "", 0, "", err,
+		}
+	}
+	value, err := json.Marshal(usersBucketEntryValue{
+		CreationDate: creationDate.UTC().Format(jsDateLayout),
+	})
+	if err != nil {
+		return &BucketClientError{
+			"PutUsersBucketEntry", "POST", client.Endpoint, "", 0, "",
+			fmt.Errorf("error marshaling users bucket entry: %w", err),
+		}
+	}
+	return client.PostBatch(ctx, UsersBucket, []PostBatchEntry{
+		{Key: UsersBucketKey(canonicalID, bucketName), Value: string(value)},
+	})
+}
+
+// DeleteUsersBucketEntry removes the entry indexing bucketName under
+// the owner canonicalID from the users bucket.
+func (client *BucketClient) DeleteUsersBucketEntry(ctx context.Context,
+	canonicalID string, bucketName string) error {
+	err := validateUsersBucketEntry(canonicalID, bucketName)
+	if err != nil {
+		return &BucketClientError{
+			"DeleteUsersBucketEntry", "POST", client.Endpoint, "", 0, "", err,
+		}
+	}
+	return client.PostBatch(ctx, UsersBucket, []PostBatchEntry{
+		{Key: UsersBucketKey(canonicalID, bucketName), Type: "del"},
+	})
+}
+
+func validateUsersBucketEntry(canonicalID string, bucketName string) error {
+	err := validateCanonicalID(canonicalID)
+	if err != nil {
+		return err
+	}
+	if bucketName == "" {
+		return fmt.Errorf("empty bucket name")
+	}
+	return nil
+}
@@ -0,0 +1,47 @@
+package bucketclient_test
+
+import (
+	"io"
+	"net/http"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/jarcoal/httpmock"
+)
+
+var _ = Describe("PutUsersBucketEntry()/DeleteUsersBucketEntry()", func() {
+	It("adds an entry to the users bucket", func(ctx SpecContext) {
+		httpmock.RegisterResponder(
+			"POST", "/default/batch/users..bucket",
+			func(req *http.Request) (*http.Response, error) {
+				defer req.Body.Close()
+				Expect(io.ReadAll(req.Body)).To(Equal([]byte(
+					`{"batch":[{"key":"owner1..|..bucket-a",` +
+						`"value":"{\"creationDate\":\"2024-03-01T10:20:30.456Z\"}"}]}`)))
+				return httpmock.NewStringResponse(200, ""), nil
+			},
+		)
+		Expect(client.PutUsersBucketEntry(ctx, "owner1", "bucket-a",
+			time.Date(2024, 3, 1, 10, 20, 30, 456000000, time.UTC))).To(Succeed())
+	})
+
+	It("removes an entry from the users bucket", func(ctx SpecContext) {
+		httpmock.RegisterResponder(
+			"POST", "/default/batch/users..bucket",
+			func(req *http.Request) (*http.Response, error) {
+				defer req.Body.Close()
+				Expect(io.ReadAll(req.Body)).To(Equal([]byte(
+					`{"batch":[{"key":"owner1..|..bucket-a","type":"del"}]}`)))
+				return httpmock.NewStringResponse(200, ""), nil
+			},
+		)
+		Expect(client.DeleteUsersBucketEntry(ctx, "owner1", "bucket-a")).To(Succeed())
+	})
+
+	It("rejects an empty bucket name", func(ctx SpecContext) {
+		Expect(client.DeleteUsersBucketEntry(ctx, "owner1", "")).To(
+			MatchError(ContainSubstring("empty bucket name")))
+	})
+})
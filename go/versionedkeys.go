@@ -0,0 +1,83 @@
+package bucketclient
+
+import (
+	"fmt"
+	"strings"
+)
+
+// VersionIdSeparator separates the object key from the version ID in
+// keys of object versions
+const VersionIdSeparator = "\x00"
+
+// Prefixes of master and version keys in buckets using the v1 key format
+const (
+	DBPrefixMaster  = "\x7fM"
+	DBPrefixVersion = "\x7fV"
+)
+
+// BucketKeyFormat is the format of keys of versioned objects in a
+// bucket, as set in the "vFormat" bucket attribute
+type BucketKeyFormat string
+
+const (
+	// BucketKeyFormatV0 stores master keys as the object key, and
+	// version keys as the object key followed by the separator and
+	// the version ID, so that master and version keys of an object
+	// are interleaved
+	BucketKeyFormatV0 BucketKeyFormat = "v0"
+	// BucketKeyFormatV1 stores master keys and version keys in two
+	// separate key ranges, each with its own prefix
+	BucketKeyFormatV1 BucketKeyFormat = "v1"
+)
+
+// ParseBucketKeyFormat validates a bucket key format, where an empty
+// string designates the v0 format used by buckets without the
+// "vFormat" attribute.
+func ParseBucketKeyFormat(format string) (BucketKeyFormat, error) {
+	switch BucketKeyFormat(format) {
+	case "", BucketKeyFormatV0:
+		return BucketKeyFormatV0, nil
+	case BucketKeyFormatV1:
+		return BucketKeyFormatV1, nil
+	default:
+		return "", fmt.Errorf("unsupported bucket key format '%s'", format)
+	}
+}
+
+// MasterKey returns the database key of the master version of an object.
+func (format BucketKeyFormat) MasterKey(objectKey string) string {
+	if format == BucketKeyFormatV1 {
+		return DBPrefixMaster + objectKey
+	}
+	return objectKey
+}
+
+// VersionKey returns the database key of a version of an object, given
+// its version ID in internal form.
+func (format BucketKeyFormat) VersionKey(objectKey string, versionId string) string {
+	versionKey := objectKey + VersionIdSeparator + versionId
+	if format == BucketKeyFormatV1 {
+		return DBPrefixVersion + versionKey
+	}
+	return versionKey
+}
+
+// ParseKey splits a database key into the object key and the version
+// ID. The returned version ID is empty for master keys.
+func (format BucketKeyFormat) ParseKey(key string) (string, string, error) {
+	if format == BucketKeyFormatV1 {
+		if strings.HasPrefix(key, DBPrefixMaster) {
+			return key[len(DBPrefixMaster):], "", nil
+		}
+		if !strings.HasPrefix(key, DBPrefixVersion) {
+			return "", "", fmt.Errorf("key %q has no v1 master or version prefix", key)
+		}
+		objectKey, versionId, found := strings.Cut(key[len(DBPrefixVersion):], VersionIdSeparator)
+		if !found {
+			return "", "", fmt.Errorf("version key %q has no version ID", key)
+		}
+		return objectKey, versionId, nil
+	}
+	objectKey, versionId, _ := strings.Cut(key, VersionIdSeparator)
+	return objectKey, versionId, nil
+}
@@ -0,0 +1,67 @@
+package bucketclient_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/scality/bucketclient/go"
+)
+
+var _ = Describe("BucketKeyFormat", func() {
+	It("parses bucket key formats", func() {
+		Expect(bucketclient.ParseBucketKeyFormat("")).To(Equal(bucketclient.BucketKeyFormatV0))
+		Expect(bucketclient.ParseBucketKeyFormat("v0")).To(Equal(bucketclient.BucketKeyFormatV0))
+		Expect(bucketclient.ParseBucketKeyFormat("v1")).To(Equal(bucketclient.BucketKeyFormatV1))
+		_, err := bucketclient.ParseBucketKeyFormat("v2")
+		Expect(err).To(MatchError(ContainSubstring("unsupported bucket key format")))
+	})
+
+	Describe("v0 format", func() {
+		format := bucketclient.BucketKeyFormatV0
+
+		It("builds master and version keys", func() {
+			Expect(format.MasterKey("foo/bar")).To(Equal("foo/bar"))
+			Expect(format.VersionKey("foo/bar", "123")).To(Equal("foo/bar\x00123"))
+		})
+
+		It("parses master and version keys", func() {
+			objectKey, versionId, err := format.ParseKey("foo/bar")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(objectKey).To(Equal("foo/bar"))
+			Expect(versionId).To(BeEmpty())
+
+			objectKey, versionId, err = format.ParseKey("foo/bar\x00123")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(objectKey).To(Equal("foo/bar"))
+			Expect(versionId).To(Equal("123"))
+		})
+	})
+
+	Describe("v1 format", func() {
+		format := bucketclient.BucketKeyFormatV1
+
+		It("builds master and version keys", func() {
+			Expect(format.MasterKey("foo/bar")).To(Equal("\x7fMfoo/bar"))
+			Expect(format.VersionKey("foo/bar", "123")).To(Equal("\x7fVfoo/bar\x00123"))
+		})
+
+		It("parses master and version keys", func() {
+			objectKey, versionId, err := format.ParseKey("\x7fMfoo/bar")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(objectKey).To(Equal("foo/bar"))
+			Expect(versionId).To(BeEmpty())
+
+			objectKey, versionId, err = format.ParseKey("\x7fVfoo/bar\x00123")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(objectKey).To(Equal("foo/bar"))
+			Expect(versionId).To(Equal("123"))
+		})
+
+		It("returns an error with malformed keys", func() {
+			_, _, err := format.ParseKey("foo/bar")
+			Expect(err).To(MatchError(ContainSubstring("no v1 master or version prefix")))
+			_, _, err = format.ParseKey("\x7fVfoo/bar")
+			Expect(err).To(MatchError(ContainSubstring("has no version ID")))
+		})
+	})
+})
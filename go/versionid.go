@@ -0,0 +1,185 @@
+package bucketclient
+
+import (
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Version IDs generated by bucketd are formatted as the concatenation of:
+//   - the reversed timestamp of the version in milliseconds since the
+//     epoch, as 14 decimal digits
+//   - the reversed sequence number of the version in its millisecond
+//     slot, as 6 decimal digits
+//   - the replication group ID, right-padded with spaces to 7 characters
+//   - an optional free-form info string
+//
+// Timestamps and sequence numbers are reversed (subtracted from their
+// maximum value) so that newer versions sort first in lexicographic
+// order, which is the order of versions in listings.
+const (
+	versionIdTimestampLength          = 14
+	versionIdSequenceLength           = 6
+	versionIdReplicationGroupIdLength = 7
+
+	versionIdMaxTimestamp = 99999999999999
+	versionIdMaxSequence  = 999999
+
+	versionIdMinLength = versionIdTimestampLength + versionIdSequenceLength +
+		versionIdReplicationGroupIdLength
+)
+
+// NullVersionId is the S3 version ID of objects created while
+// versioning was not enabled on their bucket
+const NullVersionId = "null"
+
+type VersionId struct {
+	Timestamp          time.Time
+	Sequence           int
+	ReplicationGroupId string
+	Info               string
+}
+
+// ParseVersionId decodes a version ID in its internal form, as stored
+// in metadata and returned in listings.
+func ParseVersionId(versionId string) (*VersionId, error) {
+	if len(versionId) < versionIdMinLength {
+		return nil, fmt.Errorf("invalid version ID '%s': too short", versionId)
+	}
+	reversedTimestamp, err := parseVersionIdDigits(versionId[:versionIdTimestampLength])
+	if err != nil {
+		return nil, fmt.Errorf("invalid version ID '%s': bad timestamp", versionId)
+	}
+	reversedSequence, err := parseVersionIdDigits(versionId[versionIdTimestampLength : versionIdTimestampLength+versionIdSequenceLength])
+	if err != nil {
+		return nil, fmt.Errorf("invalid version ID '%s': bad sequence number", versionId)
+	}
+	replicationGroupId := versionId[versionIdTimestampLength+versionIdSequenceLength : versionIdMinLength]
+	return &VersionId{
+		Timestamp:          time.UnixMilli(versionIdMaxTimestamp - reversedTimestamp).UTC(),
+		Sequence:           int(versionIdMaxSequence - reversedSequence),
+		ReplicationGroupId: strings.TrimRight(replicationGroupId, " "),
+		Info:               versionId[versionIdMinLength:],
+	}, nil
+}
+
+func parseVersionIdDigits(digits string) (int64, error) {
+	for _, c := range digits {
+		if c < '0' || c > '9' {
+			return 0, fmt.Errorf("not a decimal digit: '%c'", c)
+		}
+	}
+	return strconv.ParseInt(digits, 10, 64)
+}
+
+// String encodes the version ID in its internal form. It returns an
+// empty string if the timestamp, sequence number or replication group
+// ID is out of range.
+func (v *VersionId) String() string {
+	encoded, err := v.Encode()
+	if err != nil {
+		return ""
+	}
+	return encoded
+}
+
+// Encode encodes the version ID in its internal form, or returns an
+// error if the timestamp, sequence number or replication group ID is
+// out of range.
+func (v *VersionId) Encode() (string, error) {
+	timestamp := v.Timestamp.UnixMilli()
+	if timestamp < 0 || timestamp > versionIdMaxTimestamp {
+		return "", fmt.Errorf("version ID timestamp out of range: %s", v.Timestamp)
+	}
+	if v.Sequence < 0 || v.Sequence > versionIdMaxSequence {
+		return "", fmt.Errorf("version ID sequence number out of range: %d", v.Sequence)
+	}
+	if len(v.ReplicationGroupId) > versionIdReplicationGroupIdLength {
+		return "", fmt.Errorf("replication group ID '%s' is longer than %d characters",
+			v.ReplicationGroupId, versionIdReplicationGroupIdLength)
+	}
+	return fmt.Sprintf("%0*d%0*d%-*s%s",
+		versionIdTimestampLength, versionIdMaxTimestamp-timestamp,
+		versionIdSequenceLength, versionIdMaxSequence-v.Sequence,
+		versionIdReplicationGroupIdLength, v.ReplicationGroupId,
+		v.Info), nil
+}
+
+// EncodeVersionIdHex converts a version ID from its internal form to
+// the hex-encoded form visible to S3 clients.
+func EncodeVersionIdHex(versionId string) string {
+	if versionId == NullVersionId {
+		return versionId
+	}
+	return hex.EncodeToString([]byte(versionId))
+}
+
+// DecodeVersionIdHex converts a version ID from the hex-encoded form
+// visible to S3 clients to its internal form.
+func DecodeVersionIdHex(encodedVersionId string) (string, error) {
+	if encodedVersionId == NullVersionId {
+		return encodedVersionId, nil
+	}
+	decoded, err := hex.DecodeString(encodedVersionId)
+	if err != nil {
+		return "", fmt.Errorf("invalid encoded version ID '%s': %w", encodedVersionId, err)
+	}
+	return string(decoded), nil
+}
+
+// VersionIdGenerator generates new version IDs in increasing order of
+// time, the same way bucketd does: version IDs generated within the
+// same millisecond get increasing sequence numbers.
+type VersionIdGenerator struct {
+	replicationGroupId string
+
+	mutex         sync.Mutex
+	lastTimestamp int64
+	lastSequence  int
+}
+
+// NewVersionIdGenerator returns a generator of version IDs for the
+// given replication group ID, which must not be longer than 7
+// characters.
+func NewVersionIdGenerator(replicationGroupId string) (*VersionIdGenerator, error) {
+	if len(replicationGroupId) > versionIdReplicationGroupIdLength {
+		return nil, fmt.Errorf("replication group ID '%s' is longer than %d characters",
+			replicationGroupId, versionIdReplicationGroupIdLength)
+	}
+	return &VersionIdGenerator{replicationGroupId: replicationGroupId}, nil
+}
+
+// Generate returns a new version ID in its internal form, which sorts
+// before all version IDs previously returned by this generator.
+func (g *VersionIdGenerator) Generate() string {
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+
+	timestamp := time.Now().UnixMilli()
+	if timestamp < g.lastTimestamp {
+		// the clock went backwards: keep using the last
+		// millisecond slot to preserve ordering
+		timestamp = g.lastTimestamp
+	}
+	// wait for the next millisecond slot when the sequence numbers
+	// of the current one are exhausted
+	for timestamp == g.lastTimestamp && g.lastSequence >= versionIdMaxSequence {
+		time.Sleep(time.Millisecond)
+		timestamp = max(time.Now().UnixMilli(), g.lastTimestamp)
+	}
+	if timestamp == g.lastTimestamp {
+		g.lastSequence += 1
+	} else {
+		g.lastTimestamp = timestamp
+		g.lastSequence = 0
+	}
+	versionId := VersionId{
+		Timestamp:          time.UnixMilli(g.lastTimestamp),
+		Sequence:           g.lastSequence,
+		ReplicationGroupId: g.replicationGroupId,
+	}
+	return versionId.String()
+}
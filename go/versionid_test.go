@@ -0,0 +1,103 @@
+package bucketclient_test
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/scality/bucketclient/go"
+)
+
+var _ = Describe("Version IDs", func() {
+	Describe("ParseVersionId()", func() {
+		It("decodes the timestamp, sequence and replication group of a version ID", func() {
+			versionId, err := bucketclient.ParseVersionId("98290711563187999999RG001  ")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(versionId).To(Equal(&bucketclient.VersionId{
+				Timestamp:          time.Date(2024, 3, 1, 10, 20, 36, 812000000, time.UTC),
+				Sequence:           0,
+				ReplicationGroupId: "RG001",
+			}))
+		})
+
+		It("decodes the sequence number and extra info", func() {
+			versionId, err := bucketclient.ParseVersionId("98290711563187999997RG001  some-info")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(versionId.Sequence).To(Equal(2))
+			Expect(versionId.Info).To(Equal("some-info"))
+		})
+
+		It("returns an error with a malformed version ID", func() {
+			_, err := bucketclient.ParseVersionId("null")
+			Expect(err).To(MatchError(ContainSubstring("too short")))
+			_, err = bucketclient.ParseVersionId("9828969836318x999999RG001  ")
+			Expect(err).To(MatchError(ContainSubstring("bad timestamp")))
+			_, err = bucketclient.ParseVersionId("98290711563187-99999RG001  ")
+			Expect(err).To(MatchError(ContainSubstring("bad sequence number")))
+		})
+	})
+
+	Describe("VersionId.Encode()", func() {
+		It("encodes a version ID that decodes back", func() {
+			versionId := bucketclient.VersionId{
+				Timestamp:          time.Date(2024, 3, 1, 10, 20, 36, 812000000, time.UTC),
+				Sequence:           3,
+				ReplicationGroupId: "RG001",
+			}
+			Expect(versionId.Encode()).To(Equal("98290711563187999996RG001  "))
+			Expect(bucketclient.ParseVersionId(versionId.String())).To(Equal(&versionId))
+		})
+
+		It("returns an error with a too long replication group ID", func() {
+			versionId := bucketclient.VersionId{
+				Timestamp:          time.Now(),
+				ReplicationGroupId: "RG000001",
+			}
+			_, err := versionId.Encode()
+			Expect(err).To(MatchError(ContainSubstring("longer than 7 characters")))
+			Expect(versionId.String()).To(BeEmpty())
+		})
+	})
+
+	Describe("EncodeVersionIdHex()/DecodeVersionIdHex()", func() {
+		It("converts between internal and S3-visible forms", func() {
+			encoded := bucketclient.EncodeVersionIdHex("98290711563187999999RG001  ")
+			Expect(encoded).To(Equal("393832393037313135363331383739393939393952473030312020"))
+			Expect(bucketclient.DecodeVersionIdHex(encoded)).To(Equal("98290711563187999999RG001  "))
+		})
+
+		It("keeps the null version ID as is", func() {
+			Expect(bucketclient.EncodeVersionIdHex("null")).To(Equal("null"))
+			Expect(bucketclient.DecodeVersionIdHex("null")).To(Equal("null"))
+		})
+
+		It("returns an error with an invalid hex string", func() {
+			_, err := bucketclient.DecodeVersionIdHex("OOPS")
+			Expect(err).To(MatchError(ContainSubstring("invalid encoded version ID")))
+		})
+	})
+
+	Describe("VersionIdGenerator", func() {
+		It("generates strictly decreasing version IDs", func() {
+			generator, err := bucketclient.NewVersionIdGenerator("RG001")
+			Expect(err).ToNot(HaveOccurred())
+			before := time.Now().Truncate(time.Millisecond)
+			previous := generator.Generate()
+			for range 100 {
+				next := generator.Generate()
+				Expect(next < previous).To(BeTrue())
+				previous = next
+			}
+			versionId, err := bucketclient.ParseVersionId(previous)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(versionId.ReplicationGroupId).To(Equal("RG001"))
+			Expect(versionId.Timestamp).To(BeTemporally(">=", before))
+		})
+
+		It("rejects a too long replication group ID", func() {
+			_, err := bucketclient.NewVersionIdGenerator("RG000001")
+			Expect(err).To(HaveOccurred())
+		})
+	})
+})